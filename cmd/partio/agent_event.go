@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/hooks"
+	"github.com/partio-io/cli/internal/session"
+)
+
+// maxEventPayload bounds the JSON read from an agent hook's stdin.
+const maxEventPayload = 1 << 20
+
+func newAgentEventCmd() *cobra.Command {
+	var agentName string
+
+	cmd := &cobra.Command{
+		Use:    "_agent-event <start|prompt|tool|stop|end> [payload]",
+		Short:  "Internal: called by agent hooks",
+		Hidden: true,
+		Args:   cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAgentEvent(cmd, agentName, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&agentName, "agent", "claude-code", "agent reporting the event")
+
+	return cmd
+}
+
+func runAgentEvent(cmd *cobra.Command, agentName string, args []string) error {
+	ev, err := session.ParseEvent(args[0])
+	if err != nil {
+		return err
+	}
+	slog.Debug("agent event", "event", ev, "agent", agentName)
+
+	if !cfg.Enabled {
+		slog.Debug("partio disabled, skipping agent event")
+		return nil
+	}
+
+	// Agent hooks run in every project, including ones outside git.
+	runner, err := hooks.NewRunner(cfg, version)
+	if err != nil {
+		slog.Debug("not in a git repository, skipping agent event", "error", err)
+		return nil
+	}
+
+	var payload []byte
+	if len(args) > 1 {
+		payload = []byte(args[1])
+	} else if f, ok := cmd.InOrStdin().(*os.File); !ok || !isTerminal(f) {
+		if payload, err = io.ReadAll(io.LimitReader(cmd.InOrStdin(), maxEventPayload)); err != nil {
+			return fmt.Errorf("reading agent event payload: %w", err)
+		}
+	}
+
+	return runner.AgentEvent(ev, agentName, payload)
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// installAgentHooks registers partio's session event hooks with every agent
+// that supports them, running the partio binary bin.
+func installAgentHooks(bin string) {
+	for _, d := range agent.All() {
+		hi, ok := d.(agent.HookInstaller)
+		if !ok {
+			continue
+		}
+		changed, err := hi.InstallHooks(bin)
+		if err != nil {
+			fmt.Printf("  - Could not install %s session hooks: %v\n", d.Name(), err)
+		} else if changed {
+			fmt.Printf("  - Installed %s session hooks\n", d.Name())
+		}
+	}
+}
+
+// uninstallAgentHooks removes partio's session event hooks from every agent.
+func uninstallAgentHooks() {
+	for _, d := range agent.All() {
+		hi, ok := d.(agent.HookInstaller)
+		if !ok {
+			continue
+		}
+		changed, err := hi.UninstallHooks()
+		if err != nil {
+			fmt.Printf("  - Could not remove %s session hooks: %v\n", d.Name(), err)
+		} else if changed {
+			fmt.Printf("  - Removed %s session hooks\n", d.Name())
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/git"
+)
+
+func newArchiveCmd() *cobra.Command {
+	var (
+		olderThan string
+		out       string
+		dryRun    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Move old checkpoints into a bundle file",
+		Long: `Writes checkpoints older than a retention window to a git bundle and removes
+them from the partio/checkpoints/v2 branch, for long-term retention outside
+every clone. Never archives the checkpoint linked to the current HEAD.
+
+Restore them later with 'partio archive restore <file>'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runArchive(olderThan, out, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "180d", "archive checkpoints older than this (e.g. 90d, 180d, 365d)")
+	cmd.Flags().StringVar(&out, "out", "", "bundle file to write (required)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "preview what would be archived without making changes")
+	_ = cmd.MarkFlagRequired("out")
+
+	cmd.AddCommand(newArchiveRestoreCmd(), newArchiveListCmd())
+
+	return cmd
+}
+
+func newArchiveRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <file> [<checkpoint-id>...]",
+		Short: "Re-import checkpoints from a bundle file",
+		Long:  `Copies checkpoints from an archive bundle back onto the partio/checkpoints/v2 branch. With no IDs, every archived checkpoint is restored; checkpoints already on the branch are skipped.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runArchiveRestore(args[0], args[1:])
+		},
+	}
+}
+
+func newArchiveListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list <file>",
+		Short: "List the checkpoints in a bundle file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runArchiveList(args[0])
+		},
+	}
+}
+
+func runArchive(olderThan, out string, dryRun bool) error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	duration, err := config.ParseDuration(olderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than value %q: %w", olderThan, err)
+	}
+
+	currentCommit, err := git.CurrentCommit()
+	if err != nil {
+		return fmt.Errorf("getting current commit: %w", err)
+	}
+
+	// Select checkpoints with the same rules as prune, without removing them.
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	selected, err := store.Prune(duration, currentCommit, true)
+	if err != nil {
+		return fmt.Errorf("selecting checkpoints: %w", err)
+	}
+
+	if len(selected.Removed) == 0 {
+		fmt.Println("No checkpoints to archive.")
+		return nil
+	}
+
+	verb := "Archived"
+	if dryRun {
+		fmt.Println("Dry run — no changes made.")
+		fmt.Println()
+		verb = "Would archive"
+	}
+
+	var ids []string
+	for _, meta := range selected.Removed {
+		ids = append(ids, meta.ID)
+	}
+
+	if !dryRun {
+		if err := store.Archive(ids, out); err != nil {
+			return fmt.Errorf("archiving checkpoints: %w", err)
+		}
+	}
+
+	for _, meta := range selected.Removed {
+		fmt.Printf("  %s %s (branch=%s, created=%s)\n", verb, meta.ID, meta.Branch, meta.CreatedAt)
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Printf("%d checkpoint(s) would be archived to %s.\n", len(ids), out)
+	} else {
+		fmt.Printf("%d checkpoint(s) archived to %s.\n", len(ids), out)
+	}
+	return nil
+}
+
+func runArchiveRestore(file string, ids []string) error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	for _, id := range ids {
+		if len(id) != 12 {
+			return fmt.Errorf("checkpoint ID must be 12 characters (got %d)", len(id))
+		}
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	restored, err := store.RestoreArchive(file, ids)
+	if err != nil {
+		return fmt.Errorf("restoring checkpoints: %w", err)
+	}
+
+	if len(restored) == 0 {
+		fmt.Println("Nothing to restore — all requested checkpoints are already on the branch.")
+		return nil
+	}
+	for _, id := range restored {
+		fmt.Printf("  Restored %s\n", id)
+	}
+	fmt.Println()
+	fmt.Printf("%d checkpoint(s) restored from %s.\n", len(restored), file)
+	return nil
+}
+
+func runArchiveList(file string) error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	ids, err := checkpoint.NewStore(repoRoot).ArchivedIDs(file)
+	if err != nil {
+		return fmt.Errorf("reading archive: %w", err)
+	}
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/git"
+	"github.com/partio-io/cli/internal/hooks"
+)
+
+func newCheckpointCmd() *cobra.Command {
+	var note string
+
+	cmd := &cobra.Command{
+		Use:   "checkpoint",
+		Short: "Checkpoint the agent session and working tree without committing",
+		Long: `Capture the current agent session, plan and uncommitted changes (staged,
+unstaged and untracked) as a checkpoint, without creating a commit.
+
+The checkpoint is linked to HEAD and to a snapshot commit of the working tree
+kept under refs/partio/snapshots/<id>, so 'partio rewind' can bring the work
+back later.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheckpoint(note)
+		},
+	}
+
+	cmd.Flags().StringVarP(&note, "message", "m", "", "note to attach to the checkpoint")
+
+	return cmd
+}
+
+func runCheckpoint(note string) error {
+	if _, err := git.RepoRoot(); err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+	if !git.BranchExists(git.CheckpointBranch) {
+		return fmt.Errorf("no checkpoint branch found - run 'partio enable' first")
+	}
+
+	runner, err := hooks.NewRunner(cfg, version)
+	if err != nil {
+		return fmt.Errorf("initializing checkpoint: %w", err)
+	}
+	cp, err := runner.Checkpoint(note)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created checkpoint %s\n", cp.ID)
+	fmt.Printf("  Commit:   %s\n", cp.CommitHash)
+	fmt.Printf("  Snapshot: %s\n", cp.Snapshot)
+	if cp.SessionID != "" {
+		fmt.Printf("  Session:  %s (%s)\n", cp.SessionID, cp.Agent)
+	} else {
+		fmt.Println("  Session:  none found")
+	}
+	if cp.Note != "" {
+		fmt.Printf("  Note:     %s\n", cp.Note)
+	}
+	return nil
+}
@@ -5,42 +5,99 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/config"
 	"github.com/partio-io/cli/internal/git"
 )
 
 func newCleanCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		dryRun            bool
+		includeRemoteRefs bool
+		gracePeriod       string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "clean",
 		Short: "Remove orphaned checkpoint data",
-		Long:  `Scans the checkpoint branch for data that no longer corresponds to any commit in the repository and removes it.`,
-		RunE:  runClean,
+		Long: `Scans the checkpoint branch for checkpoints whose commit is no longer reachable
+from any local branch, tag or HEAD — for example after a branch was deleted
+or rewritten without keeping its Partio-Checkpoint trailers — and removes them
+in a single commit.
+
+Checkpoints newer than the grace period are kept so in-progress work isn't lost.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runClean(dryRun, includeRemoteRefs, gracePeriod)
+		},
 	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "preview what would be removed without making changes")
+	cmd.Flags().BoolVar(&includeRemoteRefs, "include-remote-refs", false, "keep checkpoints whose commit is reachable from remote-tracking branches")
+	cmd.Flags().StringVar(&gracePeriod, "grace-period", "7d", "never remove checkpoints newer than this (e.g. 0, 24h, 7d)")
+
+	return cmd
 }
 
-func runClean(cmd *cobra.Command, args []string) error {
-	_, err := git.RepoRoot()
+func runClean(dryRun, includeRemoteRefs bool, gracePeriod string) error {
+	repoRoot, err := git.RepoRoot()
 	if err != nil {
 		return fmt.Errorf("must be run inside a git repository")
 	}
 
-	// Check checkpoint branch exists
-	_, err = git.ExecGit("rev-parse", "--verify", "partio/checkpoints/v1")
-	if err != nil {
+	if !git.BranchExists(git.CheckpointBranch) {
 		return fmt.Errorf("checkpoint branch does not exist - nothing to clean")
 	}
 
-	// List checkpoint entries
-	entries, err := git.ExecGit("ls-tree", "--name-only", "partio/checkpoints/v1")
+	grace, err := config.ParseDuration(gracePeriod)
 	if err != nil {
-		return fmt.Errorf("listing checkpoint entries: %w", err)
+		return fmt.Errorf("invalid --grace-period value %q: %w", gracePeriod, err)
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	result, err := store.Clean(checkpoint.CleanOptions{
+		IncludeRemoteRefs: includeRemoteRefs,
+		GracePeriod:       grace,
+		DryRun:            dryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("cleaning checkpoints: %w", err)
+	}
+
+	if dryRun {
+		fmt.Println("Dry run — no changes made.")
+		fmt.Println()
 	}
 
-	if entries == "" {
-		fmt.Println("No checkpoint data found.")
+	if len(result.Recent) > 0 {
+		fmt.Printf("%d orphaned checkpoint(s) are newer than %s and were kept.\n", len(result.Recent), gracePeriod)
+	}
+
+	if len(result.Removed) == 0 {
+		fmt.Println("No orphaned checkpoints to remove.")
 		return nil
 	}
 
-	fmt.Println("Checkpoint data present on partio/checkpoints/v1 branch.")
-	fmt.Println("To fully reset, run: partio reset")
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	for _, meta := range result.Removed {
+		fmt.Printf("  %s %s (commit=%s, branch=%s, created=%s)\n", verb, meta.ID, shortCommit(meta.CommitHash), meta.Branch, meta.CreatedAt)
+	}
+	fmt.Println()
+
+	if dryRun {
+		fmt.Printf("%d orphaned checkpoint(s) would be removed, %d kept.\n", len(result.Removed), result.Kept+len(result.Recent))
+	} else {
+		fmt.Printf("%d orphaned checkpoint(s) removed, %d kept.\n", len(result.Removed), result.Kept+len(result.Recent))
+	}
 	return nil
 }
+
+func shortCommit(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
@@ -15,8 +15,8 @@ func newCleanupCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "cleanup",
 		Short: "Clean up stale session files",
-		Long: `Scan for session files left behind by crashed or improperly terminated agent
-processes and transition them to ENDED state.
+		Long: `Scan the tracked sessions for ones left behind by crashed or improperly
+terminated agent processes and transition them to ENDED state.
 
 A session is considered stale when it is in ACTIVE or IDLE state, its state
 file has not been updated within the configured threshold (default: 10 minutes),
@@ -42,9 +42,10 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("cleanup failed: %w", err)
 	}
 
-	if result.Cleaned {
-		fmt.Printf("Cleaned up stale session: %s (agent=%s)\n", result.Session.ID, result.Session.Agent)
-	} else {
+	for _, s := range result.Cleaned {
+		fmt.Printf("Cleaned up stale session: %s (agent=%s)\n", s.ID, s.Agent)
+	}
+	if len(result.Cleaned) == 0 {
 		fmt.Println("No stale sessions found.")
 	}
 
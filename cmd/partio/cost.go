@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/partio-io/cli/internal/pricing"
+)
+
+// priceTable returns the built-in model prices with the configured
+// overrides applied.
+func priceTable() pricing.Table {
+	table := pricing.Default()
+	maps.Copy(table, cfg.Pricing)
+	return table
+}
+
+// formatCost renders an estimated cost, noting models without a price.
+func formatCost(cost float64, unpriced []string) string {
+	s := fmt.Sprintf("$%.2f", cost)
+	if len(unpriced) > 0 {
+		s += fmt.Sprintf(" (no price for %s)", strings.Join(unpriced, ", "))
+	}
+	return s
+}
+
+// formatCount renders n with thousands separators.
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -13,23 +13,27 @@ import (
 )
 
 func newDisableCmd() *cobra.Command {
-	var removeData bool
+	var removeData, agentHooks bool
 
 	cmd := &cobra.Command{
 		Use:   "disable",
 		Short: "Disable partio in the current repository",
-		Long:  `Removes git hooks installed by partio. By default preserves checkpoint data and config. Use --remove-data to also delete the .partio/ directory.`,
+		Long: `Removes git hooks installed by partio. By default preserves checkpoint data and config. Use --remove-data to also delete the .partio/ directory.
+
+Agent session hooks are shared by every repository and are kept unless
+--agent-hooks is given.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDisable(removeData)
+			return runDisable(removeData, agentHooks)
 		},
 	}
 
 	cmd.Flags().BoolVar(&removeData, "remove-data", false, "also remove .partio/ directory and config")
+	cmd.Flags().BoolVar(&agentHooks, "agent-hooks", false, "also remove the session hooks registered with Claude Code and Codex")
 
 	return cmd
 }
 
-func runDisable(removeData bool) error {
+func runDisable(removeData, agentHooks bool) error {
 	repoRoot, err := git.RepoRoot()
 	if err != nil {
 		return fmt.Errorf("must be run inside a git repository")
@@ -42,6 +46,9 @@ func runDisable(removeData bool) error {
 
 	fmt.Println("partio disabled.")
 	fmt.Println("  - Removed git hooks (originals restored from backup if present)")
+	if agentHooks {
+		uninstallAgentHooks()
+	}
 
 	if removeData {
 		partioDir := filepath.Join(repoRoot, config.PartioDir)
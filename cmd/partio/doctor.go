@@ -62,13 +62,17 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check checkpoint branch
-	_, err = git.ExecGit("rev-parse", "--verify", "partio/checkpoints/v1")
+	_, err = git.ExecGit("rev-parse", "--verify", git.CheckpointBranch)
 	if err != nil {
 		fmt.Println("[WARN] checkpoint branch missing")
 		issues++
 	} else {
 		fmt.Println("[OK]   checkpoint branch exists")
 	}
+	if git.BranchExists(git.LegacyCheckpointBranch) {
+		fmt.Printf("[WARN] legacy %s branch found (run 'partio migrate')\n", git.LegacyCheckpointBranch)
+		issues++
+	}
 
 	// Check partio binary in PATH
 	fmt.Println("[OK]   partio binary found (you're running it!)")
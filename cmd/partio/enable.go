@@ -19,15 +19,24 @@ func newEnableCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "enable",
 		Short: "Enable partio in the current repository",
-		Long:  `Sets up partio in the current git repository by creating the .partio/ config directory, installing git hooks, and creating the checkpoint orphan branch.`,
-		RunE:  runEnable,
+		Long: `Sets up partio in the current git repository by creating the .partio/ config directory, installing git hooks, and creating the checkpoint orphan branch.
+
+With --agent-hooks it also registers session hooks with the supported agents
+(Claude Code's ~/.claude/settings.json and Codex's ~/.codex/config.toml) so
+they report when a session starts, goes idle and ends, and where its
+transcript is. These files are shared by every repository, so they are only
+edited when asked; the hooks do nothing in repositories where partio is not
+enabled.`,
+		RunE: runEnable,
 	}
 	cmd.Flags().Bool("absolute-path", false, "Install hooks using the absolute path to the partio binary (useful when partio is not on PATH in hook execution environments)")
+	cmd.Flags().Bool("agent-hooks", false, "Also register session hooks with Claude Code and Codex in their user-wide settings")
 	return cmd
 }
 
 func runEnable(cmd *cobra.Command, args []string) error {
 	absolutePath, _ := cmd.Flags().GetBool("absolute-path")
+	agentHooks, _ := cmd.Flags().GetBool("agent-hooks")
 
 	repoRoot, err := git.RepoRoot()
 	if err != nil {
@@ -53,6 +62,7 @@ func runEnable(cmd *cobra.Command, args []string) error {
 	addToGitignore(repoRoot, ".partio/state/")
 
 	// Install git hooks (reinstalls if missing or stale)
+	bin := "partio"
 	if absolutePath {
 		exePath, err := os.Executable()
 		if err != nil {
@@ -62,6 +72,7 @@ func runEnable(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("resolving partio binary symlinks: %w", err)
 		}
+		bin = exePath
 		if err := githooks.InstallAbsolute(repoRoot, exePath); err != nil {
 			return fmt.Errorf("installing git hooks: %w", err)
 		}
@@ -80,6 +91,11 @@ func runEnable(cmd *cobra.Command, args []string) error {
 	fmt.Println("partio enabled successfully!")
 	fmt.Println("  - Ensured .partio/ config directory exists")
 	fmt.Println("  - Installed git hooks (pre-commit, post-commit, pre-push)")
+	if agentHooks {
+		installAgentHooks(bin)
+	} else {
+		fmt.Println("  - Run 'partio enable --agent-hooks' to also register session hooks with Claude Code and Codex")
+	}
 	fmt.Println("  - Ready to capture AI sessions on commit")
 	return nil
 }
@@ -166,7 +182,7 @@ func addToGitignore(repoRoot, entry string) {
 }
 
 func createCheckpointBranch() error {
-	const branchName = "partio/checkpoints/v1"
+	const branchName = git.CheckpointBranch
 
 	// Check if branch already exists
 	_, err := git.ExecGit("rev-parse", "--verify", branchName)
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/git"
+)
+
+func newFsckCmd() *cobra.Command {
+	var repair bool
+
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Check the checkpoint branch for inconsistencies",
+		Long: `Walks every checkpoint on the partio/checkpoints/v2 branch and reports missing
+or malformed metadata, IDs that don't match their storage path, checkpoints
+whose commit no longer exists in any ref, commits whose Partio-Checkpoint
+trailer points at a missing checkpoint, and duplicate session captures.
+Trailers naming checkpoints that were pruned, cleaned or archived, or that are
+still on the legacy partio/checkpoints/v1 branch, are listed for information
+only.
+
+With --repair, problems that can be fixed are corrected in a single new
+commit on the checkpoint branch.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFsck(repair)
+		},
+	}
+
+	cmd.Flags().BoolVar(&repair, "repair", false, "fix the problems that can be fixed")
+
+	return cmd
+}
+
+func runFsck(repair bool) error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	result, err := store.Fsck(repair)
+	if err != nil {
+		return fmt.Errorf("checking checkpoints: %w", err)
+	}
+
+	if len(result.Problems) == 0 {
+		fmt.Printf("%d checkpoint(s) checked, no problems found.\n", result.Checked)
+		return nil
+	}
+
+	problems, remaining, repairable := 0, 0, 0
+	for _, p := range result.Problems {
+		note := ""
+		switch {
+		case p.Kind.Info():
+			note = " (info)"
+		case p.Repaired:
+			note = " (repaired)"
+		case p.Repairable:
+			note = " (repairable)"
+			repairable++
+			remaining++
+		default:
+			remaining++
+		}
+		fmt.Printf("  %-18s %s: %s%s\n", p.Kind, p.ID, p.Detail, note)
+		if !p.Kind.Info() {
+			problems++
+		}
+	}
+	fmt.Println()
+
+	fmt.Printf("%d checkpoint(s) checked, %d problem(s) found.\n", result.Checked, problems)
+	if repairable > 0 {
+		fmt.Printf("Run 'partio fsck --repair' to fix %d of them.\n", repairable)
+	}
+	if remaining > 0 {
+		return fmt.Errorf("%d problem(s) remain", remaining)
+	}
+	return nil
+}
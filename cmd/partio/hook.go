@@ -28,7 +28,7 @@ func runHook(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	runner, err := hooks.NewRunner(cfg)
+	runner, err := hooks.NewRunner(cfg, version)
 	if err != nil {
 		return fmt.Errorf("initializing hook runner: %w", err)
 	}
@@ -39,7 +39,7 @@ func runHook(cmd *cobra.Command, args []string) error {
 	case "post-commit":
 		return runner.PostCommit()
 	case "pre-push":
-		return runner.PrePush()
+		return runner.PrePush(args[1:], cmd.InOrStdin())
 	default:
 		return fmt.Errorf("unknown hook: %s", hookName)
 	}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/encrypt"
+)
+
+func newKeygenCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate a key pair for checkpoint encryption",
+		Long: `Generates an X25519 identity for decrypting encrypted checkpoints and prints
+its public recipient. The identity is written to ~/.config/partio/identity.txt
+(or $PARTIO_IDENTITY_FILE) unless --output is given; an existing file is never
+overwritten.
+
+Add the printed recipient to "encryption.recipients" in .partio/settings.json
+to encrypt new checkpoints to it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runKeygen(output)
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "", "write the identity to this path")
+
+	return cmd
+}
+
+func runKeygen(output string) error {
+	path := output
+	if path == "" {
+		p, err := encrypt.DefaultIdentityPath()
+		if err != nil {
+			return err
+		}
+		path = p
+	}
+
+	id, err := encrypt.GenerateIdentity()
+	if err != nil {
+		return err
+	}
+	if err := encrypt.WriteIdentity(path, id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Identity written to %s\n", path)
+	fmt.Printf("Recipient: %s\n", id.Recipient())
+	return nil
+}
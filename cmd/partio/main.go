@@ -61,13 +61,24 @@ func newRootCmd() *cobra.Command {
 		newDisableCmd(),
 		newStatusCmd(),
 		newHookCmd(),
+		newAgentEventCmd(),
 		newDoctorCmd(),
 		newResetCmd(),
 		newCleanCmd(),
+		newCheckpointCmd(),
 		newRewindCmd(),
 		newResumeCmd(),
 		newPruneCmd(),
 		newCleanupCmd(),
+		newSessionsCmd(),
+		newKeygenCmd(),
+		newVerifyCmd(),
+		newFsckCmd(),
+		newArchiveCmd(),
+		newMigrateCmd(),
+		newShowCmd(),
+		newStatsCmd(),
+		newMCPCmd(),
 	)
 
 	return root
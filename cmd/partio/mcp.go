@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/git"
+	"github.com/partio-io/cli/internal/mcp"
+)
+
+func newMCPCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve checkpoints to agents over the Model Context Protocol",
+		Long: `Run a Model Context Protocol server over stdio, so agents can look up the
+reasoning behind existing code before changing it. Register it with the
+agent, e.g. 'claude mcp add partio -- partio mcp'.
+
+Tools:
+  search_checkpoints      find checkpoints by request, context, branch, author, agent, model, plan or note
+  get_checkpoint          a checkpoint's request, plan, diff and where it left off
+  why_line                the checkpoints behind the commit that last changed a line
+  list_sessions_for_path  the agent sessions whose commits touched a file or directory`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMCP()
+		},
+	}
+}
+
+func runMCP() error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+	// Paths in tool arguments are relative to the repository root.
+	if err := os.Chdir(repoRoot); err != nil {
+		return fmt.Errorf("changing to repository root: %w", err)
+	}
+
+	return mcp.NewServer("partio", version, mcpTools(repoRoot)).Serve(os.Stdin, os.Stdout)
+}
+
+// mcpTools returns the tools `partio mcp` serves.
+func mcpTools(repoRoot string) []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name:        "search_checkpoints",
+			Description: "Search the checkpoints partio captured for this repository: the AI agent sessions behind its commits. Every word of the query must appear in a checkpoint's metadata (its ID, commit, branch, author, agent, models, session ID, plan name or note) or in the request and context of its unencrypted sessions. Newest first, with each match's request and changed files.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"query":{"type":"string","description":"Words to search for"},"limit":{"type":"integer","description":"Maximum number of results (default 20)"}},"required":["query"]}`),
+			Handler:     func(args json.RawMessage) (string, error) { return searchCheckpointsTool(repoRoot, args) },
+		},
+		{
+			Name:        "get_checkpoint",
+			Description: "Get a checkpoint by its 12-character ID: the request that started the agent session, its plan, the diff it produced, the agent's last message and the files its tools touched.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"id":{"type":"string","description":"Checkpoint ID"}},"required":["id"]}`),
+			Handler:     getCheckpointTool,
+		},
+		{
+			Name:        "why_line",
+			Description: "Explain why a line of code is the way it is: blames the line at HEAD and returns the commit that last changed it with the request, plan and final message of the agent sessions captured for that commit.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"file":{"type":"string","description":"File path, relative to the repository root"},"line":{"type":"integer","description":"1-based line number"}},"required":["file","line"]}`),
+			Handler:     func(args json.RawMessage) (string, error) { return whyLineTool(repoRoot, args) },
+		},
+		{
+			Name:        "list_sessions_for_path",
+			Description: "List the agent sessions whose commits in HEAD's history touched a file or directory, most recent first, with their checkpoints, commits and the request that started each session.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"path":{"type":"string","description":"File or directory, relative to the repository root"},"limit":{"type":"integer","description":"Maximum number of sessions (default 20)"}},"required":["path"]}`),
+			Handler:     func(args json.RawMessage) (string, error) { return sessionsForPathTool(repoRoot, args) },
+		},
+	}
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/git"
+)
+
+// defaultMCPLimit is the number of results tools return unless asked for
+// another.
+const defaultMCPLimit = 20
+
+// maxMCPText bounds the request, plan and messages quoted in tool results
+// that list several checkpoints, and maxMCPDiff the diff of get_checkpoint.
+const (
+	maxMCPText = 2000
+	maxMCPDiff = 50000
+)
+
+// checkpointBrief is a checkpoint in a list of tool results.
+type checkpointBrief struct {
+	ID         string   `json:"id"`
+	CommitHash string   `json:"commit_hash"`
+	Branch     string   `json:"branch"`
+	CreatedAt  string   `json:"created_at"`
+	Agent      string   `json:"agent"`
+	SessionID  string   `json:"session_id,omitempty"`
+	Manual     bool     `json:"manual,omitempty"`
+	Note       string   `json:"note,omitempty"`
+	Request    string   `json:"request"`
+	Files      []string `json:"changed_files,omitempty"`
+}
+
+func newCheckpointBrief(data *checkpoint.CheckpointData) checkpointBrief {
+	meta := data.Metadata
+	return checkpointBrief{
+		ID:         meta.ID,
+		CommitHash: meta.CommitHash,
+		Branch:     meta.Branch,
+		CreatedAt:  meta.CreatedAt,
+		Agent:      meta.Agent,
+		SessionID:  meta.SessionID,
+		Manual:     meta.Manual,
+		Note:       meta.Note,
+		Request:    trimText(checkpointRequest(data), maxSummaryText),
+		Files:      diffFiles(data.Diff),
+	}
+}
+
+func searchCheckpointsTool(repoRoot string, raw json.RawMessage) (string, error) {
+	var args struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	terms := strings.Fields(strings.ToLower(args.Query))
+	if len(terms) == 0 {
+		return "", fmt.Errorf("query is required")
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultMCPLimit
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	summaries, err := store.List()
+	if err != nil {
+		return "", fmt.Errorf("listing checkpoints: %w", err)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return createdAt(summaries[i].Metadata).After(createdAt(summaries[j].Metadata))
+	})
+	// Requests and contexts are only read for checkpoints that contain one
+	// of the terms there but don't match on metadata alone.
+	candidates, err := store.TextCandidates(terms)
+	if err != nil {
+		return "", fmt.Errorf("searching checkpoints: %w", err)
+	}
+
+	// Only the checkpoints that match are read in full.
+	results := []checkpointBrief{}
+	for _, s := range summaries {
+		if s.Err != nil {
+			continue
+		}
+		text := searchText(s)
+		if !matchesAll(text, terms) {
+			if !candidates[s.ID] {
+				continue
+			}
+			plain, err := store.PlainText(s.ID)
+			if err != nil || !matchesAll(text+"\n"+strings.ToLower(plain), terms) {
+				continue
+			}
+		}
+		data, err := checkpoint.Read(s.ID)
+		if err != nil {
+			// Encrypted without a key: only the metadata can be shown.
+			data = &checkpoint.CheckpointData{Metadata: s.Metadata}
+		}
+		results = append(results, newCheckpointBrief(data))
+		if len(results) == limit {
+			break
+		}
+	}
+	return toolJSON(results)
+}
+
+// searchText returns the metadata search_checkpoints matches a query
+// against, as listed without reading the session files.
+func searchText(s checkpoint.Summary) string {
+	meta := s.Metadata
+	fields := []string{meta.ID, meta.CommitHash, meta.Branch, meta.Author, meta.Agent, meta.SessionID, meta.PlanSlug, meta.Note}
+	for _, sm := range s.Sessions {
+		fields = append(fields, sm.SessionID, sm.Agent)
+		fields = append(fields, sm.Models...)
+	}
+	return strings.ToLower(strings.Join(fields, "\n"))
+}
+
+// matchesAll reports whether text contains every term.
+func matchesAll(text string, terms []string) bool {
+	for _, t := range terms {
+		if !strings.Contains(text, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkpointDetail is the result of get_checkpoint.
+type checkpointDetail struct {
+	checkpoint.Metadata
+	Request      string   `json:"request"`
+	Plan         string   `json:"plan,omitempty"`
+	Diff         string   `json:"diff,omitempty"`
+	LastMessage  string   `json:"last_message,omitempty"`
+	FilesTouched []string `json:"files_touched,omitempty"`
+}
+
+func getCheckpointTool(raw json.RawMessage) (string, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	data, err := checkpoint.Read(strings.TrimSpace(args.ID))
+	if err != nil {
+		return "", err
+	}
+
+	detail := checkpointDetail{
+		Metadata: data.Metadata,
+		Request:  checkpointRequest(data),
+		Plan:     data.Plan,
+		Diff:     data.Diff,
+	}
+	if len(detail.Diff) > maxMCPDiff {
+		detail.Diff = trimText(detail.Diff, maxMCPDiff) + "\n(diff trimmed)"
+	}
+	if t := checkpointTranscript(data); t != nil {
+		if m, ok := t.LastMessage("assistant"); ok {
+			detail.LastMessage = m.Content
+		}
+		detail.FilesTouched = filesTouched(t)
+	}
+	return toolJSON(detail)
+}
+
+// lineCheckpoint is a checkpoint behind a line, in the result of why_line.
+type lineCheckpoint struct {
+	ID          string `json:"id"`
+	CreatedAt   string `json:"created_at"`
+	Agent       string `json:"agent"`
+	SessionID   string `json:"session_id,omitempty"`
+	Request     string `json:"request"`
+	Plan        string `json:"plan,omitempty"`
+	LastMessage string `json:"last_message,omitempty"`
+}
+
+// whyLine is the result of why_line.
+type whyLine struct {
+	File        string           `json:"file"`
+	Line        int              `json:"line"`
+	Content     string           `json:"content"`
+	Commit      string           `json:"commit"`
+	Author      string           `json:"author"`
+	Summary     string           `json:"summary"`
+	Checkpoints []lineCheckpoint `json:"checkpoints"`
+	Note        string           `json:"note,omitempty"`
+}
+
+// blameLine is a line of `git blame --porcelain` output.
+type blameLine struct {
+	commit  string
+	author  string
+	summary string
+	content string
+}
+
+func whyLineTool(repoRoot string, raw json.RawMessage) (string, error) {
+	var args struct {
+		File string `json:"file"`
+		Line int    `json:"line"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.File == "" || args.Line < 1 {
+		return "", fmt.Errorf("file and a line number of 1 or more are required")
+	}
+	file, err := repoPath(repoRoot, args.File)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := git.ExecGit("blame", "--porcelain", "-L", strconv.Itoa(args.Line)+",+1", "HEAD", "--", file)
+	if err != nil {
+		return "", fmt.Errorf("%s has no line %d at HEAD", file, args.Line)
+	}
+	blame, err := parseBlame(out)
+	if err != nil {
+		return "", err
+	}
+
+	result := whyLine{
+		File:        file,
+		Line:        args.Line,
+		Content:     blame.content,
+		Commit:      blame.commit,
+		Author:      blame.author,
+		Summary:     blame.summary,
+		Checkpoints: []lineCheckpoint{},
+	}
+	refs, err := commitCheckpoints(repoRoot, "-1", blame.commit)
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		data, err := checkpoint.Read(ref.meta.ID)
+		if err != nil {
+			continue
+		}
+		lc := lineCheckpoint{
+			ID:        data.Metadata.ID,
+			CreatedAt: data.Metadata.CreatedAt,
+			Agent:     data.Metadata.Agent,
+			SessionID: data.Metadata.SessionID,
+			Request:   trimText(checkpointRequest(data), maxMCPText),
+			Plan:      trimText(data.Plan, maxMCPText),
+		}
+		if t := checkpointTranscript(data); t != nil {
+			if m, ok := t.LastMessage("assistant"); ok {
+				lc.LastMessage = trimText(m.Content, maxMCPText)
+			}
+		}
+		result.Checkpoints = append(result.Checkpoints, lc)
+	}
+	if len(result.Checkpoints) == 0 {
+		result.Note = "No checkpoint was captured for this commit; it was probably written without an agent."
+	}
+	return toolJSON(result)
+}
+
+// parseBlame parses `git blame --porcelain` output for a single line.
+func parseBlame(out string) (blameLine, error) {
+	var b blameLine
+	for i, line := range strings.Split(out, "\n") {
+		if i == 0 {
+			b.commit, _, _ = strings.Cut(line, " ")
+			continue
+		}
+		if content, ok := strings.CutPrefix(line, "\t"); ok {
+			b.content = content
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			b.author = value
+		case "summary":
+			b.summary = value
+		}
+	}
+	if len(b.commit) < 40 {
+		return blameLine{}, fmt.Errorf("unexpected blame output")
+	}
+	return b, nil
+}
+
+// sessionForPath is an agent session in the result of
+// list_sessions_for_path.
+type sessionForPath struct {
+	Agent       string   `json:"agent"`
+	SessionID   string   `json:"session_id,omitempty"`
+	Request     string   `json:"request"`
+	Checkpoints []string `json:"checkpoints"`
+	Commits     []string `json:"commits"`
+	LastSeen    string   `json:"last_seen"`
+}
+
+func sessionsForPathTool(repoRoot string, raw json.RawMessage) (string, error) {
+	var args struct {
+		Path  string `json:"path"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultMCPLimit
+	}
+	path, err := repoPath(repoRoot, args.Path)
+	if err != nil {
+		return "", err
+	}
+
+	refs, err := commitCheckpoints(repoRoot, "HEAD", "--", path)
+	if err != nil {
+		return "", err
+	}
+
+	// Commits come newest first, so sessions are listed by their most
+	// recent commit to the path.
+	sessions := []*sessionForPath{}
+	byKey := make(map[string]*sessionForPath)
+	for _, ref := range refs {
+		meta := ref.meta
+		key := meta.Agent + "\x00" + meta.SessionID
+		if meta.SessionID == "" {
+			key = meta.ID
+		}
+		s, ok := byKey[key]
+		if !ok {
+			if len(sessions) == limit {
+				continue
+			}
+			// The request is read from the session's most recent checkpoint.
+			request := ""
+			if data, err := checkpoint.Read(meta.ID); err == nil {
+				request = trimText(checkpointRequest(data), maxSummaryText)
+			}
+			s = &sessionForPath{
+				Agent:     meta.Agent,
+				SessionID: meta.SessionID,
+				Request:   request,
+				LastSeen:  meta.CreatedAt,
+			}
+			byKey[key] = s
+			sessions = append(sessions, s)
+		}
+		if !slices.Contains(s.Checkpoints, meta.ID) {
+			s.Checkpoints = append(s.Checkpoints, meta.ID)
+		}
+		if !slices.Contains(s.Commits, ref.commit) {
+			s.Commits = append(s.Commits, ref.commit)
+		}
+	}
+	return toolJSON(sessions)
+}
+
+// checkpointRef is a checkpoint captured for a commit.
+type checkpointRef struct {
+	commit string
+	meta   checkpoint.Metadata
+}
+
+// commitCheckpoints returns the checkpoints captured for the commits
+// revArgs select (anything accepted by git log), newest commit first: those
+// the commits' Partio-Checkpoint trailers name, and those recorded with the
+// commit's hash. Manual checkpoints are left out of the latter, since the
+// changes they hold are not the commit's, and so are checkpoints not stored
+// locally.
+func commitCheckpoints(repoRoot string, revArgs ...string) ([]checkpointRef, error) {
+	store := checkpoint.NewStore(repoRoot)
+	commits, err := store.CommitTrailers(revArgs...)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("listing checkpoints: %w", err)
+	}
+
+	byID := make(map[string]checkpoint.Metadata)
+	byCommit := make(map[string][]string)
+	for _, s := range summaries {
+		if s.Err != nil {
+			continue
+		}
+		byID[s.ID] = s.Metadata
+		if !s.Metadata.Manual && s.Metadata.CommitHash != "" {
+			byCommit[s.Metadata.CommitHash] = append(byCommit[s.Metadata.CommitHash], s.ID)
+		}
+	}
+
+	var refs []checkpointRef
+	for _, c := range commits {
+		seen := make(map[string]bool)
+		for _, id := range slices.Concat(c.IDs, byCommit[c.Hash]) {
+			meta, ok := byID[id]
+			if ok && !seen[id] {
+				seen[id] = true
+				refs = append(refs, checkpointRef{commit: c.Hash, meta: meta})
+			}
+		}
+	}
+	return refs, nil
+}
+
+// repoPath returns path relative to the repository root, refusing paths
+// outside it.
+func repoPath(repoRoot, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return "", fmt.Errorf("%s is not in the repository", path)
+		}
+		path = rel
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	if path == ".." || strings.HasPrefix(path, "../") {
+		return "", fmt.Errorf("%s is not in the repository", path)
+	}
+	return path, nil
+}
+
+// toolJSON renders a tool result as indented JSON, leaving the <, > and &
+// of code and author emails unescaped.
+func toolJSON(v any) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", fmt.Errorf("encoding result: %w", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+)
+
+// mcpTestRepo creates a repository, makes it the working directory and
+// returns its root and the hash of its first commit. Its history is:
+//
+//	init        a.go lines 1-3, no checkpoint (manual checkpoint dd.. taken on it)
+//	retry       a.go line 2, trailer aa0000000001 (session sess-1)
+//	backoff     a.go line 3, trailer bb0000000002 (session sess-1)
+//	parser      b.go, checkpoint cc0000000003 linked by commit hash (session sess-2)
+func mcpTestRepo(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+	t.Chdir(dir)
+
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(file, content, msg string, trailers ...string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", file)
+		args := []string{"commit", "-q", "-m", msg}
+		for _, tr := range trailers {
+			args = append(args, "-m", tr)
+		}
+		run(args...)
+		return run("rev-parse", "HEAD")
+	}
+
+	store := checkpoint.NewStore(dir)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	write := func(cp checkpoint.Checkpoint, prompt, diff string) {
+		t.Helper()
+		cp.Branch = "main"
+		cp.CreatedAt = created
+		created = created.Add(time.Hour)
+		files := &checkpoint.SessionFiles{Prompt: prompt, Plan: "Plan for " + cp.ID, Context: "Context for " + cp.ID, Diff: diff}
+		files.Metadata.Agent = cp.Agent
+		if err := store.Write(&cp, files); err != nil {
+			t.Fatalf("Write(%s): %v", cp.ID, err)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	initial := commit("a.go", "package a\n\nfunc fetch() {}\n", "init")
+	write(checkpoint.Checkpoint{ID: "dd0000000004", SessionID: "sess-3", Agent: "claude-code", CommitHash: initial, Manual: true, Note: "wip parser"},
+		"Sketch a parser", "diff --git a/a.go b/a.go\n")
+
+	write(checkpoint.Checkpoint{ID: "aa0000000001", SessionID: "sess-1", Agent: "claude-code", PlanSlug: "fetch-retries"},
+		"Add retry logic to fetch", "diff --git a/a.go b/a.go\n")
+	commit("a.go", "package a\n// fetch retries\nfunc fetch() {}\n", "retry", checkpoint.Trailer+": aa0000000001")
+	write(checkpoint.Checkpoint{ID: "bb0000000002", SessionID: "sess-1", Agent: "claude-code", PlanSlug: "fetch-retries"},
+		"Now add exponential backoff", "diff --git a/a.go b/a.go\n")
+	commit("a.go", "package a\n// fetch retries\nfunc fetch() { backoff() }\n", "backoff", checkpoint.Trailer+": bb0000000002")
+
+	parser := commit("b.go", "package a\n\nfunc parse() {}\n", "parser")
+	write(checkpoint.Checkpoint{ID: "cc0000000003", SessionID: "sess-2", Agent: "codex", CommitHash: parser},
+		"Write the parser", "diff --git a/b.go b/b.go\n")
+
+	return dir, initial
+}
+
+func TestMCPTools(t *testing.T) {
+	repoRoot, initial := mcpTestRepo(t)
+
+	type tool func(json.RawMessage) (string, error)
+	search := func(args json.RawMessage) (string, error) { return searchCheckpointsTool(repoRoot, args) }
+	why := func(args json.RawMessage) (string, error) { return whyLineTool(repoRoot, args) }
+	sessions := func(args json.RawMessage) (string, error) { return sessionsForPathTool(repoRoot, args) }
+
+	tests := []struct {
+		name    string
+		tool    tool
+		args    string
+		want    string // JSON the result must match, ignoring fields it leaves out
+		wantErr string
+	}{
+		{
+			name: "search by plan name, newest first",
+			tool: search,
+			args: `{"query":"FETCH-retries"}`,
+			want: `[{"id":"bb0000000002","request":"Now add exponential backoff","changed_files":["a.go"]},{"id":"aa0000000001"}]`,
+		},
+		{
+			name: "search needs every word",
+			tool: search,
+			args: `{"query":"codex sess-2"}`,
+			want: `[{"id":"cc0000000003","agent":"codex"}]`,
+		},
+		{
+			name: "search finds manual checkpoints by note",
+			tool: search,
+			args: `{"query":"wip"}`,
+			want: `[{"id":"dd0000000004","manual":true,"note":"wip parser"}]`,
+		},
+		{
+			name: "search the request",
+			tool: search,
+			args: `{"query":"Exponential"}`,
+			want: `[{"id":"bb0000000002"}]`,
+		},
+		{
+			name: "search the request, newest first",
+			tool: search,
+			args: `{"query":"parser"}`,
+			want: `[{"id":"cc0000000003"},{"id":"dd0000000004"}]`,
+		},
+		{
+			name: "search words split between metadata and context",
+			tool: search,
+			args: `{"query":"codex context"}`,
+			want: `[{"id":"cc0000000003"}]`,
+		},
+		{
+			name: "search limit",
+			tool: search,
+			args: `{"query":"main","limit":1}`,
+			want: `[{"id":"cc0000000003"}]`,
+		},
+		{
+			name: "search with no match",
+			tool: search,
+			args: `{"query":"nothing-like-this"}`,
+			want: `[]`,
+		},
+		{
+			name:    "search without a query",
+			tool:    search,
+			args:    `{}`,
+			wantErr: "query is required",
+		},
+		{
+			name: "get checkpoint",
+			tool: getCheckpointTool,
+			args: `{"id":"aa0000000001"}`,
+			want: `{"id":"aa0000000001","session_id":"sess-1","request":"Add retry logic to fetch","plan":"Plan for aa0000000001"}`,
+		},
+		{
+			name:    "get unknown checkpoint",
+			tool:    getCheckpointTool,
+			args:    `{"id":"ffffffffffff"}`,
+			wantErr: "ffffffffffff",
+		},
+		{
+			name: "why line through a trailer",
+			tool: why,
+			args: `{"file":"a.go","line":3}`,
+			want: `{"file":"a.go","line":3,"content":"func fetch() { backoff() }","summary":"backoff","checkpoints":[{"id":"bb0000000002","request":"Now add exponential backoff","plan":"Plan for bb0000000002"}]}`,
+		},
+		{
+			name: "why line through a commit hash",
+			tool: why,
+			args: `{"file":"` + filepath.Join(repoRoot, "b.go") + `","line":3}`,
+			want: `{"file":"b.go","summary":"parser","checkpoints":[{"id":"cc0000000003","agent":"codex"}]}`,
+		},
+		{
+			name: "why line skips manual checkpoints",
+			tool: why,
+			args: `{"file":"a.go","line":1}`,
+			want: `{"commit":"` + initial + `","checkpoints":[],"note":"No checkpoint was captured for this commit; it was probably written without an agent."}`,
+		},
+		{
+			name:    "why line past the end of the file",
+			tool:    why,
+			args:    `{"file":"a.go","line":99}`,
+			wantErr: "a.go has no line 99 at HEAD",
+		},
+		{
+			name:    "why line outside the repository",
+			tool:    why,
+			args:    `{"file":"../elsewhere.go","line":1}`,
+			wantErr: "not in the repository",
+		},
+		{
+			name: "sessions for a file",
+			tool: sessions,
+			args: `{"path":"a.go"}`,
+			want: `[{"agent":"claude-code","session_id":"sess-1","request":"Now add exponential backoff","checkpoints":["bb0000000002","aa0000000001"]}]`,
+		},
+		{
+			name: "sessions for the whole repository",
+			tool: sessions,
+			args: `{"path":"."}`,
+			want: `[{"session_id":"sess-2"},{"session_id":"sess-1"}]`,
+		},
+		{
+			name: "sessions limit",
+			tool: sessions,
+			args: `{"path":".","limit":1}`,
+			want: `[{"session_id":"sess-2"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tool(json.RawMessage(tt.args))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			var gotV, wantV any
+			if err := json.Unmarshal([]byte(got), &gotV); err != nil {
+				t.Fatalf("result is not JSON: %v\n%s", err, got)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantV); err != nil {
+				t.Fatal(err)
+			}
+			if !jsonSubset(wantV, gotV) {
+				t.Errorf("result:\n%s\nwant it to match:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// jsonSubset reports whether got matches want: objects must have want's
+// fields with matching values, arrays the same length and matching elements.
+func jsonSubset(want, got any) bool {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range w {
+			if !jsonSubset(v, g[k]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !jsonSubset(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return want == got
+	}
+}
+
+func TestParseBlame(t *testing.T) {
+	out := "3f2252b0c1d2e3f4a5b6c7d8e9f0011223344556 12 12 1\n" +
+		"author Jane Doe\n" +
+		"author-mail <jane@example.com>\n" +
+		"author-time 1760000000\n" +
+		"summary Add resume --native\n" +
+		"filename cmd/partio/resume.go\n" +
+		"\tif opts.native {"
+
+	got, err := parseBlame(out)
+	if err != nil {
+		t.Fatalf("parseBlame: %v", err)
+	}
+	want := blameLine{
+		commit:  "3f2252b0c1d2e3f4a5b6c7d8e9f0011223344556",
+		author:  "Jane Doe",
+		summary: "Add resume --native",
+		content: "if opts.native {",
+	}
+	if got != want {
+		t.Errorf("parseBlame = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseBlame("fatal: no such path"); err == nil {
+		t.Error("parseBlame accepted output without a commit")
+	}
+}
+
+func TestRepoPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "cmd/partio/main.go", want: "cmd/partio/main.go"},
+		{path: "./internal/../README.md", want: "README.md"},
+		{path: "/repo/internal/mcp", want: "internal/mcp"},
+		{path: "/repo", want: "."},
+		{path: "../other/file.go", wantErr: true},
+		{path: "/elsewhere/file.go", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := repoPath("/repo", tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("repoPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("repoPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/git"
+)
+
+func newMigrateCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Convert v1 checkpoints to the v2 schema",
+		Long: `Copies every checkpoint on the legacy partio/checkpoints/v1 branch to the
+partio/checkpoints/v2 branch, upgrading its metadata to schema v2 (schema
+version, session list, session agent) and adding a manifest so it can be
+checked with 'partio verify'. Checkpoints already on the v2 branch are skipped.
+
+The v1 branch is left untouched; delete it once you are satisfied with the
+result.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrate(dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be migrated without making changes")
+
+	return cmd
+}
+
+func runMigrate(dryRun bool) error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	if !git.BranchExists(git.LegacyCheckpointBranch) {
+		fmt.Printf("No %s branch found; nothing to migrate.\n", git.LegacyCheckpointBranch)
+		return nil
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	result, err := store.Migrate(dryRun)
+	if err != nil {
+		return fmt.Errorf("migrating checkpoints: %w", err)
+	}
+
+	verb := "Migrated"
+	if dryRun {
+		verb = "Would migrate"
+	}
+	fmt.Printf("%s %d checkpoint(s) from %s to %s.\n", verb, len(result.Migrated), git.LegacyCheckpointBranch, git.CheckpointBranch)
+	if len(result.Skipped) > 0 {
+		fmt.Printf("Skipped %d checkpoint(s) already on %s.\n", len(result.Skipped), git.CheckpointBranch)
+	}
+
+	if len(result.Failed) > 0 {
+		ids := make([]string, 0, len(result.Failed))
+		for id := range result.Failed {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		fmt.Println("Could not migrate:")
+		for _, id := range ids {
+			fmt.Printf("  %s: %v\n", id, result.Failed[id])
+		}
+		return fmt.Errorf("%d checkpoint(s) could not be migrated", len(ids))
+	}
+
+	if !dryRun {
+		fmt.Println()
+		fmt.Printf("%s was kept. Once you are satisfied, delete it with:\n", git.LegacyCheckpointBranch)
+		fmt.Printf("  git branch -D %s\n", git.LegacyCheckpointBranch)
+	}
+	return nil
+}
@@ -2,59 +2,182 @@ package main
 
 import (
 	"fmt"
-	"regexp"
-	"strconv"
-	"time"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/config"
 	"github.com/partio-io/cli/internal/git"
+	"github.com/partio-io/cli/internal/hooks"
 )
 
 func newPruneCmd() *cobra.Command {
 	var (
 		olderThan string
 		dryRun    bool
+		policy    bool
+		squash    bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "prune",
 		Short: "Delete old checkpoints",
-		Long:  `Remove checkpoints older than a retention window from the partio/checkpoints/v1 branch. Never deletes the checkpoint linked to the current HEAD.`,
+		Long: `Remove checkpoints older than a retention window from the partio/checkpoints/v2 branch. Never deletes the checkpoint linked to the current HEAD.
+
+With --policy, the "retention" section of the config is applied instead: max age,
+max count and max total transcript size, with per-branch overrides.
+
+Pruning alone does not free disk space: removed checkpoints stay reachable
+through the branch history. With --squash-history, the checkpoint branch (and
+the refs holding published checkpoints) are rewritten into a single commit
+after pruning, so the old data can be garbage-collected. Rewritten history
+must be force-pushed, and teammates must re-sync.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPrune(olderThan, dryRun)
+			var (
+				result *checkpoint.PruneResult
+				err    error
+			)
+			if policy {
+				if cmd.Flags().Changed("older-than") {
+					return fmt.Errorf("--older-than cannot be combined with --policy")
+				}
+				result, err = runPrunePolicy(dryRun)
+			} else {
+				result, err = runPrune(olderThan, dryRun)
+			}
+			if err != nil || !squash {
+				return err
+			}
+			return runSquashHistory(result, dryRun)
 		},
 	}
 
 	cmd.Flags().StringVar(&olderThan, "older-than", "90d", "retention window (e.g. 30d, 90d, 365d)")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "preview what would be deleted without making changes")
+	cmd.Flags().BoolVar(&policy, "policy", false, "apply the configured retention policy")
+	cmd.Flags().BoolVar(&squash, "squash-history", false, "rewrite the checkpoint branch history to reclaim disk space")
 
 	return cmd
 }
 
-func runPrune(olderThan string, dryRun bool) error {
+func runPrune(olderThan string, dryRun bool) (*checkpoint.PruneResult, error) {
 	repoRoot, err := git.RepoRoot()
 	if err != nil {
-		return fmt.Errorf("must be run inside a git repository")
+		return nil, fmt.Errorf("must be run inside a git repository")
 	}
 
-	duration, err := parseDuration(olderThan)
+	duration, err := config.ParseDuration(olderThan)
 	if err != nil {
-		return fmt.Errorf("invalid --older-than value %q: %w", olderThan, err)
+		return nil, fmt.Errorf("invalid --older-than value %q: %w", olderThan, err)
 	}
 
 	currentCommit, err := git.CurrentCommit()
 	if err != nil {
-		return fmt.Errorf("getting current commit: %w", err)
+		return nil, fmt.Errorf("getting current commit: %w", err)
 	}
 
 	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
 	result, err := store.Prune(duration, currentCommit, dryRun)
 	if err != nil {
-		return fmt.Errorf("pruning checkpoints: %w", err)
+		return nil, fmt.Errorf("pruning checkpoints: %w", err)
+	}
+
+	printPruneResult(result, dryRun)
+	return result, nil
+}
+
+func runPrunePolicy(dryRun bool) (*checkpoint.PruneResult, error) {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("must be run inside a git repository")
+	}
+
+	if !cfg.Retention.Configured() {
+		return nil, fmt.Errorf("no retention policy configured (set \"retention\" in .partio/settings.json)")
+	}
+
+	currentCommit, err := git.CurrentCommit()
+	if err != nil {
+		return nil, fmt.Errorf("getting current commit: %w", err)
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	result, err := store.ApplyRetention(hooks.RetentionPolicy(cfg.Retention), currentCommit, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("applying retention policy: %w", err)
+	}
+
+	printPruneResult(result, dryRun)
+	return result, nil
+}
+
+func runSquashHistory(pruned *checkpoint.PruneResult, dryRun bool) error {
+	fmt.Println()
+	if dryRun {
+		fmt.Println("Would squash the checkpoint branch history into a single commit.")
+		return nil
+	}
+
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	var drop []string
+	for _, meta := range pruned.Removed {
+		drop = append(drop, meta.ID)
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	result, err := store.SquashHistory(drop)
+	if err != nil {
+		return fmt.Errorf("squashing checkpoint history: %w", err)
+	}
+
+	fmt.Printf("Squashed %s history into a single commit (%s -> %s, %d checkpoint(s)).\n",
+		git.CheckpointBranch, shortCommit(result.OldTip), shortCommit(result.NewTip), result.Checkpoints)
+	if len(result.RemoteRefs) > 0 {
+		fmt.Printf("Deleted %d fetched remote checkpoint ref(s); the next push fetches them again.\n", len(result.RemoteRefs))
+	}
+	printSquashGuidance(result.PublishRefs, result.Legacy)
+	return nil
+}
+
+// printSquashGuidance explains how to propagate a rewritten checkpoint
+// history to remotes and teammates, and how to reclaim the space locally.
+// legacy is true when the legacy v1 branch still holds old history.
+func printSquashGuidance(publishRefs []string, legacy bool) {
+	dst := hooks.CheckpointDestRef(cfg)
+	fmt.Println()
+
+	if len(publishRefs) > 0 {
+		fmt.Println("1. Force-push the rewritten checkpoints to each remote they were published to:")
+		for _, ref := range publishRefs {
+			remote := strings.TrimSuffix(strings.TrimPrefix(ref, checkpoint.PublishRefPrefix), checkpoint.PublishRefSuffix)
+			fmt.Printf("     git push --force %s %s:%s\n", remote, ref, dst)
+		}
+		fmt.Println()
+		fmt.Println("2. Ask teammates to re-sync, discarding their copy of the old history:")
+		fmt.Println("     git fetch <remote>")
+		fmt.Printf("     git update-ref -d %s<remote>%s\n", checkpoint.PublishRefPrefix, checkpoint.PublishRefSuffix)
+		fmt.Printf("     git update-ref -d %s<remote>%s\n", checkpoint.RemoteRefPrefix, checkpoint.PublishRefSuffix)
+		fmt.Println()
+		fmt.Println("3. Once remote-tracking refs point at the new history, reclaim the space:")
+	} else {
+		fmt.Println("No checkpoints have been published, so nothing needs to be force-pushed.")
+		fmt.Println("Reclaim the space with:")
+	}
+	if legacy {
+		fmt.Printf("     git branch -D %s   # its old history is still reachable; run 'partio migrate' first if needed\n", git.LegacyCheckpointBranch)
 	}
+	fmt.Println("     git gc --prune=now")
+}
 
+func printPruneResult(result *checkpoint.PruneResult, dryRun bool) {
 	if dryRun {
 		fmt.Println("Dry run — no changes made.")
 		fmt.Println()
@@ -62,7 +185,7 @@ func runPrune(olderThan string, dryRun bool) error {
 
 	if len(result.Removed) == 0 {
 		fmt.Println("No checkpoints to prune.")
-		return nil
+		return
 	}
 
 	verb := "Removed"
@@ -71,7 +194,11 @@ func runPrune(olderThan string, dryRun bool) error {
 	}
 
 	for _, meta := range result.Removed {
-		fmt.Printf("  %s %s (branch=%s, created=%s)\n", verb, meta.ID, meta.Branch, meta.CreatedAt)
+		reason := ""
+		if r := result.Reasons[meta.ID]; r != "" {
+			reason = ": " + r
+		}
+		fmt.Printf("  %s %s (branch=%s, created=%s)%s\n", verb, meta.ID, meta.Branch, meta.CreatedAt, reason)
 	}
 	fmt.Println()
 
@@ -80,24 +207,4 @@ func runPrune(olderThan string, dryRun bool) error {
 	} else {
 		fmt.Printf("%d checkpoint(s) removed, %d kept.\n", len(result.Removed), len(result.Kept))
 	}
-
-	return nil
-}
-
-// parseDuration parses a duration string like "90d" into a time.Duration.
-// Supports "d" for days as a suffix.
-var durationPattern = regexp.MustCompile(`^(\d+)d$`)
-
-func parseDuration(s string) (time.Duration, error) {
-	matches := durationPattern.FindStringSubmatch(s)
-	if matches == nil {
-		// Try standard Go duration as fallback
-		return time.ParseDuration(s)
-	}
-
-	days, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return 0, err
-	}
-	return time.Duration(days) * 24 * time.Hour, nil
 }
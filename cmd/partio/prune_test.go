@@ -1,34 +0,0 @@
-package main
-
-import (
-	"testing"
-	"time"
-)
-
-func TestParseDuration(t *testing.T) {
-	tests := []struct {
-		input   string
-		want    time.Duration
-		wantErr bool
-	}{
-		{"90d", 90 * 24 * time.Hour, false},
-		{"30d", 30 * 24 * time.Hour, false},
-		{"1d", 24 * time.Hour, false},
-		{"365d", 365 * 24 * time.Hour, false},
-		{"24h", 24 * time.Hour, false},
-		{"", 0, true},
-		{"abc", 0, true},
-		{"d", 0, true},
-	}
-
-	for _, tt := range tests {
-		got, err := parseDuration(tt.input)
-		if (err != nil) != tt.wantErr {
-			t.Errorf("parseDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
-			continue
-		}
-		if !tt.wantErr && got != tt.want {
-			t.Errorf("parseDuration(%q) = %v, want %v", tt.input, got, tt.want)
-		}
-	}
-}
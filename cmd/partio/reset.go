@@ -12,7 +12,7 @@ func newResetCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "reset",
 		Short: "Reset the checkpoint branch",
-		Long:  `Deletes and recreates the partio/checkpoints/v1 branch. This removes all stored checkpoint data.`,
+		Long:  `Deletes and recreates the partio/checkpoints/v2 branch (and deletes any legacy partio/checkpoints/v1 branch). This removes all stored checkpoint data.`,
 		RunE:  runReset,
 	}
 }
@@ -23,10 +23,9 @@ func runReset(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("must be run inside a git repository")
 	}
 
-	const branchName = "partio/checkpoints/v1"
-
-	// Delete existing branch
-	_, _ = git.ExecGit("branch", "-D", branchName)
+	// Delete existing branches, including a legacy one not yet migrated
+	_, _ = git.ExecGit("branch", "-D", git.CheckpointBranch)
+	_, _ = git.ExecGit("branch", "-D", git.LegacyCheckpointBranch)
 
 	// Recreate
 	if err := createCheckpointBranch(); err != nil {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,36 +12,106 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/agent/claude"
 	"github.com/partio-io/cli/internal/checkpoint"
 	"github.com/partio-io/cli/internal/git"
 )
 
 func newResumeCmd() *cobra.Command {
-	var (
-		printFlag bool
-		copyFlag  bool
-		branchFlag bool
-	)
+	var opts resumeOptions
 
 	cmd := &cobra.Command{
-		Use:   "resume <checkpoint-id>",
+		Use:   "resume <checkpoint-id> | --range <base>..<head> | --branch <name>",
 		Short: "Resume a session from a checkpoint",
-		Long:  `Read checkpoint data from the orphan branch and launch a new Claude Code session with the previous context.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Read checkpoint data from the orphan branch and launch the checkpoint's agent
+to continue the work.
+
+If the agent still has the checkpoint's session, it is resumed directly
+(claude --resume, codex resume). Otherwise the agent starts a new session
+from a context prompt composed from the checkpoint: the original request,
+plan, diff and where the session left off. --agent resumes in another agent
+from that prompt, e.g. a Claude Code checkpoint in Codex. For agents partio
+cannot launch the prompt is printed.
+
+--native instead restores the raw transcript stored in the checkpoint to the
+agent and resumes it, so the agent gets the exact prior conversation,
+including tool results, even on another machine or after its own copy was
+deleted. If the agent still has the session, the checkpoint's copy is
+restored under a new session ID and the original is left alone. Only Claude
+Code sessions can be restored.
+
+--range and --branch compose the prompt from every checkpoint of the commits
+in <base>..<head>, or captured on a branch, oldest first, to hand off work
+that spans many sessions. The newest checkpoints are given in full; older
+ones are summarized, then listed in a line each, to keep the prompt within
+--budget tokens.
+
+--checkout creates the branch partio/resume/<id> at the checkpoint's commit
+before launching. It was called --branch before --branch took a branch name.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runResume(args[0], printFlag, copyFlag, branchFlag)
+			chain := opts.rangeSpec != "" || opts.branch != ""
+			switch {
+			case opts.rangeSpec != "" && opts.branch != "":
+				return fmt.Errorf("--range and --branch cannot be combined")
+			case opts.branch != "" && len(args) > 0:
+				return errBranchRenamed
+			case chain && len(args) > 0:
+				return fmt.Errorf("give either a checkpoint ID or --range/--branch")
+			case !chain && len(args) == 0:
+				return fmt.Errorf("a checkpoint ID, --range or --branch is required")
+			case opts.native && (opts.print || opts.copy):
+				return fmt.Errorf("--native cannot be combined with --print or --copy")
+			case chain && (opts.native || opts.checkout):
+				return fmt.Errorf("--native and --checkout need a single checkpoint ID")
+			}
+			if chain {
+				return runResumeChain(opts)
+			}
+			return runResume(args[0], opts)
 		},
 	}
 
-	cmd.Flags().BoolVar(&printFlag, "print", false, "print the composed context prompt to stdout")
-	cmd.Flags().BoolVar(&copyFlag, "copy", false, "copy the context prompt to clipboard")
-	cmd.Flags().BoolVar(&branchFlag, "branch", false, "create a branch at the checkpoint's commit before launching")
+	cmd.Flags().BoolVar(&opts.print, "print", false, "print the composed context prompt to stdout")
+	cmd.Flags().BoolVar(&opts.copy, "copy", false, "copy the context prompt to clipboard")
+	cmd.Flags().BoolVar(&opts.checkout, "checkout", false, "create a branch at the checkpoint's commit before launching")
+	cmd.Flags().BoolVar(&opts.native, "native", false, "restore the checkpoint's own transcript and resume it")
+	cmd.Flags().StringVar(&opts.agent, "agent", "", "agent to resume in (claude-code, codex); defaults to the checkpoint's")
+	cmd.Flags().StringVar(&opts.rangeSpec, "range", "", "resume from the checkpoints of the commits in <base>..<head>")
+	cmd.Flags().StringVar(&opts.branch, "branch", "", "resume from the checkpoints captured on a branch")
+	cmd.Flags().IntVar(&opts.budget, "budget", defaultChainBudget, "approximate token budget of a --range or --branch prompt")
+
+	// `partio resume <id> --branch` used to create a branch at the
+	// checkpoint's commit; point those calls at --checkout.
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		if strings.Contains(err.Error(), "needs an argument: --branch") {
+			return errBranchRenamed
+		}
+		return err
+	})
 
 	return cmd
 }
 
-func runResume(id string, printFlag, copyFlag, branchFlag bool) error {
-	_, err := git.RepoRoot()
+// errBranchRenamed is returned when --branch is used the way it was before it
+// took a branch name.
+var errBranchRenamed = errors.New("--branch now takes a branch name to resume its checkpoints; use --checkout to create a branch at a checkpoint's commit")
+
+// resumeOptions holds the flags of `partio resume`.
+type resumeOptions struct {
+	print     bool
+	copy      bool
+	checkout  bool
+	native    bool
+	agent     string
+	rangeSpec string
+	branch    string
+	budget    int
+}
+
+func runResume(id string, opts resumeOptions) error {
+	repoRoot, err := git.RepoRoot()
 	if err != nil {
 		return fmt.Errorf("must be run inside a git repository")
 	}
@@ -50,7 +121,17 @@ func runResume(id string, printFlag, copyFlag, branchFlag bool) error {
 		return err
 	}
 
-	if branchFlag {
+	detector := checkpointAgent(data.Metadata)
+	if opts.agent != "" {
+		if detector, err = agent.NewDetector(opts.agent); err != nil {
+			return err
+		}
+		if opts.native && opts.agent != data.Metadata.Agent {
+			return fmt.Errorf("--native resumes in the checkpoint's own agent (%s)", data.Metadata.Agent)
+		}
+	}
+
+	if opts.checkout {
 		branchName := fmt.Sprintf("partio/resume/%s", id)
 		_, err := git.ExecGit("checkout", "-b", branchName, data.Metadata.CommitHash)
 		if err != nil {
@@ -59,18 +140,31 @@ func runResume(id string, printFlag, copyFlag, branchFlag bool) error {
 		fmt.Printf("Created branch: %s\n", branchName)
 	}
 
-	prompt := composePrompt(id, data)
+	if opts.native {
+		return resumeNative(detector, repoRoot, data.Metadata)
+	}
+
+	// Only the agent that produced the checkpoint can resume its session.
+	sessionID := ""
+	if detector.Name() == data.Metadata.Agent {
+		sessionID = data.Metadata.SessionID
+	}
+	return handOff(composePrompt(id, data), opts, detector, repoRoot, id, sessionID)
+}
 
-	if printFlag {
+// handOff prints or copies prompt as opts ask, or else launches the agent
+// with it (see launchAgent).
+func handOff(prompt string, opts resumeOptions, detector agent.Detector, repoRoot, name, sessionID string) error {
+	if opts.print {
 		fmt.Print(prompt)
 		return nil
 	}
 
-	if copyFlag {
+	if opts.copy {
 		return copyToClipboard(prompt)
 	}
 
-	return launchClaude(id, prompt)
+	return launchAgent(detector, repoRoot, name, sessionID, prompt)
 }
 
 func composePrompt(id string, data *checkpoint.CheckpointData) string {
@@ -86,12 +180,11 @@ func composePrompt(id string, data *checkpoint.CheckpointData) string {
 		diff = "No diff was recorded."
 	}
 
-	prompt := data.Prompt
-	if prompt == "" && data.Context != "" {
-		prompt = data.Context
-	}
-	if prompt == "" {
-		prompt = "(No prompt was recorded.)"
+	prompt := checkpointRequest(data)
+
+	var progress string
+	if t := checkpointTranscript(data); t != nil {
+		progress = transcriptProgress(t, "##", maxLastMessage)
 	}
 
 	return fmt.Sprintf(`# Previous Session Context
@@ -109,7 +202,7 @@ You are continuing work from a previous Partio session (checkpoint %s).
 ## Changes Made
 
 %s
-
+%s
 ## Session Info
 
 - **Branch:** %s
@@ -120,7 +213,48 @@ You are continuing work from a previous Partio session (checkpoint %s).
 ---
 
 Please review the current state of the repository and continue this work.
-`, id, prompt, plan, diff, meta.Branch, meta.CommitHash, meta.CreatedAt, meta.Agent, meta.AgentPercent)
+`, id, prompt, plan, diff, progress, meta.Branch, meta.CommitHash, meta.CreatedAt, meta.Agent, meta.AgentPercent)
+}
+
+// checkpointRequest returns the request that started a checkpoint's
+// session: its first prompt, else the context summary.
+func checkpointRequest(data *checkpoint.CheckpointData) string {
+	if data.Prompt != "" {
+		return data.Prompt
+	}
+	if data.Context != "" {
+		return data.Context
+	}
+	return "(No prompt was recorded.)"
+}
+
+// maxLastMessage bounds the agent's final message quoted in a resume prompt.
+const maxLastMessage = 2000
+
+// transcriptProgress renders where a session left off, under headings of
+// the given level: the agent's last message, cut to maxLast bytes, and the
+// files its tools touched. It returns "" if the transcript holds neither.
+func transcriptProgress(t *agent.Transcript, heading string, maxLast int) string {
+	var b strings.Builder
+	if m, ok := t.LastMessage("assistant"); ok {
+		fmt.Fprintf(&b, "\n%s Where It Left Off\n\n%s\n", heading, trimText(m.Content, maxLast))
+	}
+	if files := filesTouched(t); len(files) > 0 {
+		fmt.Fprintf(&b, "\n%s Files Touched\n\n", heading)
+		for _, f := range files {
+			fmt.Fprintf(&b, "- %s\n", f)
+		}
+	}
+	return b.String()
+}
+
+// trimText cuts s to at most max bytes, on a UTF-8 boundary, marking the
+// cut with "...".
+func trimText(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "") + "..."
 }
 
 func copyToClipboard(text string) error {
@@ -143,28 +277,101 @@ func copyToClipboard(text string) error {
 	return nil
 }
 
-func launchClaude(id, prompt string) error {
-	claudePath, err := exec.LookPath("claude")
+// checkpointAgent returns the detector of the agent that produced a
+// checkpoint. Checkpoints from before other agents were supported, or from
+// an agent this build does not know, get Claude Code.
+func checkpointAgent(meta checkpoint.Metadata) agent.Detector {
+	if d, err := agent.NewDetector(meta.Agent); err == nil {
+		return d
+	}
+	return claude.New()
+}
+
+// launchAgent replaces this process with the agent's CLI, resuming the
+// agent's session sessionID if it still has it, else starting a new session
+// from prompt, saved as partio-resume-<name>.md. If the agent cannot be
+// launched, prompt is printed instead.
+func launchAgent(detector agent.Detector, repoRoot, name, sessionID, prompt string) error {
+	launcher, ok := detector.(agent.Launcher)
+	if !ok {
+		fmt.Printf("Resuming in %s is not supported. Printing context instead:\n", detector.Name())
+		fmt.Println()
+		fmt.Print(prompt)
+		return nil
+	}
+	binPath, err := exec.LookPath(launcher.Executable())
 	if err != nil {
-		fmt.Println("Claude Code not found in PATH. Printing context instead:")
+		fmt.Printf("%s not found in PATH. Printing context instead:\n", launcher.Executable())
 		fmt.Println()
 		fmt.Print(prompt)
 		return nil
 	}
 
-	// Write context file to temp directory
-	contextFile := filepath.Join(os.TempDir(), "partio-resume-"+id+".md")
-	if err := os.WriteFile(contextFile, []byte(prompt), 0o644); err != nil {
-		return fmt.Errorf("writing context file: %w", err)
+	args, ok := launcher.ResumeArgs(repoRoot, sessionID)
+	if ok {
+		fmt.Printf("Resuming %s session %s...\n", detector.Name(), sessionID)
+	} else {
+		// Write context file to temp directory
+		contextFile := filepath.Join(os.TempDir(), "partio-resume-"+name+".md")
+		if err := os.WriteFile(contextFile, []byte(prompt), 0o644); err != nil {
+			return fmt.Errorf("writing context file: %w", err)
+		}
+		args = launcher.PromptArgs(contextFile)
+		fmt.Printf("Context written to %s\n", contextFile)
+		fmt.Printf("Launching %s...\n", detector.Name())
+	}
+
+	return execAgent(binPath, launcher, repoRoot, args)
+}
+
+// resumeNative restores the checkpoint's raw transcript to its agent,
+// forking it if the agent still has the session, and resumes it.
+func resumeNative(detector agent.Detector, repoRoot string, meta checkpoint.Metadata) error {
+	restorer, canRestore := detector.(agent.SessionRestorer)
+	launcher, canLaunch := detector.(agent.Launcher)
+	if !canRestore || !canLaunch {
+		return fmt.Errorf("native resume is not supported for %s", detector.Name())
+	}
+	if meta.SessionID == "" {
+		return fmt.Errorf("checkpoint %s has no agent session", meta.ID)
+	}
+	binPath, err := exec.LookPath(launcher.Executable())
+	if err != nil {
+		return fmt.Errorf("%s not found in PATH", launcher.Executable())
+	}
+
+	raw, err := checkpoint.ReadFullJSONL(meta.ID)
+	if err != nil {
+		return err
+	}
+	if raw == "" {
+		return fmt.Errorf("checkpoint %s has no transcript", meta.ID)
+	}
+
+	sessionID, path, err := restorer.ForkSession(repoRoot, meta.SessionID, []byte(raw))
+	if err != nil {
+		return err
+	}
+	if sessionID != meta.SessionID {
+		fmt.Printf("Session %s still exists; restored the checkpoint's copy as %s\n", meta.SessionID, sessionID)
 	}
+	fmt.Printf("Transcript restored to %s\n", path)
 
-	fmt.Printf("Context written to %s\n", contextFile)
-	fmt.Println("Launching Claude Code...")
+	args, ok := launcher.ResumeArgs(repoRoot, sessionID)
+	if !ok {
+		return fmt.Errorf("restored session %s cannot be resumed", sessionID)
+	}
+	fmt.Printf("Resuming %s session %s...\n", detector.Name(), sessionID)
+	return execAgent(binPath, launcher, repoRoot, args)
+}
 
-	// Replace this process with claude
-	args := []string{
-		"claude",
-		fmt.Sprintf("Read %s for full context on a previous session, then continue that work.", contextFile),
+// execAgent replaces this process with the agent's CLI, run from repoRoot.
+func execAgent(binPath string, launcher agent.Launcher, repoRoot string, args []string) error {
+	// Agents find sessions by the directory they were launched from.
+	if err := os.Chdir(repoRoot); err != nil {
+		return fmt.Errorf("changing to repository root: %w", err)
 	}
-	return syscall.Exec(claudePath, args, os.Environ())
+
+	// Replace this process with the agent
+	return syscall.Exec(binPath, append([]string{launcher.Executable()}, args...), os.Environ())
 }
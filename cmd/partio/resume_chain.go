@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/git"
+)
+
+// defaultChainBudget is the default token budget of a prompt composed from
+// several checkpoints: a few full sessions, leaving most of the agent's
+// context window for the work itself.
+const defaultChainBudget = 50000
+
+// charsPerToken is the rough number of characters per token of English text
+// and code, used to estimate prompt sizes without a tokenizer.
+const charsPerToken = 4
+
+// maxSummaryText bounds the request and last message of a summarized
+// checkpoint in a chained prompt.
+const maxSummaryText = 500
+
+// maxBriefRequest bounds the request of a checkpoint listed in one line.
+const maxBriefRequest = 120
+
+func runResumeChain(opts resumeOptions) error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+	if opts.budget <= 0 {
+		return fmt.Errorf("--budget must be positive")
+	}
+
+	chain, label, err := chainCheckpoints(repoRoot, opts)
+	if err != nil {
+		return err
+	}
+	if len(chain) == 0 {
+		if opts.branch != "" && !git.BranchExists(opts.branch) {
+			// `partio resume --branch <id>`, from before --checkout.
+			if ids, _ := checkpoint.NewStore(repoRoot).IDs(); slices.Contains(ids, opts.branch) {
+				return errBranchRenamed
+			}
+		}
+		return fmt.Errorf("no checkpoints found for %s", label)
+	}
+
+	newest := chain[len(chain)-1].Metadata
+	detector := checkpointAgent(newest)
+	if opts.agent != "" {
+		if detector, err = agent.NewDetector(opts.agent); err != nil {
+			return err
+		}
+	}
+
+	prompt := composeChainPrompt(label, chain, opts.budget)
+	return handOff(prompt, opts, detector, repoRoot, newest.ID+"-chain", "")
+}
+
+// chainCheckpoints reads the checkpoints selected by --range, those of the
+// commits in the range in commit order, or by --branch, those captured on
+// the branch, oldest first. It also returns a description of the selection.
+func chainCheckpoints(repoRoot string, opts resumeOptions) ([]*checkpoint.CheckpointData, string, error) {
+	summaries, err := checkpoint.NewStore(repoRoot).List()
+	if err != nil {
+		return nil, "", fmt.Errorf("listing checkpoints: %w", err)
+	}
+
+	label, revs := "branch "+opts.branch, opts.branch
+	if opts.rangeSpec != "" {
+		if !strings.Contains(opts.rangeSpec, "..") {
+			return nil, "", fmt.Errorf("--range must be <base>..<head>")
+		}
+		label, revs = "commits "+opts.rangeSpec, opts.rangeSpec
+	}
+
+	// Checkpoints are ordered by their commit's position in history, then by
+	// creation time. Checkpoints on a branch whose commits are no longer in
+	// its history (rebased, or the branch was deleted) come first.
+	order := make(map[string]int)
+	out, err := git.ExecGit("rev-list", "--reverse", revs)
+	if err != nil && opts.rangeSpec != "" {
+		return nil, "", fmt.Errorf("invalid range %s", opts.rangeSpec)
+	}
+	for i, commit := range strings.Fields(out) {
+		order[commit] = i + 1
+	}
+
+	var selected []checkpoint.Metadata
+	for _, s := range summaries {
+		if s.Err != nil {
+			continue
+		}
+		if opts.rangeSpec != "" {
+			if _, ok := order[s.Metadata.CommitHash]; ok {
+				selected = append(selected, s.Metadata)
+			}
+		} else if s.Metadata.Branch == opts.branch {
+			selected = append(selected, s.Metadata)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, b := selected[i], selected[j]
+		if order[a.CommitHash] != order[b.CommitHash] {
+			return order[a.CommitHash] < order[b.CommitHash]
+		}
+		return createdAt(a).Before(createdAt(b))
+	})
+
+	chain := make([]*checkpoint.CheckpointData, 0, len(selected))
+	for _, meta := range selected {
+		data, err := checkpoint.Read(meta.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		chain = append(chain, data)
+	}
+	return chain, label, nil
+}
+
+// createdAt returns when a checkpoint was created, or the zero time if its
+// metadata does not say.
+func createdAt(meta checkpoint.Metadata) time.Time {
+	t, _ := time.Parse(time.RFC3339, meta.CreatedAt)
+	return t
+}
+
+// composeChainPrompt composes a resume prompt from a chain of checkpoints,
+// oldest first, within roughly budget tokens. Filling the budget from the
+// newest checkpoint back, each is given in full, then summarized (trimmed
+// request and last message, changed files instead of the diff), then in a
+// single line, never in more detail than a newer one. The newest is always
+// included, its diff trimmed to fit; the oldest are dropped if even single
+// lines no longer fit.
+func composeChainPrompt(label string, chain []*checkpoint.CheckpointData, budget int) string {
+	header := fmt.Sprintf(`# Previous Session Context
+
+You are continuing work spanning %d Partio checkpoint(s) (%s), oldest first.
+`, len(chain), label)
+	footer := `
+---
+
+Please review the current state of the repository and continue this work.
+`
+	const (
+		briefHeading = "\n## Earlier Checkpoints\n\n"
+		briefLevel   = 2
+		leftOut      = "\n%d earlier checkpoint(s) were left out to fit the token budget.\n"
+	)
+
+	remaining := budget - estimateTokens(header+footer+briefHeading+leftOut)
+	sections := make([]string, len(chain))
+	levels := make([]int, len(chain))
+	level, first := 0, 0
+	for i := len(chain) - 1; i >= 0; i-- {
+		n, data := i+1, chain[i]
+		renders := []func() string{
+			func() string { return fullSection(n, data, -1) },
+			func() string { return summarySection(n, data) },
+			func() string { return briefSection(n, data) },
+		}
+		section := ""
+		if i == len(chain)-1 {
+			// The newest checkpoint stays in full, its diff trimmed to fit.
+			if section = renders[0](); estimateTokens(section) > remaining {
+				base := fullSection(n, data, 0)
+				section = fullSection(n, data, max(0, (remaining-estimateTokens(base))*charsPerToken))
+				level = 1
+			}
+		} else {
+			for ; level < len(renders); level++ {
+				if s := renders[level](); estimateTokens(s) <= remaining {
+					section = s
+					break
+				}
+			}
+		}
+		if section == "" {
+			first = i + 1
+			break
+		}
+		sections[i], levels[i] = section, level
+		remaining -= estimateTokens(section)
+	}
+
+	var b strings.Builder
+	b.WriteString(header)
+	if first > 0 {
+		fmt.Fprintf(&b, leftOut, first)
+	}
+	for i := first; i < len(chain); i++ {
+		// Single lines come first, oldest checkpoints being the least
+		// detailed.
+		if levels[i] == briefLevel && i == first {
+			b.WriteString(briefHeading)
+		}
+		b.WriteString(sections[i])
+	}
+	b.WriteString(footer)
+	return b.String()
+}
+
+// fullSection renders checkpoint n of a chain with its request, plan, diff
+// and progress. A diffLimit of 0 or more cuts the diff to that many bytes.
+func fullSection(n int, data *checkpoint.CheckpointData, diffLimit int) string {
+	var b strings.Builder
+	writeSectionHeader(&b, n, data, "")
+	fmt.Fprintf(&b, "\n### Request\n\n%s\n", checkpointRequest(data))
+	if data.Plan != "" {
+		fmt.Fprintf(&b, "\n### Plan\n\n%s\n", data.Plan)
+	}
+	diff := data.Diff
+	if diff == "" {
+		diff = "No diff was recorded."
+	} else if diffLimit >= 0 && len(diff) > diffLimit {
+		diff = trimText(diff, diffLimit) + "\n(diff trimmed to fit the token budget)"
+	}
+	fmt.Fprintf(&b, "\n### Changes Made\n\n%s\n", diff)
+	if t := checkpointTranscript(data); t != nil {
+		b.WriteString(transcriptProgress(t, "###", maxLastMessage))
+	}
+	return b.String()
+}
+
+// summarySection renders checkpoint n of a chain briefly: trimmed request
+// and last message, and the files its diff changed.
+func summarySection(n int, data *checkpoint.CheckpointData) string {
+	var b strings.Builder
+	writeSectionHeader(&b, n, data, " (summarized)")
+	fmt.Fprintf(&b, "\n### Request\n\n%s\n", trimText(checkpointRequest(data), maxSummaryText))
+	if files := diffFiles(data.Diff); len(files) > 0 {
+		b.WriteString("\n### Changed Files\n\n")
+		for _, f := range files {
+			fmt.Fprintf(&b, "- %s\n", f)
+		}
+	}
+	if t := checkpointTranscript(data); t != nil {
+		if m, ok := t.LastMessage("assistant"); ok {
+			fmt.Fprintf(&b, "\n### Where It Left Off\n\n%s\n", trimText(m.Content, maxSummaryText))
+		}
+	}
+	return b.String()
+}
+
+// briefSection renders checkpoint n of a chain as a single list item.
+func briefSection(n int, data *checkpoint.CheckpointData) string {
+	meta := data.Metadata
+	request, _, _ := strings.Cut(checkpointRequest(data), "\n")
+	return fmt.Sprintf("- %d. %s (commit %s, %s): %s\n", n, meta.ID, shortCommit(meta.CommitHash), meta.CreatedAt, trimText(request, maxBriefRequest))
+}
+
+// writeSectionHeader writes the heading and session info of checkpoint n
+// of a chain.
+func writeSectionHeader(b *strings.Builder, n int, data *checkpoint.CheckpointData, suffix string) {
+	meta := data.Metadata
+	fmt.Fprintf(b, "\n## Checkpoint %d: %s%s\n\n", n, meta.ID, suffix)
+	fmt.Fprintf(b, "- **Commit:** %s on %s\n", meta.CommitHash, meta.Branch)
+	fmt.Fprintf(b, "- **Date:** %s\n", meta.CreatedAt)
+	fmt.Fprintf(b, "- **Agent:** %s (%d%%)\n", meta.Agent, meta.AgentPercent)
+	if meta.Manual {
+		note := "taken without a commit"
+		if meta.Note != "" {
+			note += ": " + meta.Note
+		}
+		fmt.Fprintf(b, "- **Manual checkpoint:** %s\n", note)
+	}
+}
+
+// diffFiles returns the files a unified git diff changes, in order.
+func diffFiles(diff string) []string {
+	var files []string
+	for line := range strings.Lines(diff) {
+		rest, ok := strings.CutPrefix(strings.TrimRight(line, "\n"), "diff --git ")
+		if !ok {
+			continue
+		}
+		if i := strings.LastIndex(rest, " b/"); i >= 0 {
+			files = append(files, rest[i+len(" b/"):])
+		}
+	}
+	return files
+}
+
+// estimateTokens roughly estimates the number of tokens in s.
+func estimateTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+)
+
+func chainCheckpoint(n int, diffSize int) *checkpoint.CheckpointData {
+	return &checkpoint.CheckpointData{
+		Metadata: checkpoint.Metadata{
+			ID:         fmt.Sprintf("aa000000000%d", n),
+			CommitHash: fmt.Sprintf("c%d", n),
+			Branch:     "feature",
+			CreatedAt:  fmt.Sprintf("2025-01-0%dT00:00:00Z", n),
+			Agent:      "claude-code",
+		},
+		Prompt: fmt.Sprintf("Request %d\nwith details", n),
+		Diff:   fmt.Sprintf("diff --git a/file%d.go b/file%d.go\n+%s\n", n, n, strings.Repeat("x", diffSize)),
+	}
+}
+
+func TestComposeChainPrompt(t *testing.T) {
+	chain := []*checkpoint.CheckpointData{chainCheckpoint(1, 4000), chainCheckpoint(2, 4000), chainCheckpoint(3, 4000)}
+
+	tests := []struct {
+		name   string
+		budget int
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "everything fits",
+			budget: 100000,
+			want:   []string{"## Checkpoint 1: aa0000000001\n", "## Checkpoint 2: aa0000000002\n", "## Checkpoint 3: aa0000000003\n", "diff --git a/file1.go"},
+			absent: []string{"summarized", "left out"},
+		},
+		{
+			name:   "older checkpoints summarized",
+			budget: 1400,
+			want:   []string{"## Checkpoint 1: aa0000000001 (summarized)", "## Checkpoint 2: aa0000000002 (summarized)", "- file1.go\n", "## Checkpoint 3: aa0000000003\n", "diff --git a/file3.go"},
+			absent: []string{"diff --git a/file1.go", "Earlier Checkpoints"},
+		},
+		{
+			name:   "oldest listed in a line",
+			budget: 1205,
+			want:   []string{"## Earlier Checkpoints\n\n- 1. aa0000000001 (commit c1, 2025-01-01T00:00:00Z): Request 1\n", "## Checkpoint 2: aa0000000002 (summarized)", "## Checkpoint 3: aa0000000003\n"},
+		},
+		{
+			name:   "newest trimmed, older left out",
+			budget: 300,
+			want:   []string{"## Checkpoint 3: aa0000000003\n", "(diff trimmed to fit the token budget)", "2 earlier checkpoint(s) were left out"},
+			absent: []string{"aa0000000001", "aa0000000002"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := composeChainPrompt("branch feature", chain, tt.budget)
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("prompt is missing %q:\n%s", w, got)
+				}
+			}
+			for _, a := range tt.absent {
+				if strings.Contains(got, a) {
+					t.Errorf("prompt should not contain %q:\n%s", a, got)
+				}
+			}
+			if tokens := estimateTokens(got); tokens > tt.budget {
+				t.Errorf("prompt is ~%d tokens, over the budget of %d", tokens, tt.budget)
+			}
+		})
+	}
+}
+
+func TestDiffFiles(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-x\n+y\ndiff --git a/old.go b/new dir/new.go\n"
+	if got, want := diffFiles(diff), []string{"a.go", "new dir/new.go"}; !slices.Equal(got, want) {
+		t.Errorf("diffFiles = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestResumeOldBranchFlag(t *testing.T) {
+	tests := [][]string{
+		{"a1b2c3d4e5f6", "--branch"},
+		{"a1b2c3d4e5f6", "--branch", "--print"},
+		{"--branch", "main", "a1b2c3d4e5f6"},
+	}
+
+	for _, args := range tests {
+		cmd := newResumeCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		if err := cmd.Execute(); !errors.Is(err, errBranchRenamed) {
+			t.Errorf("resume %v: error = %v, want one pointing at --checkout", args, err)
+		}
+	}
+}
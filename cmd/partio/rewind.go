@@ -1,12 +1,13 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/partio-io/cli/internal/agent"
 	"github.com/partio-io/cli/internal/checkpoint"
 	"github.com/partio-io/cli/internal/git"
 )
@@ -15,18 +16,32 @@ func newRewindCmd() *cobra.Command {
 	var (
 		list bool
 		toID string
+		opts rewindOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:   "rewind",
 		Short: "List or restore checkpoints",
-		Long:  `List all captured checkpoints or restore the repository state to a specific checkpoint.`,
+		Long: `List all captured checkpoints or restore the repository state to a specific checkpoint.
+
+By default --to creates the branch partio/rewind/<id> at the checkpoint's
+commit. For manual checkpoints the uncommitted changes they snapshotted are
+restored into its working tree too.
+
+With --files, the checkpoint's version of the given paths ("." for all) is
+restored into the current branch's working tree instead, leaving the index
+alone. --diff previews what a restore would change without changing
+anything, --stash stashes local changes first, and --transcript restores the
+agent's session transcript so the session can be continued.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if list {
 				return runRewindList()
 			}
 			if toID != "" {
-				return runRewindTo(toID)
+				return runRewindTo(toID, opts)
+			}
+			if len(opts.files) > 0 || opts.diff || opts.stash || opts.transcript {
+				return fmt.Errorf("--files, --diff, --stash and --transcript need --to")
 			}
 			return cmd.Help()
 		},
@@ -34,27 +49,37 @@ func newRewindCmd() *cobra.Command {
 
 	cmd.Flags().BoolVar(&list, "list", false, "list all checkpoints")
 	cmd.Flags().StringVar(&toID, "to", "", "restore to a specific checkpoint ID")
+	cmd.Flags().StringSliceVar(&opts.files, "files", nil, "restore these paths into the current working tree (\".\" for all)")
+	cmd.Flags().BoolVar(&opts.diff, "diff", false, "preview the changes a restore would make")
+	cmd.Flags().BoolVar(&opts.stash, "stash", false, "stash local changes before restoring")
+	cmd.Flags().BoolVar(&opts.transcript, "transcript", false, "restore the agent session transcript")
 
 	return cmd
 }
 
+// rewindOptions selects how `partio rewind --to` restores a checkpoint.
+type rewindOptions struct {
+	files      []string
+	diff       bool
+	stash      bool
+	transcript bool
+}
+
 func runRewindList() error {
-	_, err := git.RepoRoot()
+	repoRoot, err := git.RepoRoot()
 	if err != nil {
 		return fmt.Errorf("must be run inside a git repository")
 	}
 
-	const branch = "partio/checkpoints/v1"
-
-	// Check branch exists
-	_, err = git.ExecGit("rev-parse", "--verify", branch)
-	if err != nil {
+	if !git.BranchExists(git.CheckpointBranch) && !git.BranchExists(git.LegacyCheckpointBranch) {
 		return fmt.Errorf("no checkpoint branch found - run 'partio enable' first")
 	}
 
-	// List top-level shard directories
-	shards, err := git.ExecGit("ls-tree", "--name-only", branch)
-	if err != nil || shards == "" {
+	summaries, err := checkpoint.NewStore(repoRoot).List()
+	if err != nil {
+		return fmt.Errorf("listing checkpoints: %w", err)
+	}
+	if len(summaries) == 0 {
 		fmt.Println("No checkpoints found.")
 		return nil
 	}
@@ -62,77 +87,211 @@ func runRewindList() error {
 	fmt.Println("Checkpoints:")
 	fmt.Println()
 
-	for _, shard := range strings.Split(shards, "\n") {
-		// List entries within each shard
-		entries, err := git.ExecGit("ls-tree", "--name-only", branch+":"+shard)
-		if err != nil {
+	for _, s := range summaries {
+		if s.Err != nil {
+			fmt.Printf("  %s  (%v)\n", s.ID, s.Err)
 			continue
 		}
-		for _, entry := range strings.Split(entries, "\n") {
-			cpID := shard + entry
-			// Try to read metadata
-			metaJSON, err := git.ExecGit("show", branch+":"+shard+"/"+entry+"/metadata.json")
-			if err != nil {
-				fmt.Printf("  %s  (metadata unavailable)\n", cpID)
-				continue
+		extra := ""
+		if s.Metadata.Manual {
+			extra = "  manual"
+			if s.Metadata.Note != "" {
+				extra += fmt.Sprintf(" %q", s.Metadata.Note)
 			}
-
-			var meta checkpoint.Metadata
-			if err := json.Unmarshal([]byte(metaJSON), &meta); err != nil {
-				fmt.Printf("  %s  (invalid metadata)\n", cpID)
-				continue
-			}
-
-			fmt.Printf("  %s  branch=%s  agent=%d%%  created=%s\n",
-				cpID, meta.Branch, meta.AgentPercent, meta.CreatedAt)
 		}
+		if s.Legacy {
+			extra += "  (v1, run 'partio migrate')"
+		}
+		fmt.Printf("  %s  branch=%s  agent=%d%%  created=%s%s\n",
+			s.ID, s.Metadata.Branch, s.Metadata.AgentPercent, s.Metadata.CreatedAt, extra)
 	}
 
 	return nil
 }
 
-func runRewindTo(id string) error {
-	_, err := git.RepoRoot()
+func runRewindTo(id string, opts rewindOptions) error {
+	repoRoot, err := git.RepoRoot()
 	if err != nil {
 		return fmt.Errorf("must be run inside a git repository")
 	}
 
-	if len(id) != 12 {
-		return fmt.Errorf("checkpoint ID must be 12 characters (got %d)", len(id))
-	}
-
-	const branch = "partio/checkpoints/v1"
-	shard := id[:2]
-	rest := id[2:]
-
-	// Read metadata
-	metaJSON, err := git.ExecGit("show", branch+":"+shard+"/"+rest+"/metadata.json")
+	data, err := checkpoint.Read(id)
 	if err != nil {
-		return fmt.Errorf("checkpoint %s not found", id)
+		return err
 	}
+	meta := data.Metadata
+	source := rewindSource(meta)
 
-	var meta checkpoint.Metadata
-	if err := json.Unmarshal([]byte(metaJSON), &meta); err != nil {
-		return fmt.Errorf("invalid checkpoint metadata: %w", err)
+	paths := opts.files
+	if len(paths) == 0 {
+		paths = []string{"."}
 	}
 
-	// Read session context
-	context, _ := git.ExecGit("show", branch+":"+shard+"/"+rest+"/0/context.md")
+	if opts.diff {
+		// Restoring makes the working tree match source: show the diff
+		// from the working tree to it, with the usual a/ and b/ prefixes
+		// (which -R would swap).
+		args := []string{"diff", "-R", "--src-prefix=b/", "--dst-prefix=a/", source, "--"}
+		preview, err := git.ExecGit(append(args, paths...)...)
+		if err != nil {
+			return fmt.Errorf("diffing against checkpoint: %w", err)
+		}
+		if preview == "" {
+			fmt.Println("No differences from the working tree.")
+			return nil
+		}
+		fmt.Println(preview)
+		return nil
+	}
 
 	fmt.Printf("Rewinding to checkpoint %s\n", id)
 	fmt.Printf("  Commit: %s\n", meta.CommitHash)
+	if source != meta.CommitHash {
+		fmt.Printf("  Snapshot: %s\n", source)
+	}
 	fmt.Printf("  Branch: %s\n", meta.Branch)
-	if context != "" {
-		fmt.Printf("  Context:\n%s\n", context)
+	if data.Context != "" {
+		fmt.Printf("  Context:\n%s\n", data.Context)
+	}
+
+	if len(opts.files) == 0 && source != meta.CommitHash && !opts.stash {
+		// The snapshot replaces the whole working tree.
+		dirty, err := localChanges(nil)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("local changes would be overwritten by the checkpoint's snapshot - commit them or use --stash")
+		}
+	}
+
+	if opts.stash {
+		// Without --files another commit is checked out, so the whole
+		// working tree is stashed.
+		if err := stashLocalChanges(id, opts.files); err != nil {
+			return err
+		}
+	}
+
+	if len(opts.files) > 0 {
+		if err := restoreFiles(source, opts.files); err != nil {
+			return err
+		}
+		fmt.Printf("  Restored: %s\n", strings.Join(opts.files, ", "))
+	} else {
+		branchName := fmt.Sprintf("partio/rewind/%s", id)
+		if _, err := git.ExecGit("checkout", "-b", branchName, meta.CommitHash); err != nil {
+			return fmt.Errorf("creating rewind branch: %w", err)
+		}
+		fmt.Printf("  Created branch: %s\n", branchName)
+		if source != meta.CommitHash {
+			if _, err := git.ExecGit("restore", "--source="+source, "--worktree", "--", "."); err != nil {
+				return fmt.Errorf("restoring snapshot: %w", err)
+			}
+			fmt.Println("  Restored uncommitted changes from the snapshot")
+		}
 	}
 
-	// Create a new branch at the checkpoint's commit
-	branchName := fmt.Sprintf("partio/rewind/%s", id)
-	_, err = git.ExecGit("checkout", "-b", branchName, meta.CommitHash)
+	if opts.transcript {
+		return restoreTranscript(repoRoot, meta)
+	}
+	return nil
+}
+
+// rewindSource returns the commit whose tree a checkpoint restores: the
+// working-tree snapshot of a manual checkpoint, or else its commit. Snapshots
+// are local, so a manual checkpoint fetched from elsewhere falls back to its
+// commit.
+func rewindSource(meta checkpoint.Metadata) string {
+	if meta.Snapshot == "" {
+		return meta.CommitHash
+	}
+	if _, err := git.ExecGit("cat-file", "-e", meta.Snapshot+"^{commit}"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: snapshot %s of checkpoint %s is not in this repository; using its commit\n", meta.Snapshot, meta.ID)
+		return meta.CommitHash
+	}
+	return meta.Snapshot
+}
+
+// restoreFiles overwrites paths in the working tree with their version in
+// source, refusing to discard local changes to them.
+func restoreFiles(source string, paths []string) error {
+	dirty, err := localChanges(paths)
 	if err != nil {
-		return fmt.Errorf("creating rewind branch: %w", err)
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("local changes to %s would be overwritten - commit them or use --stash", strings.Join(paths, ", "))
+	}
+	if _, err := git.ExecGit(append([]string{"restore", "--source=" + source, "--worktree", "--"}, paths...)...); err != nil {
+		return fmt.Errorf("restoring files from checkpoint: %w", err)
 	}
+	return nil
+}
 
-	fmt.Printf("  Created branch: %s\n", branchName)
+// stashLocalChanges stashes uncommitted changes, including untracked files,
+// to paths, or to the whole working tree if paths is empty.
+func stashLocalChanges(id string, paths []string) error {
+	dirty, err := localChanges(paths)
+	if err != nil {
+		return err
+	}
+	if !dirty {
+		fmt.Println("  No local changes to stash")
+		return nil
+	}
+	args := []string{"stash", "push", "--include-untracked", "-m", "partio rewind " + id}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	if _, err := git.ExecGit(args...); err != nil {
+		return fmt.Errorf("stashing local changes: %w", err)
+	}
+	fmt.Println("  Stashed local changes (run 'git stash pop' to bring them back)")
+	return nil
+}
+
+// localChanges reports whether paths, or the whole working tree if paths is
+// empty, have staged, unstaged or untracked changes.
+func localChanges(paths []string) (bool, error) {
+	out, err := git.ExecGit(append([]string{"status", "--porcelain", "--"}, paths...)...)
+	if err != nil {
+		return false, fmt.Errorf("checking for local changes: %w", err)
+	}
+	return out != "", nil
+}
+
+// restoreTranscript puts the checkpoint's raw session transcript back where
+// its agent keeps sessions, so the session can be continued.
+func restoreTranscript(repoRoot string, meta checkpoint.Metadata) error {
+	if meta.SessionID == "" {
+		return fmt.Errorf("checkpoint %s has no agent session", meta.ID)
+	}
+	detector, err := agent.NewDetector(meta.Agent)
+	if err != nil {
+		return fmt.Errorf("restoring transcript: %w", err)
+	}
+	restorer, ok := detector.(agent.SessionRestorer)
+	if !ok {
+		return fmt.Errorf("restoring %s transcripts is not supported", meta.Agent)
+	}
+
+	raw, err := checkpoint.ReadFullJSONL(meta.ID)
+	if err != nil {
+		return err
+	}
+	if raw == "" {
+		return fmt.Errorf("checkpoint %s has no transcript", meta.ID)
+	}
+
+	path, restored, err := restorer.RestoreSession(repoRoot, meta.SessionID, []byte(raw))
+	if err != nil {
+		return err
+	}
+	if restored {
+		fmt.Printf("  Transcript: restored session %s to %s\n", meta.SessionID, path)
+	} else {
+		fmt.Printf("  Transcript: session %s is already present at %s\n", meta.SessionID, path)
+	}
 	return nil
 }
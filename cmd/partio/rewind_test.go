@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+)
+
+func TestRewindSnapshotRefusesLocalChanges(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+	t.Chdir(dir)
+
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile("a.go", []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	write("base\n")
+	run("add", "a.go")
+	run("commit", "-q", "-m", "base")
+	head := run("rev-parse", "HEAD")
+
+	// Take a manual checkpoint of work in progress.
+	const id = "ee0000000001"
+	write("checkpointed\n")
+	store := checkpoint.NewStore(dir)
+	snap, err := store.Snapshot(id, head, "snapshot")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	cp := &checkpoint.Checkpoint{ID: id, CommitHash: head, Branch: "main", CreatedAt: time.Now(), Manual: true, Snapshot: snap}
+	if err := store.Write(cp, &checkpoint.SessionFiles{Prompt: "wip"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	write("newer work\n")
+	if err := runRewindTo(id, rewindOptions{}); err == nil || !strings.Contains(err.Error(), "--stash") {
+		t.Fatalf("rewind over local changes: error = %v, want one suggesting --stash", err)
+	}
+	if data, _ := os.ReadFile("a.go"); string(data) != "newer work\n" {
+		t.Errorf("a.go = %q after a refused rewind", data)
+	}
+	if branch := run("branch", "--show-current"); branch != "main" {
+		t.Errorf("refused rewind switched to %s", branch)
+	}
+
+	if err := runRewindTo(id, rewindOptions{stash: true}); err != nil {
+		t.Fatalf("rewind --stash: %v", err)
+	}
+	if data, _ := os.ReadFile("a.go"); string(data) != "checkpointed\n" {
+		t.Errorf("a.go = %q, want the snapshot's version", data)
+	}
+	if stash := run("stash", "list"); !strings.Contains(stash, "partio rewind "+id) {
+		t.Errorf("local changes were not stashed: %q", stash)
+	}
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/git"
+	"github.com/partio-io/cli/internal/session"
+)
+
+func newSessionsCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "List, inspect and end tracked agent sessions",
+		Long: `Shows the agent sessions partio tracks in this repository: their agent,
+state, whether the agent process is still running, branch, worktree, the
+checkpoints they were captured in and when they were last active.
+
+When a commit was not checkpointed, this is the first place to look: the
+session may have been ended, already condensed, or tracked under another
+worktree.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionsList(jsonOut)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print the sessions as JSON")
+
+	cmd.AddCommand(newSessionsListCmd(), newSessionsShowCmd(), newSessionsEndCmd(), newSessionsForgetCmd())
+
+	return cmd
+}
+
+func newSessionsListCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tracked sessions, most recently active first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionsList(jsonOut)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print the sessions as JSON")
+
+	return cmd
+}
+
+func newSessionsShowCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "show <session-id>",
+		Short: "Show a tracked session's details",
+		Long:  `Shows a tracked session. The ID may be partio's session ID, the agent's own session ID, or an unambiguous prefix of either.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionsShow(args[0], jsonOut)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print the session as JSON")
+
+	return cmd
+}
+
+func newSessionsEndCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "end <session-id>...",
+		Short: "Mark sessions as ended",
+		Long:  `Transitions sessions to ENDED state, as if their agent had exited. Commits no longer pick them up as active sessions.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionsEnd(args)
+		},
+	}
+}
+
+func newSessionsForgetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "forget <session-id>...",
+		Short: "Stop tracking sessions",
+		Long: `Removes sessions from partio's session state. Checkpoints are not affected,
+but partio no longer remembers that a forgotten session was captured, so a
+later commit may capture its transcript again.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSessionsForget(args)
+		},
+	}
+}
+
+// sessionInfo is the JSON form of a tracked session.
+type sessionInfo struct {
+	*session.Session
+	// ProcessAlive is unset when no agent PID is recorded.
+	ProcessAlive *bool `json:"process_alive,omitempty"`
+	// LastActivity is the last transcript entry when the transcript is
+	// known, else the session's last update.
+	LastActivity time.Time          `json:"last_activity"`
+	Checkpoints  []linkedCheckpoint `json:"checkpoints"`
+}
+
+// linkedCheckpoint is a checkpoint that captured a tracked session.
+type linkedCheckpoint struct {
+	ID         string `json:"id"`
+	CommitHash string `json:"commit_hash"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// sessionsManager returns the session manager of the current repository.
+func sessionsManager() (*session.Manager, string, error) {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return nil, "", fmt.Errorf("must be run inside a git repository")
+	}
+	return session.NewManager(filepath.Join(repoRoot, config.PartioDir)), repoRoot, nil
+}
+
+// detectIdleSessions brings the ACTIVE and IDLE states of the tracked
+// sessions up to date with their transcripts.
+func detectIdleSessions(mgr *session.Manager) {
+	quiet := cfg.IdleThreshold.Duration()
+	if quiet <= 0 {
+		return
+	}
+	changed, err := mgr.DetectIdle(quiet, time.Now())
+	if err != nil {
+		slog.Debug("idle session detection failed", "error", err)
+	}
+	for _, s := range changed {
+		slog.Debug("session state changed", "id", s.ID, "state", s.State)
+	}
+}
+
+// describeSessions gathers the details shown for each session, linking them
+// to the checkpoints on the checkpoint branch.
+func describeSessions(mgr *session.Manager, repoRoot string, sessions []*session.Session) []sessionInfo {
+	summaries, err := checkpoint.NewStore(repoRoot).List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not list checkpoints: %v\n", err)
+	}
+
+	infos := make([]sessionInfo, len(sessions))
+	for i, s := range sessions {
+		info := sessionInfo{
+			Session:      s,
+			LastActivity: mgr.UpdatedAt(s),
+			Checkpoints:  linkedCheckpoints(summaries, s),
+		}
+		if s.TranscriptPath != "" {
+			if activity, err := session.TranscriptActivity(s.TranscriptPath); err == nil {
+				info.LastActivity = activity
+			}
+		}
+		if s.AgentPID > 0 {
+			alive := session.ProcessAlive(s.AgentPID)
+			info.ProcessAlive = &alive
+		}
+		infos[i] = info
+	}
+	return infos
+}
+
+// linkedCheckpoints returns the checkpoints that captured the agent session
+// behind s, oldest first.
+func linkedCheckpoints(summaries []checkpoint.Summary, s *session.Session) []linkedCheckpoint {
+	ids := make(map[string]bool)
+	for _, id := range []string{s.AgentSessionID, s.CapturedSessionID} {
+		if id != "" {
+			ids[id] = true
+		}
+	}
+
+	linked := []linkedCheckpoint{}
+	if len(ids) == 0 {
+		return linked
+	}
+	for _, sum := range summaries {
+		if sum.Err != nil {
+			continue
+		}
+		match := ids[sum.Metadata.SessionID]
+		for _, ref := range sum.Metadata.Sessions {
+			match = match || ids[ref.SessionID]
+		}
+		if match {
+			linked = append(linked, linkedCheckpoint{ID: sum.ID, CommitHash: sum.Metadata.CommitHash, CreatedAt: sum.Metadata.CreatedAt})
+		}
+	}
+	return linked
+}
+
+func runSessionsList(jsonOut bool) error {
+	mgr, repoRoot, err := sessionsManager()
+	if err != nil {
+		return err
+	}
+	detectIdleSessions(mgr)
+	sessions, err := mgr.List()
+	if err != nil {
+		return fmt.Errorf("listing sessions: %w", err)
+	}
+	infos := describeSessions(mgr, repoRoot, sessions)
+
+	if jsonOut {
+		return printJSON(infos, "sessions")
+	}
+
+	if len(infos) == 0 {
+		fmt.Println("No tracked sessions.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tAGENT\tSTATE\tPROCESS\tBRANCH\tWORKTREE\tCHECKPOINTS\tLAST ACTIVITY\t")
+	for _, info := range infos {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t\n",
+			shortSessionID(info.ID), info.Agent, info.State, processStatus(info),
+			info.Branch, info.SourceDir, len(info.Checkpoints), formatAgo(info.LastActivity))
+	}
+	return w.Flush()
+}
+
+func runSessionsShow(ref string, jsonOut bool) error {
+	mgr, repoRoot, err := sessionsManager()
+	if err != nil {
+		return err
+	}
+	detectIdleSessions(mgr)
+	s, err := mgr.Resolve(ref)
+	if err != nil {
+		return err
+	}
+	info := describeSessions(mgr, repoRoot, []*session.Session{s})[0]
+
+	if jsonOut {
+		return printJSON(info, "session")
+	}
+
+	fmt.Printf("Session %s\n", info.ID)
+	fmt.Printf("  Agent:      %s\n", info.Agent)
+	if info.AgentSessionID != "" {
+		fmt.Printf("  Agent ID:   %s\n", info.AgentSessionID)
+	}
+	state := string(info.State)
+	if info.Condensed {
+		state += ", condensed"
+	}
+	fmt.Printf("  State:      %s\n", state)
+	if info.LastEvent != "" {
+		fmt.Printf("  Last event: %s\n", info.LastEvent)
+	}
+	fmt.Printf("  Process:    %s\n", processStatus(info))
+	fmt.Printf("  Branch:     %s\n", info.Branch)
+	fmt.Printf("  Worktree:   %s\n", info.SourceDir)
+	if info.TranscriptPath != "" {
+		fmt.Printf("  Transcript: %s\n", info.TranscriptPath)
+	}
+	fmt.Printf("  Started:    %s\n", info.StartedAt.Format(time.RFC3339))
+	if !info.EndedAt.IsZero() {
+		fmt.Printf("  Ended:      %s\n", info.EndedAt.Format(time.RFC3339))
+	}
+	if !info.CapturedAt.IsZero() {
+		fmt.Printf("  Captured:   %s\n", info.CapturedAt.Format(time.RFC3339))
+	}
+	fmt.Printf("  Active:     %s\n", formatAgo(info.LastActivity))
+
+	fmt.Println()
+	if len(info.Checkpoints) == 0 {
+		fmt.Println("No checkpoints captured this session.")
+		return nil
+	}
+	fmt.Printf("%d checkpoint(s):\n", len(info.Checkpoints))
+	for _, cp := range info.Checkpoints {
+		fmt.Printf("  %s  commit %s  %s\n", cp.ID, shortCommit(cp.CommitHash), cp.CreatedAt)
+	}
+	return nil
+}
+
+func runSessionsEnd(refs []string) error {
+	mgr, _, err := sessionsManager()
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		s, err := mgr.Resolve(ref)
+		if err != nil {
+			return err
+		}
+		if s.State == session.StateEnded {
+			fmt.Printf("Session %s already ended.\n", shortSessionID(s.ID))
+			continue
+		}
+		if err := mgr.End(s.ID); err != nil {
+			return fmt.Errorf("ending session %s: %w", s.ID, err)
+		}
+		fmt.Printf("Ended session %s (agent=%s)\n", shortSessionID(s.ID), s.Agent)
+	}
+	return nil
+}
+
+func runSessionsForget(refs []string) error {
+	mgr, _, err := sessionsManager()
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		s, err := mgr.Resolve(ref)
+		if err != nil {
+			return err
+		}
+		if err := mgr.Forget(s.ID); err != nil {
+			return fmt.Errorf("forgetting session %s: %w", s.ID, err)
+		}
+		fmt.Printf("Forgot session %s (agent=%s)\n", shortSessionID(s.ID), s.Agent)
+	}
+	return nil
+}
+
+// printJSON prints v as indented JSON; what names it in errors.
+func printJSON(v any, what string) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", what, err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// processStatus describes whether the session's agent process is running.
+func processStatus(info sessionInfo) string {
+	switch {
+	case info.ProcessAlive == nil:
+		return "unknown"
+	case *info.ProcessAlive:
+		return fmt.Sprintf("running (pid %d)", info.AgentPID)
+	default:
+		return fmt.Sprintf("exited (pid %d)", info.AgentPID)
+	}
+}
+
+// shortSessionID abbreviates a session UUID for display.
+func shortSessionID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// formatAgo describes how long ago t was, e.g. "5m ago".
+func formatAgo(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/session"
+)
+
+func TestLinkedCheckpoints(t *testing.T) {
+	summaries := []checkpoint.Summary{
+		{ID: "aaaaaaaaaaaa", Metadata: checkpoint.Metadata{SessionID: "sess-1", CommitHash: "c1"}},
+		{ID: "bbbbbbbbbbbb", Metadata: checkpoint.Metadata{Sessions: []checkpoint.SessionRef{{SessionID: "other"}, {Index: 1, SessionID: "sess-1"}}}},
+		{ID: "cccccccccccc", Metadata: checkpoint.Metadata{SessionID: "sess-2"}},
+		{ID: "dddddddddddd", Metadata: checkpoint.Metadata{SessionID: "sess-1"}, Err: errors.New("metadata unavailable")},
+	}
+
+	tests := []struct {
+		name    string
+		session *session.Session
+		want    []string
+	}{
+		{"by agent session ID", &session.Session{AgentSessionID: "sess-1"}, []string{"aaaaaaaaaaaa", "bbbbbbbbbbbb"}},
+		{"by captured session ID", &session.Session{CapturedSessionID: "sess-2"}, []string{"cccccccccccc"}},
+		{"unknown agent session", &session.Session{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := linkedCheckpoints(summaries, tt.session)
+			if len(got) != len(tt.want) {
+				t.Fatalf("linkedCheckpoints = %+v, want %v", got, tt.want)
+			}
+			for i, cp := range got {
+				if cp.ID != tt.want[i] {
+					t.Errorf("checkpoint %d = %s, want %s", i, cp.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatAgo(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Time{}, "unknown"},
+		{now.Add(-10 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{now.Add(-72 * time.Hour), "3d ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAgo(tt.t); got != tt.want {
+			t.Errorf("formatAgo(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
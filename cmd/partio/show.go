@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/git"
+)
+
+func newShowCmd() *cobra.Command {
+	var (
+		jsonOut        bool
+		transcriptFlag bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show <checkpoint-id>",
+		Short: "Show a checkpoint's details, token usage and estimated cost",
+		Long: `Prints a checkpoint's metadata and, for each captured session, the models
+used, the input, output, cache-write and cache-read tokens consumed and the
+estimated cost. Costs use built-in list prices, which the "pricing" setting
+can override per model.
+
+With --transcript, prints the session's normalized transcript instead: the
+same JSON format for every agent, holding messages, thinking, attachments
+and tool calls.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runShow(args[0], jsonOut, transcriptFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print the checkpoint as JSON")
+	cmd.Flags().BoolVar(&transcriptFlag, "transcript", false, "print the session's normalized transcript as JSON")
+
+	return cmd
+}
+
+// showSession is the JSON form of a session in `partio show --json`.
+type showSession struct {
+	checkpoint.SessionMetadata
+	EstimatedCost float64  `json:"estimated_cost_usd"`
+	Unpriced      []string `json:"unpriced_models,omitempty"`
+}
+
+func runShow(id string, jsonOut, transcriptFlag bool) error {
+	if _, err := git.RepoRoot(); err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	data, err := checkpoint.Read(id)
+	if err != nil {
+		return err
+	}
+	meta := data.Metadata
+	transcript := checkpointTranscript(data)
+
+	if transcriptFlag {
+		if transcript == nil {
+			return fmt.Errorf("checkpoint %s has no transcript", id)
+		}
+		out, err := json.MarshalIndent(transcript, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling transcript: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	table := priceTable()
+	sessions := make([]showSession, len(data.Sessions))
+	var total float64
+	var unpriced []string
+	for i, sm := range data.Sessions {
+		cost, missing := sm.EstimatedCost(table)
+		sessions[i] = showSession{SessionMetadata: sm, EstimatedCost: cost, Unpriced: missing}
+		total += cost
+		unpriced = append(unpriced, missing...)
+	}
+
+	if jsonOut {
+		out, err := json.MarshalIndent(struct {
+			checkpoint.Metadata
+			SessionDetails []showSession `json:"session_details"`
+			EstimatedCost  float64       `json:"estimated_cost_usd"`
+		}{meta, sessions, total}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling checkpoint: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("Checkpoint %s\n", meta.ID)
+	fmt.Printf("  Commit:   %s\n", meta.CommitHash)
+	if meta.Manual {
+		fmt.Printf("  Snapshot: %s (manual checkpoint)\n", meta.Snapshot)
+	}
+	if meta.Note != "" {
+		fmt.Printf("  Note:     %s\n", meta.Note)
+	}
+	fmt.Printf("  Branch:   %s\n", meta.Branch)
+	if meta.Author != "" {
+		fmt.Printf("  Author:   %s\n", meta.Author)
+	}
+	fmt.Printf("  Created:  %s\n", meta.CreatedAt)
+	fmt.Printf("  Agent:    %s (%d%%)\n", meta.Agent, meta.AgentPercent)
+	schema := fmt.Sprintf("v%d", meta.SchemaVersion)
+	if meta.PartioVersion != "" {
+		schema += ", partio " + meta.PartioVersion
+	}
+	if meta.HookStrategy != "" {
+		schema += ", " + meta.HookStrategy
+	}
+	fmt.Printf("  Schema:   %s\n", schema)
+	if meta.Encrypted {
+		fmt.Println("  Encrypted: yes")
+	}
+
+	for i, s := range sessions {
+		fmt.Println()
+		fmt.Printf("Session %d", i)
+		if s.SessionID != "" {
+			fmt.Printf(" (%s)", s.SessionID)
+		}
+		fmt.Println()
+		fmt.Printf("  Agent:    %s\n", s.Agent)
+		if len(s.Models) > 0 {
+			fmt.Printf("  Models:   %s\n", strings.Join(s.Models, ", "))
+		}
+		if s.Duration != "" {
+			fmt.Printf("  Duration: %s\n", s.Duration)
+		}
+		t := s.Tokens
+		if t.Total() > 0 {
+			fmt.Printf("  Tokens:   %s input, %s output, %s cache write, %s cache read (%s total)\n",
+				formatCount(t.Input), formatCount(t.Output), formatCount(t.CacheCreation), formatCount(t.CacheRead), formatCount(t.Total()))
+		} else {
+			fmt.Printf("  Tokens:   %s total\n", formatCount(s.TotalTokens))
+		}
+		fmt.Printf("  Cost:     %s (estimated)\n", formatCost(s.EstimatedCost, s.Unpriced))
+		if m := s.Match; m != nil && m.Score > 0 {
+			fmt.Printf("  Matched:  score %.2f among %d candidate(s): %s\n", m.Score, m.Candidates, m.Reason)
+		} else if m != nil {
+			fmt.Printf("  Matched:  %s\n", m.Reason)
+		}
+		if i == 0 && transcript != nil {
+			if summary := transcriptSummary(transcript); summary != "" {
+				fmt.Printf("  Messages: %s\n", summary)
+			}
+			if summary := toolSummary(transcript); summary != "" {
+				fmt.Printf("  Tools:    %s\n", summary)
+			}
+		}
+	}
+
+	if data.Context != "" {
+		fmt.Println()
+		fmt.Println("Context:")
+		fmt.Printf("  %s\n", data.Context)
+	}
+	return nil
+}
+
+// transcriptSummary describes a transcript's messages, e.g.
+// "14 (6 user, 8 assistant), 5 thinking block(s), 1 attachment(s)".
+func transcriptSummary(t *agent.Transcript) string {
+	if len(t.Messages) == 0 {
+		return ""
+	}
+	roles := make(map[string]int)
+	var thinking, attachments int
+	for _, m := range t.Messages {
+		roles[m.Role]++
+		thinking += len(m.Thinking)
+		attachments += len(m.Attachments)
+	}
+
+	summary := fmt.Sprintf("%d (%d user, %d assistant)", len(t.Messages), roles["user"], roles["assistant"])
+	if thinking > 0 {
+		summary += fmt.Sprintf(", %d thinking block(s)", thinking)
+	}
+	if attachments > 0 {
+		summary += fmt.Sprintf(", %d attachment(s)", attachments)
+	}
+	if len(t.Threads) > 0 {
+		summary += fmt.Sprintf(", %d sub-agent thread(s)", len(t.Threads))
+	}
+	return summary
+}
+
+// toolSummary describes a transcript's tool calls, e.g.
+// "12 call(s) (Edit 5, Bash 4, Read 3), 3 file(s) touched".
+func toolSummary(t *agent.Transcript) string {
+	calls := t.ToolCalls
+	if len(calls) == 0 {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, c := range calls {
+		counts[c.Name]++
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, counts[name])
+	}
+
+	summary := fmt.Sprintf("%d call(s) (%s)", len(calls), strings.Join(parts, ", "))
+	if files := filesTouched(t); len(files) > 0 {
+		summary += fmt.Sprintf(", %d file(s) touched", len(files))
+	}
+	return summary
+}
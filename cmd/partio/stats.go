@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/git"
+	"github.com/partio-io/cli/internal/pricing"
+)
+
+func newStatsCmd() *cobra.Command {
+	var (
+		since   string
+		jsonOut bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize token usage and estimated AI spend",
+		Long: `Totals the tokens consumed by every captured session in this repository and
+estimates what they cost, broken down by model and by commit author.
+
+Costs use built-in list prices, which the "pricing" setting can override or
+extend per model (USD per million tokens):
+
+  "pricing": {"claude-sonnet-4": {"input": 3, "output": 15, "cache_creation": 3.75, "cache_read": 0.3}}`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStats(since, jsonOut)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "only count checkpoints created within this window (e.g. 30d)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "print the statistics as JSON")
+
+	return cmd
+}
+
+// usageStats accumulates token usage and cost for one group of sessions.
+type usageStats struct {
+	Name          string           `json:"name"`
+	Checkpoints   int              `json:"checkpoints"`
+	Sessions      int              `json:"sessions"`
+	Tokens        agent.TokenUsage `json:"tokens"`
+	EstimatedCost float64          `json:"estimated_cost_usd"`
+	// Unpriced counts tokens of models without a price.
+	Unpriced int `json:"unpriced_tokens,omitempty"`
+}
+
+// repoStats is the output of `partio stats`.
+type repoStats struct {
+	Since    string        `json:"since,omitempty"`
+	Total    usageStats    `json:"total"`
+	ByModel  []*usageStats `json:"by_model"`
+	ByAuthor []*usageStats `json:"by_author"`
+}
+
+func runStats(since string, jsonOut bool) error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	var cutoff time.Time
+	if since != "" {
+		d, err := config.ParseDuration(since)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		cutoff = time.Now().Add(-d)
+	}
+
+	summaries, err := checkpoint.NewStore(repoRoot).List()
+	if err != nil {
+		return fmt.Errorf("listing checkpoints: %w", err)
+	}
+
+	stats := collectStats(summaries, cutoff, priceTable())
+	stats.Since = since
+
+	if jsonOut {
+		out, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling stats: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	printStats(stats)
+	return nil
+}
+
+// collectStats aggregates the sessions of checkpoints created at or after
+// cutoff (all checkpoints when cutoff is zero), pricing them with table.
+func collectStats(summaries []checkpoint.Summary, cutoff time.Time, table pricing.Table) *repoStats {
+	stats := &repoStats{Total: usageStats{Name: "total"}}
+	byModel := make(map[string]*usageStats)
+	byAuthor := make(map[string]*usageStats)
+
+	group := func(groups map[string]*usageStats, name string) *usageStats {
+		if groups[name] == nil {
+			groups[name] = &usageStats{Name: name}
+		}
+		return groups[name]
+	}
+
+	for _, s := range summaries {
+		if s.Err != nil {
+			continue
+		}
+		if !cutoff.IsZero() {
+			created, err := time.Parse(time.RFC3339, s.Metadata.CreatedAt)
+			if err != nil || created.Before(cutoff) {
+				continue
+			}
+		}
+
+		author := s.Metadata.Author
+		if author == "" {
+			author = "unknown"
+		}
+		a := group(byAuthor, author)
+		a.Checkpoints++
+		stats.Total.Checkpoints++
+
+		for _, sm := range s.Sessions {
+			a.Sessions++
+			stats.Total.Sessions++
+			for model, t := range sm.ModelUsage() {
+				m := group(byModel, model)
+				m.Sessions++
+				cost, priced := t.Cost(table, model)
+				for _, u := range []*usageStats{m, a, &stats.Total} {
+					u.Tokens.Add(t)
+					if priced {
+						u.EstimatedCost += cost
+					} else {
+						u.Unpriced += t.Total()
+					}
+				}
+			}
+		}
+	}
+
+	stats.ByModel = sortedStats(byModel)
+	stats.ByAuthor = sortedStats(byAuthor)
+	return stats
+}
+
+// sortedStats orders groups by estimated cost, then tokens, descending.
+func sortedStats(groups map[string]*usageStats) []*usageStats {
+	list := make([]*usageStats, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].EstimatedCost != list[j].EstimatedCost {
+			return list[i].EstimatedCost > list[j].EstimatedCost
+		}
+		if list[i].Tokens.Total() != list[j].Tokens.Total() {
+			return list[i].Tokens.Total() > list[j].Tokens.Total()
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+func printStats(stats *repoStats) {
+	t := stats.Total
+	if stats.Since != "" {
+		fmt.Printf("Checkpoints in the last %s: %d (%d sessions)\n", stats.Since, t.Checkpoints, t.Sessions)
+	} else {
+		fmt.Printf("Checkpoints: %d (%d sessions)\n", t.Checkpoints, t.Sessions)
+	}
+	if t.Checkpoints == 0 {
+		return
+	}
+	fmt.Printf("Tokens:      %s input, %s output, %s cache write, %s cache read (%s total)\n",
+		formatCount(t.Tokens.Input), formatCount(t.Tokens.Output), formatCount(t.Tokens.CacheCreation),
+		formatCount(t.Tokens.CacheRead), formatCount(t.Tokens.Total()))
+	fmt.Printf("Estimated:   $%.2f\n", t.EstimatedCost)
+	if t.Unpriced > 0 {
+		fmt.Printf("             (%s tokens from models without a price are not included)\n", formatCount(t.Unpriced))
+	}
+
+	printStatsTable("MODEL", stats.ByModel)
+	printStatsTable("AUTHOR", stats.ByAuthor)
+}
+
+func printStatsTable(heading string, rows []*usageStats) {
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%s\tSESSIONS\tINPUT\tOUTPUT\tCACHE WRITE\tCACHE READ\tCOST\t\n", heading)
+	for _, r := range rows {
+		cost := fmt.Sprintf("$%.2f", r.EstimatedCost)
+		if r.Unpriced == r.Tokens.Total() && r.Unpriced > 0 {
+			cost = "n/a"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t\n", r.Name, r.Sessions,
+			formatCount(r.Tokens.Input), formatCount(r.Tokens.Output),
+			formatCount(r.Tokens.CacheCreation), formatCount(r.Tokens.CacheRead), cost)
+	}
+	_ = w.Flush()
+}
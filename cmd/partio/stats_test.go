@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/pricing"
+)
+
+func TestCollectStats(t *testing.T) {
+	table := pricing.Table{"claude-sonnet-4": {Input: 3, Output: 15}}
+	now := time.Now().UTC()
+	summary := func(author string, age time.Duration, sessions ...checkpoint.SessionMetadata) checkpoint.Summary {
+		return checkpoint.Summary{
+			Metadata: checkpoint.Metadata{Author: author, CreatedAt: now.Add(-age).Format(time.RFC3339)},
+			Sessions: sessions,
+		}
+	}
+	sonnet := checkpoint.SessionMetadata{
+		ModelTokens: map[string]agent.TokenUsage{"claude-sonnet-4-5": {Input: 1_000_000, Output: 100_000}},
+	}
+	legacy := checkpoint.SessionMetadata{TotalTokens: 500}
+
+	summaries := []checkpoint.Summary{
+		summary("alice <a@x>", time.Hour, sonnet),
+		summary("bob <b@x>", 48*time.Hour, sonnet, legacy),
+		summary("alice <a@x>", 60*24*time.Hour, sonnet),
+	}
+
+	tests := []struct {
+		name        string
+		cutoff      time.Time
+		checkpoints int
+		cost        float64
+		unpriced    int
+		authors     int
+	}{
+		{"all", time.Time{}, 3, 13.5, 500, 2},
+		{"since 30d", now.Add(-30 * 24 * time.Hour), 2, 9, 500, 2},
+		{"since 1d", now.Add(-24 * time.Hour), 1, 4.5, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := collectStats(summaries, tt.cutoff, table)
+			if stats.Total.Checkpoints != tt.checkpoints {
+				t.Errorf("checkpoints = %d, want %d", stats.Total.Checkpoints, tt.checkpoints)
+			}
+			if math.Abs(stats.Total.EstimatedCost-tt.cost) > 1e-9 {
+				t.Errorf("cost = %v, want %v", stats.Total.EstimatedCost, tt.cost)
+			}
+			if stats.Total.Unpriced != tt.unpriced {
+				t.Errorf("unpriced = %d, want %d", stats.Total.Unpriced, tt.unpriced)
+			}
+			if len(stats.ByAuthor) != tt.authors {
+				t.Errorf("authors = %d, want %d", len(stats.ByAuthor), tt.authors)
+			}
+			if stats.ByModel[0].Name != "claude-sonnet-4-5" {
+				t.Errorf("top model = %q", stats.ByModel[0].Name)
+			}
+		})
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -4200: "-4,200"}
+	for n, want := range tests {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
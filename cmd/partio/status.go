@@ -48,13 +48,20 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	mgr := session.NewManager(partioDir)
 	if result, err := mgr.CleanupStale(cfg.StaleSessionThreshold.Duration()); err != nil {
 		slog.Debug("stale session cleanup failed", "error", err)
-	} else if result.Cleaned {
-		slog.Info("stale session cleaned up", "id", result.Session.ID)
+	} else {
+		for _, s := range result.Cleaned {
+			slog.Info("stale session cleaned up", "id", s.ID)
+		}
 	}
+	detectIdleSessions(mgr)
 
 	fmt.Println("Status:     enabled")
 	fmt.Printf("Strategy:   %s\n", cfg.Strategy)
-	fmt.Printf("Linking:    %s\n", cfg.CommitLinking)
+	if cfg.IdleCommitLinking != "" {
+		fmt.Printf("Linking:    %s (idle sessions: %s)\n", cfg.CommitLinking, cfg.IdleCommitLinking)
+	} else {
+		fmt.Printf("Linking:    %s\n", cfg.CommitLinking)
+	}
 	fmt.Printf("Agent:      %s\n", cfg.Agent)
 
 	// Check hooks
@@ -79,12 +86,15 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check checkpoint branch
-	_, err = git.ExecGit("rev-parse", "--verify", "partio/checkpoints/v1")
+	_, err = git.ExecGit("rev-parse", "--verify", git.CheckpointBranch)
 	if err == nil {
 		fmt.Println("Checkpoints: branch exists")
 	} else {
 		fmt.Println("Checkpoints: branch missing")
 	}
+	if git.BranchExists(git.LegacyCheckpointBranch) {
+		fmt.Printf("             %s found (run 'partio migrate' to convert it)\n", git.LegacyCheckpointBranch)
+	}
 
 	return nil
 }
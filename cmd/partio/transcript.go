@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/checkpoint"
+)
+
+// checkpointTranscript returns the normalized transcript of a checkpoint's
+// session. Checkpoints captured before transcript.json was recorded get a
+// partial transcript holding only the tool calls from tools.json, if any;
+// nil is returned when neither is available.
+func checkpointTranscript(data *checkpoint.CheckpointData) *agent.Transcript {
+	if data.Transcript != "" {
+		t, err := agent.ParseTranscript([]byte(data.Transcript))
+		if err == nil {
+			return t
+		}
+		slog.Debug("could not read transcript", "checkpoint", data.Metadata.ID, "error", err)
+	}
+
+	var calls []agent.ToolCall
+	if data.Tools == "" || json.Unmarshal([]byte(data.Tools), &calls) != nil {
+		return nil
+	}
+	return &agent.Transcript{Agent: data.Metadata.Agent, SessionID: data.Metadata.SessionID, ToolCalls: calls}
+}
+
+// filesTouched returns the files named by a transcript's tool calls, in the
+// order they were first touched.
+func filesTouched(t *agent.Transcript) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, c := range t.ToolCalls {
+		for _, f := range c.FilesTouched {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+)
+
+func TestCheckpointTranscript(t *testing.T) {
+	transcript := `{"version":1,"agent":"codex","messages":[{"role":"user","content":"Fix it","timestamp":"2025-01-01T00:00:00Z"},{"role":"assistant","content":"Fixed the parser.","timestamp":"2025-01-01T00:00:01Z","tool_call_ids":["c1"]}],"tool_calls":[{"id":"c1","name":"apply_patch","files_touched":["parse.go"],"timestamp":"2025-01-01T00:00:01Z"}]}`
+	tools := `[{"name":"Edit","files_touched":["a.go","b.go"],"timestamp":"2025-01-01T00:00:00Z"},{"name":"Edit","files_touched":["a.go"],"timestamp":"2025-01-01T00:00:01Z"}]`
+
+	tests := []struct {
+		name     string
+		data     checkpoint.CheckpointData
+		messages int
+		files    []string
+	}{
+		{"transcript", checkpoint.CheckpointData{Transcript: transcript, Tools: tools}, 2, []string{"parse.go"}},
+		{"tools only", checkpoint.CheckpointData{Tools: tools}, 0, []string{"a.go", "b.go"}},
+		{"newer version", checkpoint.CheckpointData{Transcript: `{"version":99}`, Tools: tools}, 0, []string{"a.go", "b.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := checkpointTranscript(&tt.data)
+			if tr == nil {
+				t.Fatal("got nil transcript")
+			}
+			if len(tr.Messages) != tt.messages {
+				t.Errorf("messages = %d, want %d", len(tr.Messages), tt.messages)
+			}
+			if got := strings.Join(filesTouched(tr), ","); got != strings.Join(tt.files, ",") {
+				t.Errorf("filesTouched = %s, want %v", got, tt.files)
+			}
+		})
+	}
+
+	if tr := checkpointTranscript(&checkpoint.CheckpointData{}); tr != nil {
+		t.Errorf("empty checkpoint transcript = %+v, want nil", tr)
+	}
+}
+
+func TestTranscriptProgress(t *testing.T) {
+	tr := checkpointTranscript(&checkpoint.CheckpointData{
+		Transcript: `{"version":1,"agent":"claude-code","messages":[{"role":"assistant","content":"Tests pass; docs remain.","timestamp":"2025-01-01T00:00:00Z"},{"role":"assistant","content":"","timestamp":"2025-01-01T00:00:01Z","tool_call_ids":["t1"]}],"tool_calls":[{"id":"t1","name":"Write","files_touched":["README.md"],"timestamp":"2025-01-01T00:00:01Z"}]}`,
+	})
+
+	got := transcriptProgress(tr, "##", maxLastMessage)
+	for _, want := range []string{"## Where It Left Off\n\nTests pass; docs remain.\n", "## Files Touched\n\n- README.md\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("progress is missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := toolSummary(tr); got != "1 call(s) (Write 1), 1 file(s) touched" {
+		t.Errorf("toolSummary = %q", got)
+	}
+	if got := transcriptSummary(tr); got != "2 (0 user, 2 assistant)" {
+		t.Errorf("transcriptSummary = %q", got)
+	}
+}
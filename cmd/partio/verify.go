@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/git"
+)
+
+func newVerifyCmd() *cobra.Command {
+	var requireSignature bool
+
+	cmd := &cobra.Command{
+		Use:   "verify [<checkpoint-id>...]",
+		Short: "Verify checkpoint signatures and integrity",
+		Long: `Checks that each checkpoint's files match its manifest, that the manifest
+hash was recorded by a (signed) checkpoint branch commit, and that the linked
+commit exists and carries the matching Partio-Checkpoint trailer.
+
+With no arguments, every checkpoint is verified. Exits non-zero if any check fails.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVerify(args, requireSignature)
+		},
+	}
+
+	cmd.Flags().BoolVar(&requireSignature, "require-signature", false, "treat unsigned, untrusted or uncheckable signatures as failures")
+
+	return cmd
+}
+
+func runVerify(ids []string, requireSignature bool) error {
+	repoRoot, err := git.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("must be run inside a git repository")
+	}
+
+	for _, id := range ids {
+		if len(id) != 12 {
+			return fmt.Errorf("checkpoint ID must be 12 characters (got %d)", len(id))
+		}
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	results, err := store.Verify(ids, requireSignature)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No checkpoints found.")
+		return nil
+	}
+
+	failed := 0
+	for _, r := range results {
+		fmt.Printf("%s\n", r.ID)
+		for _, c := range r.Checks {
+			fmt.Printf("  %s %-9s %s\n", statusLabel(c.Status), c.Name, c.Detail)
+		}
+		if !r.OK() {
+			failed++
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checkpoint(s) failed verification", failed, len(results))
+	}
+	fmt.Printf("%d checkpoint(s) verified.\n", len(results))
+	return nil
+}
+
+func statusLabel(s checkpoint.CheckStatus) string {
+	switch s {
+	case checkpoint.CheckOK:
+		return "[OK]  "
+	case checkpoint.CheckWarn:
+		return "[WARN]"
+	default:
+		return "[FAIL]"
+	}
+}
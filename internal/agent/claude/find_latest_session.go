@@ -12,6 +12,7 @@ import (
 
 // FindLatestJSONLPath returns the path of the most recently modified JSONL file
 // without parsing its contents. This is cheaper than FindLatestSession.
+// Sub-agent transcripts are skipped; they belong to their parent session.
 func (d *Detector) FindLatestJSONLPath(repoRoot string) (string, error) {
 	sessionDir, err := d.FindSessionDir(repoRoot)
 	if err != nil {
@@ -25,7 +26,7 @@ func (d *Detector) FindLatestJSONLPath(repoRoot string) (string, error) {
 
 	var jsonlFiles []os.DirEntry
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".jsonl") {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".jsonl") && !isSidechainFile(e.Name()) {
 			jsonlFiles = append(jsonlFiles, e)
 		}
 	}
@@ -43,7 +44,8 @@ func (d *Detector) FindLatestJSONLPath(repoRoot string) (string, error) {
 	return filepath.Join(sessionDir, jsonlFiles[0].Name()), nil
 }
 
-// FindLatestSession finds the most recently modified JSONL session file.
+// FindLatestSession finds the most recently modified JSONL session file and
+// parses the logical session it ends (see ParseJSONL).
 func (d *Detector) FindLatestSession(repoRoot string) (string, *agent.SessionData, error) {
 	latestPath, err := d.FindLatestJSONLPath(repoRoot)
 	if err != nil {
@@ -58,3 +60,9 @@ func (d *Detector) FindLatestSession(repoRoot string) (string, *agent.SessionDat
 
 	return latestPath, data, nil
 }
+
+// ReadSession parses the session ending in the transcript at path, such as
+// one reported by Claude Code's hooks.
+func (d *Detector) ReadSession(path string) (*agent.SessionData, error) {
+	return ParseJSONL(path)
+}
@@ -0,0 +1,101 @@
+package claude
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/partio-io/cli/internal/agent"
+)
+
+const (
+	// maxMatchCandidates bounds how many recent sessions are parsed when
+	// matching a commit to a session.
+	maxMatchCandidates = 5
+
+	// matchWindow excludes sessions last modified this long before the
+	// newest one; they cannot have produced the commit being made.
+	matchWindow = 24 * time.Hour
+)
+
+// FindMatchingSession returns the session most likely to have produced a
+// commit of files, rather than simply the newest one, which is wrong when
+// several Claude sessions are open in the same project. Recent sessions in
+// the repo's session directories are ranked by how many of the committed
+// files their edits touched, then by recency and working directory.
+func (d *Detector) FindMatchingSession(repoRoot string, files []string) (string, *agent.SessionData, *agent.SessionMatch, error) {
+	paths, err := recentSessionFiles(repoRoot)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	candidates := make([]agent.SessionCandidate, 0, len(paths))
+	for _, p := range paths {
+		data, err := ParseJSONL(p.path)
+		if err != nil {
+			slog.Debug("skipping unreadable session", "path", p.path, "error", err)
+			continue
+		}
+		candidates = append(candidates, agent.SessionCandidate{Path: p.path, Data: data, ModTime: p.modTime})
+	}
+	if len(candidates) == 0 {
+		return paths[0].path, nil, nil, fmt.Errorf("parsing JSONL: no readable session in %d candidate(s)", len(paths))
+	}
+
+	ranked := agent.RankSessions(candidates, repoRoot, files, time.Now())
+	for _, r := range ranked {
+		slog.Debug("session candidate", "path", r.Path, "score", r.Match.Score, "reason", r.Match.Reason)
+	}
+	best := ranked[0]
+	return best.Path, best.Data, &best.Match, nil
+}
+
+type sessionFile struct {
+	path    string
+	modTime time.Time
+}
+
+// recentSessionFiles returns up to maxMatchCandidates main session files
+// from the repo's session directories, newest first.
+func recentSessionFiles(repoRoot string) ([]sessionFile, error) {
+	dirs, err := sessionDirs(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no Claude session directory found for %s", repoRoot)
+	}
+
+	var files []sessionFile
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") || isSidechainFile(e.Name()) {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			files = append(files, sessionFile{path: filepath.Join(dir, e.Name()), modTime: info.ModTime()})
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no JSONL session files found in %s", strings.Join(dirs, ", "))
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	cutoff := files[0].modTime.Add(-matchWindow)
+	n := 1
+	for n < len(files) && n < maxMatchCandidates && files[n].modTime.After(cutoff) {
+		n++
+	}
+	return files[:n], nil
+}
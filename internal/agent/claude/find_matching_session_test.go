@@ -0,0 +1,63 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindMatchingSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	home := filepath.Join(tmpDir, "home")
+	repoRoot := filepath.Join(tmpDir, "repo")
+	sessionDir := filepath.Join(home, ".claude", "projects", sanitizePath(repoRoot))
+	if err := os.MkdirAll(sessionDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+
+	edit := func(session, file string) string {
+		return `{"type":"assistant","sessionId":"` + session + `","cwd":"` + repoRoot + `","message":{"id":"m1","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"` + filepath.Join(repoRoot, file) + `"}}]},"timestamp":"2025-01-01T00:00:01Z"}`
+	}
+	now := time.Now()
+	sessions := []struct {
+		name, file string
+		age        time.Duration
+	}{
+		{"parser", "parser.go", time.Hour},
+		{"docs", "README.md", time.Minute},
+		{"stale", "parser.go", 48 * time.Hour},
+	}
+	for _, s := range sessions {
+		p := filepath.Join(sessionDir, s.name+".jsonl")
+		writeTranscript(t, p, edit(s.name, s.file))
+		if err := os.Chtimes(p, now.Add(-s.age), now.Add(-s.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := &Detector{}
+	path, data, match, err := d.FindMatchingSession(repoRoot, []string{"parser.go"})
+	if err != nil {
+		t.Fatalf("FindMatchingSession: %v", err)
+	}
+	if filepath.Base(path) != "parser.jsonl" || data.SessionID != "parser" {
+		t.Errorf("got %s (%s), want parser.jsonl", path, data.SessionID)
+	}
+	if match == nil || match.Candidates != 2 {
+		t.Fatalf("match = %+v, want 2 candidates (stale session excluded)", match)
+	}
+	if data.CWD != repoRoot {
+		t.Errorf("CWD = %q, want %q", data.CWD, repoRoot)
+	}
+
+	// Without committed files to compare, the newest session wins.
+	path, _, _, err = d.FindMatchingSession(repoRoot, nil)
+	if err != nil {
+		t.Fatalf("FindMatchingSession: %v", err)
+	}
+	if filepath.Base(path) != "docs.jsonl" {
+		t.Errorf("got %s, want docs.jsonl", path)
+	}
+}
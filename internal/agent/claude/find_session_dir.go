@@ -16,22 +16,9 @@ import (
 // When both the repo root and its parent have matching directories, the one
 // containing the most recently modified JSONL file is returned.
 func (d *Detector) FindSessionDir(repoRoot string) (string, error) {
-	home, err := os.UserHomeDir()
+	candidates, err := sessionDirs(repoRoot)
 	if err != nil {
-		return "", fmt.Errorf("getting home directory: %w", err)
-	}
-
-	projectsDir := filepath.Join(home, ".claude", "projects")
-
-	// Check the repo root and its immediate parent directory.
-	var candidates []string
-	dirs := []string{repoRoot, filepath.Dir(repoRoot)}
-	for _, dir := range dirs {
-		sanitized := sanitizePath(dir)
-		sessionDir := filepath.Join(projectsDir, sanitized)
-		if _, err := os.Stat(sessionDir); err == nil {
-			candidates = append(candidates, sessionDir)
-		}
+		return "", err
 	}
 
 	if len(candidates) == 0 {
@@ -72,3 +59,23 @@ func (d *Detector) FindSessionDir(repoRoot string) (string, error) {
 
 	return bestDir, nil
 }
+
+// sessionDirs returns the existing Claude session directories for the repo
+// root and its immediate parent, in that order.
+func sessionDirs(repoRoot string) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting home directory: %w", err)
+	}
+
+	projectsDir := filepath.Join(home, ".claude", "projects")
+
+	var dirs []string
+	for _, dir := range []string{repoRoot, filepath.Dir(repoRoot)} {
+		sessionDir := filepath.Join(projectsDir, sanitizePath(dir))
+		if _, err := os.Stat(sessionDir); err == nil {
+			dirs = append(dirs, sessionDir)
+		}
+	}
+	return dirs, nil
+}
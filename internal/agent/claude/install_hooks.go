@@ -0,0 +1,146 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/partio-io/cli/internal/agent"
+)
+
+// hookEvents maps the Claude Code hook events partio listens to onto the
+// session events it records.
+var hookEvents = []struct{ hook, event string }{
+	{"SessionStart", "start"},
+	{"UserPromptSubmit", "prompt"},
+	{"PostToolUse", "tool"},
+	{"Stop", "stop"},
+	{"SessionEnd", "end"},
+}
+
+// hookGroup is an entry of a hook event in Claude Code's settings.json.
+type hookGroup struct {
+	Matcher string        `json:"matcher,omitempty"`
+	Hooks   []hookCommand `json:"hooks"`
+}
+
+type hookCommand struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+}
+
+// InstallHooks registers partio's session event hooks in the user's
+// ~/.claude/settings.json. Claude Code passes each hook the session ID and
+// transcript path on stdin. Other settings and hooks are preserved.
+func (d *Detector) InstallHooks(bin string) (bool, error) {
+	return editHooks(bin)
+}
+
+// UninstallHooks removes partio's hooks from ~/.claude/settings.json.
+func (d *Detector) UninstallHooks() (bool, error) {
+	return editHooks("")
+}
+
+// editHooks replaces partio's hooks in the user's settings with hooks
+// running bin, or removes them when bin is empty.
+func editHooks(bin string) (bool, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false, fmt.Errorf("getting home directory: %w", err)
+	}
+	path := filepath.Join(home, ".claude", "settings.json")
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+	case !os.IsNotExist(err):
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	case bin == "":
+		return false, nil
+	default:
+		data = []byte("{}\n")
+	}
+	settings := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return false, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	hooks := make(map[string][]json.RawMessage)
+	if raw, ok := settings["hooks"]; ok {
+		if err := json.Unmarshal(raw, &hooks); err != nil {
+			return false, fmt.Errorf("parsing hooks in %s: %w", path, err)
+		}
+	}
+	before, _ := marshalHooks(hooks)
+
+	for _, h := range hookEvents {
+		groups := withoutPartioHooks(hooks[h.hook])
+		if bin != "" {
+			group, err := json.Marshal(hookGroup{Hooks: []hookCommand{{
+				Type:    "command",
+				Command: agent.EventCommand(bin, "claude-code", h.event),
+			}}})
+			if err != nil {
+				return false, err
+			}
+			groups = append(groups, group)
+		}
+		if len(groups) == 0 {
+			delete(hooks, h.hook)
+		} else {
+			hooks[h.hook] = groups
+		}
+	}
+
+	after, err := marshalHooks(hooks)
+	if err != nil {
+		return false, fmt.Errorf("marshaling hooks: %w", err)
+	}
+	if string(after) == string(before) {
+		return false, nil
+	}
+	// Only the hooks member is rewritten; the rest of the user's file is
+	// kept byte for byte.
+	var value json.RawMessage
+	if len(hooks) > 0 {
+		value = after
+	}
+	out, err := setJSONMember(data, "hooks", value)
+	if err != nil {
+		return false, fmt.Errorf("editing %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := agent.WriteConfigFile(path, out); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// marshalHooks encodes the hooks setting, leaving the <, > and & of the
+// user's hook commands unescaped.
+func marshalHooks(hooks map[string][]json.RawMessage) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(hooks); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// withoutPartioHooks drops the hook groups partio installed.
+func withoutPartioHooks(groups []json.RawMessage) []json.RawMessage {
+	var kept []json.RawMessage
+	for _, raw := range groups {
+		var g hookGroup
+		if json.Unmarshal(raw, &g) == nil && len(g.Hooks) == 1 && agent.IsEventCommand(g.Hooks[0].Command) {
+			continue
+		}
+		kept = append(kept, raw)
+	}
+	return kept
+}
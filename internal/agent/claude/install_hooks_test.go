@@ -0,0 +1,94 @@
+package claude
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallHooks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	existing := `{"model":"opus","hooks":{"Stop":[{"hooks":[{"type":"command","command":"notify-send done"}]}]}}`
+	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := New()
+	if changed, err := d.InstallHooks("partio"); err != nil || !changed {
+		t.Fatalf("InstallHooks = %v, %v; want changed", changed, err)
+	}
+	if changed, err := d.InstallHooks("partio"); err != nil || changed {
+		t.Fatalf("second InstallHooks = %v, %v; want unchanged", changed, err)
+	}
+
+	var settings struct {
+		Model string                 `json:"model"`
+		Hooks map[string][]hookGroup `json:"hooks"`
+	}
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("parsing settings: %v", err)
+	}
+	if settings.Model != "opus" {
+		t.Errorf("model = %q, other settings were not preserved", settings.Model)
+	}
+	for _, h := range hookEvents {
+		groups := settings.Hooks[h.hook]
+		if len(groups) == 0 || groups[len(groups)-1].Hooks[0].Command != "partio _agent-event "+h.event+" --agent claude-code" {
+			t.Errorf("%s hooks = %+v", h.hook, groups)
+		}
+	}
+	if stop := settings.Hooks["Stop"]; len(stop) != 2 || stop[0].Hooks[0].Command != "notify-send done" {
+		t.Errorf("Stop hooks = %+v, want the user's hook kept", stop)
+	}
+
+	if changed, err := d.UninstallHooks(); err != nil || !changed {
+		t.Fatalf("UninstallHooks = %v, %v; want changed", changed, err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "_agent-event") || !strings.Contains(string(data), "notify-send done") {
+		t.Errorf("settings after uninstall = %s", data)
+	}
+}
+
+func TestInstallHooksPreservesSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	existing := "{\n  \"model\": \"opus\",\n  \"env\": { \"A\": \"1 && 2\" },\n  \"alwaysThinkingEnabled\": true\n}\n"
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := New()
+	if _, err := d.InstallHooks("/opt/my tools/partio"); err != nil {
+		t.Fatalf("InstallHooks: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), strings.TrimSuffix(existing, "\n}\n")+",\n  \"hooks\": {") {
+		t.Errorf("settings were reformatted:\n%s", data)
+	}
+	if !strings.Contains(string(data), `"command": "'/opt/my tools/partio' _agent-event stop --agent claude-code"`) {
+		t.Errorf("hook command does not quote the binary path:\n%s", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("settings mode = %v, %v; want 0600 kept", info.Mode().Perm(), err)
+	}
+
+	if _, err := d.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != existing {
+		t.Errorf("settings after uninstall =\n%s\nwant the original:\n%s", data, existing)
+	}
+}
@@ -47,18 +47,95 @@ type jsonlEntry struct {
 	Timestamp flexTimestamp   `json:"timestamp"`
 	SessionID string          `json:"sessionId,omitempty"`
 	Slug      string          `json:"slug,omitempty"`
+	CWD       string          `json:"cwd,omitempty"`
+
+	// Entries form a tree: each names its parent, so a conversation is
+	// read by walking back from its last entry. A compaction boundary has
+	// no parent but names the entry it logically follows.
+	UUID              string `json:"uuid,omitempty"`
+	ParentUUID        string `json:"parentUuid,omitempty"`
+	LogicalParentUUID string `json:"logicalParentUuid,omitempty"`
+
+	// IsSidechain marks entries of a sub-agent conversation (Task tool),
+	// identified by AgentID in newer transcripts.
+	IsSidechain bool   `json:"isSidechain,omitempty"`
+	AgentID     string `json:"agentId,omitempty"`
+
+	// IsCompactSummary marks the summary that replaces the conversation
+	// before a compaction boundary.
+	IsCompactSummary bool `json:"isCompactSummary,omitempty"`
+
+	// ToolUseResult carries structured tool results; for the Task tool it
+	// names the sub-agent that ran.
+	ToolUseResult json.RawMessage `json:"toolUseResult,omitempty"`
 
 	// For content blocks
 	ContentBlocks []contentBlock `json:"contentBlocks,omitempty"`
 }
 
+// parent returns the UUID of the entry e follows.
+func (e jsonlEntry) parent() string {
+	if e.ParentUUID != "" {
+		return e.ParentUUID
+	}
+	return e.LogicalParentUUID
+}
+
 type contentBlock struct {
 	Type string `json:"type"`
 	Text string `json:"text,omitempty"`
+
+	// thinking blocks
+	Thinking string `json:"thinking,omitempty"`
+
+	// image and document blocks
+	Source *blockSource `json:"source,omitempty"`
+	Title  string       `json:"title,omitempty"`
+
+	// tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result blocks; Content is a string or a list of text blocks.
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
 }
 
-// messageContent extracts text content from a JSONL entry.
+// blockSource is the content of an image or document block: inline base64
+// data or a URL.
+type blockSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// messageContent extracts text content from a JSONL entry. Content is a
+// list of blocks, or a plain string for typed user prompts.
 type messageContent struct {
-	Role    string         `json:"role"`
-	Content []contentBlock `json:"content"`
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
 }
+
+// assistantMessage holds the fields of an assistant message that identify it
+// and report its token usage. Claude Code writes one line per content block,
+// each repeating the message ID and usage.
+type assistantMessage struct {
+	ID    string        `json:"id"`
+	Model string        `json:"model"`
+	Usage *messageUsage `json:"usage"`
+}
+
+// messageUsage is the Anthropic API usage object.
+type messageUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// syntheticModel marks assistant messages Claude Code generates locally
+// (e.g. for interrupted requests); they consume no tokens.
+const syntheticModel = "<synthetic>"
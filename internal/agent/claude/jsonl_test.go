@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/agent"
 )
 
 func TestParseJSONL(t *testing.T) {
@@ -179,3 +182,122 @@ func TestSanitizePath(t *testing.T) {
 		}
 	}
 }
+
+func TestParseJSONLTokenUsage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "usage.jsonl")
+
+	// msg_1 is split over two lines (text, then tool use) that repeat its
+	// usage; it must be counted once, with the final output count.
+	lines := `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"Fix the bug"}]},"timestamp":"2025-01-01T00:00:00Z","sessionId":"s1"}
+{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"Looking."}],"usage":{"input_tokens":10,"output_tokens":1,"cache_creation_input_tokens":100,"cache_read_input_tokens":1000}},"timestamp":"2025-01-01T00:00:01Z"}
+{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"tool_use","name":"Edit"}],"usage":{"input_tokens":10,"output_tokens":50,"cache_creation_input_tokens":100,"cache_read_input_tokens":1000}},"timestamp":"2025-01-01T00:00:02Z"}
+{"type":"assistant","message":{"id":"msg_2","model":"claude-haiku-4-5","role":"assistant","content":[{"type":"text","text":"Done."}],"usage":{"input_tokens":5,"output_tokens":7,"cache_creation_input_tokens":0,"cache_read_input_tokens":20}},"timestamp":"2025-01-01T00:00:03Z"}
+{"type":"assistant","message":{"id":"msg_3","model":"<synthetic>","role":"assistant","content":[{"type":"text","text":"No response requested."}],"usage":{"input_tokens":0,"output_tokens":0}},"timestamp":"2025-01-01T00:00:04Z"}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+
+	data, err := ParseJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	want := agent.TokenUsage{Input: 15, Output: 57, CacheCreation: 100, CacheRead: 1020}
+	if data.Tokens != want {
+		t.Errorf("Tokens = %+v, want %+v", data.Tokens, want)
+	}
+	if data.TotalTokens != want.Total() {
+		t.Errorf("TotalTokens = %d, want %d", data.TotalTokens, want.Total())
+	}
+	if got := data.Models(); len(got) != 2 || got[0] != "claude-haiku-4-5" || got[1] != "claude-sonnet-4-5" {
+		t.Errorf("Models() = %v", got)
+	}
+	if got := data.ModelTokens["claude-sonnet-4-5"]; got.Output != 50 || got.CacheRead != 1000 {
+		t.Errorf("sonnet tokens = %+v", got)
+	}
+	if m := data.Transcript[1]; m.Model != "claude-sonnet-4-5" || m.Tokens != 1111 {
+		t.Errorf("first assistant message = %+v", m)
+	}
+}
+
+func TestParseJSONLToolCalls(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tools.jsonl")
+
+	lines := `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"Fix the test"}]},"timestamp":"2025-01-01T00:00:00Z"}
+{"type":"assistant","message":{"id":"msg_1","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Edit","input":{"file_path":"/repo/main.go","old_string":"a","new_string":"b"}}]},"timestamp":"2025-01-01T00:00:01Z"}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"The file /repo/main.go has been updated."}]},"timestamp":"2025-01-01T00:00:02Z"}
+{"type":"assistant","message":{"id":"msg_2","role":"assistant","content":[{"type":"tool_use","id":"toolu_2","name":"Bash","input":{"command":"go test ./..."}}]},"timestamp":"2025-01-01T00:00:03Z"}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_2","is_error":true,"content":[{"type":"text","text":"Error: Exit code 1\nFAIL"}]}]},"timestamp":"2025-01-01T00:00:04Z"}
+{"type":"assistant","message":{"id":"msg_3","role":"assistant","content":[{"type":"tool_use","id":"toolu_3","name":"Bash","input":{"command":"go test ./..."}}]},"timestamp":"2025-01-01T00:00:05Z"}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_3","content":"ok"}]},"timestamp":"2025-01-01T00:00:06Z"}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+
+	data, err := ParseJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	calls := data.ToolCalls
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 tool calls, got %d: %+v", len(calls), calls)
+	}
+
+	if c := calls[0]; c.Name != "Edit" || len(c.FilesTouched) != 1 || c.FilesTouched[0] != "/repo/main.go" || c.ExitCode != nil {
+		t.Errorf("edit call = %+v", c)
+	}
+	if c := calls[1]; !c.IsError || c.ExitCode == nil || *c.ExitCode != 1 || c.ResultSummary != "Error: Exit code 1\nFAIL" {
+		t.Errorf("failing bash call = %+v", c)
+	}
+	if c := calls[2]; c.IsError || c.ExitCode == nil || *c.ExitCode != 0 || c.ResultSummary != "ok" {
+		t.Errorf("passing bash call = %+v", c)
+	}
+	if string(calls[1].Input) != `{"command":"go test ./..."}` {
+		t.Errorf("bash input = %s", calls[1].Input)
+	}
+}
+
+func TestParseJSONLTranscript(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.jsonl")
+
+	// A typed prompt (string content) with a pasted image, then one
+	// assistant message split over thinking, text and tool_use lines.
+	lines := `{"type":"user","message":{"role":"user","content":"Why does this fail?"},"timestamp":"2025-01-01T00:00:00Z","sessionId":"s1"}
+{"type":"user","message":{"role":"user","content":[{"type":"image","source":{"type":"base64","media_type":"image/png","data":"aGVsbG8="}}]},"timestamp":"2025-01-01T00:00:01Z"}
+{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"thinking","thinking":"The import is missing."}]},"timestamp":"2025-01-01T00:00:02Z"}
+{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"The import is missing."}]},"timestamp":"2025-01-01T00:00:03Z"}
+{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Edit","input":{"file_path":"/repo/main.go"}}]},"timestamp":"2025-01-01T00:00:04Z"}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"ok"}]},"timestamp":"2025-01-01T00:00:05Z"}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+
+	data, err := ParseJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	if data.Prompt != "Why does this fail?" {
+		t.Errorf("Prompt = %q", data.Prompt)
+	}
+	if len(data.Transcript) != 3 {
+		t.Fatalf("expected 3 messages, got %d: %+v", len(data.Transcript), data.Transcript)
+	}
+	if a := data.Transcript[1].Attachments; len(a) != 1 || a[0].Type != "image" || a[0].MediaType != "image/png" || a[0].Size != 6 {
+		t.Errorf("attachments = %+v", a)
+	}
+	m := data.Transcript[2]
+	if m.Content != "The import is missing." || len(m.Thinking) != 1 || len(m.ToolCallIDs) != 1 || m.ToolCallIDs[0] != "toolu_1" {
+		t.Errorf("assistant message = %+v", m)
+	}
+	if m.Model != "claude-sonnet-4-5" || !m.Timestamp.Equal(data.StartedAt.Add(2*time.Second)) {
+		t.Errorf("assistant model = %q, timestamp = %v", m.Model, m.Timestamp)
+	}
+}
@@ -0,0 +1,35 @@
+package claude
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Executable returns the name of the Claude Code CLI.
+func (d *Detector) Executable() string {
+	return "claude"
+}
+
+// ResumeArgs returns `--resume <sessionID>` if the session's transcript is
+// still in the session directory Claude Code uses when launched from
+// repoRoot.
+func (d *Detector) ResumeArgs(repoRoot, sessionID string) ([]string, bool) {
+	if sessionID == "" || filepath.Base(sessionID) != sessionID {
+		return nil, false
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, false
+	}
+	path := filepath.Join(home, ".claude", "projects", sanitizePath(repoRoot), sessionID+".jsonl")
+	if _, err := os.Stat(path); err != nil {
+		return nil, false
+	}
+	return []string{"--resume", sessionID}, true
+}
+
+// PromptArgs returns an initial prompt pointing Claude Code at promptFile.
+func (d *Detector) PromptArgs(promptFile string) []string {
+	return []string{fmt.Sprintf("Read %s for full context on a previous session, then continue that work.", promptFile)}
+}
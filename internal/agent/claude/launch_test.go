@@ -0,0 +1,40 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResumeArgs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".claude", "projects", "-work-repo")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "s1.jsonl"), []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		repoRoot  string
+		sessionID string
+		want      []string
+	}{
+		{"session present", "/work/repo", "s1", []string{"--resume", "s1"}},
+		{"session missing", "/work/repo", "s2", nil},
+		{"other project", "/work/other", "s1", nil},
+		{"no session ID", "/work/repo", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := New().ResumeArgs(tt.repoRoot, tt.sessionID)
+			if ok != (tt.want != nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("ResumeArgs = %v, %v; want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -1,56 +1,49 @@
 package claude
 
 import (
-	"bufio"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"os"
+	"slices"
 	"time"
 
 	"github.com/partio-io/cli/internal/agent"
 )
 
 // ParseJSONL reads a Claude Code JSONL transcript and extracts session data.
+// The whole logical session is read: transcripts the session was resumed
+// from, conversation before compactions, and sub-agent conversations, which
+// are returned as threads.
 func ParseJSONL(path string) (*agent.SessionData, error) {
-	f, err := os.Open(path)
+	log, err := loadSession(path)
 	if err != nil {
-		return nil, fmt.Errorf("opening JSONL file: %w", err)
+		return nil, err
 	}
-	defer func() { _ = f.Close() }()
 
 	var (
-		messages    []agent.Message
-		prompt      string
-		sessionID   string
-		slug        string
-		totalTokens int
-		firstTS     time.Time
-		lastTS      time.Time
-	)
-
-	scanner := bufio.NewScanner(f)
-	// Allow for large lines (Claude transcripts can be big)
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 10*1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
+		sessionID string
+		slug      string
+		cwd       string
+		firstTS   time.Time
+		lastTS    time.Time
 
-		var entry jsonlEntry
-		if err := json.Unmarshal(line, &entry); err != nil {
-			continue // Skip malformed lines
-		}
+		usage = newUsageTracker()
+		main  = newThreadParser()
+		// agentCalls maps sub-agent IDs to the Task call that ran them.
+		agentCalls = make(map[string]string)
+	)
 
-		if entry.SessionID != "" && sessionID == "" {
+	for _, entry := range log.main {
+		// A resumed session takes the ID and slug of its newest transcript.
+		if entry.SessionID != "" {
 			sessionID = entry.SessionID
 		}
-
-		if entry.Slug != "" && slug == "" {
+		if entry.Slug != "" {
 			slug = entry.Slug
 		}
+		if entry.CWD != "" {
+			cwd = entry.CWD
+		}
 
 		ts := entry.Timestamp.Time
 		if !ts.IsZero() {
@@ -60,103 +53,300 @@ func ParseJSONL(path string) (*agent.SessionData, error) {
 			lastTS = ts
 		}
 
-		// Extract message content
-		text := extractText(entry)
-		if text == "" {
-			continue
+		blocks := entryBlocks(entry)
+		if id := subAgentID(entry); id != "" {
+			for _, b := range blocks {
+				if b.Type == "tool_result" {
+					agentCalls[id] = b.ToolUseID
+				}
+			}
 		}
+		main.add(entry, blocks, usage.add(entry))
+	}
 
-		role := entry.Role
-		if role == "" {
-			role = entry.Type
-		}
+	data := &agent.SessionData{
+		SessionID:  sessionID,
+		Agent:      "claude-code",
+		Prompt:     main.prompt,
+		Transcript: main.messages,
+		Context:    generateContext(main.messages),
+		Duration:   lastTS.Sub(firstTS),
+		PlanSlug:   slug,
+		CWD:        cwd,
+		StartedAt:  firstTS,
+		ToolCalls:  main.tools.calls,
+	}
+	if len(log.files) > 1 {
+		data.SourceFiles = log.files
+	}
 
-		msg := agent.Message{
-			Role:      role,
-			Content:   text,
-			Timestamp: ts,
+	for _, sc := range log.sidechains {
+		p := newThreadParser()
+		for _, entry := range sc.entries {
+			p.add(entry, entryBlocks(entry), usage.add(entry))
 		}
-		messages = append(messages, msg)
+		data.Threads = append(data.Threads, agent.Thread{
+			ID:         sc.id,
+			ToolCallID: threadCall(sc.id, p.prompt, agentCalls, data.ToolCalls),
+			Messages:   p.messages,
+			ToolCalls:  p.tools.calls,
+		})
+	}
 
-		// First human message is the prompt
-		if prompt == "" && (role == "human" || role == "user") {
-			prompt = text
-		}
+	usage.apply(data)
+	return data, nil
+}
+
+// threadParser turns the entries of one conversation into messages and
+// tool calls.
+type threadParser struct {
+	messages []agent.Message
+	tools    *toolCollector
+	// prompt is the text of the first user message.
+	prompt string
+	// lastID is the message ID of the last message.
+	lastID string
+}
+
+func newThreadParser() *threadParser {
+	return &threadParser{tools: newToolCollector()}
+}
+
+// add records an entry, given its content blocks and usage.
+func (p *threadParser) add(entry jsonlEntry, blocks []contentBlock, am assistantMessage) {
+	ts := entry.Timestamp.Time
+	p.tools.add(blocks, ts)
+
+	msg := blockMessage(blocks)
+	if msg.Content == "" && len(msg.Thinking) == 0 && len(msg.ToolCallIDs) == 0 && len(msg.Attachments) == 0 {
+		return
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scanning JSONL: %w", err)
+	msg.Role = entry.Role
+	if msg.Role == "" {
+		msg.Role = entry.Type
+	}
+	if entry.IsCompactSummary {
+		// Written as a user message, but generated by Claude Code.
+		msg.Role = "system"
+	}
+	msg.Timestamp = ts
+	if am.Model != syntheticModel {
+		msg.Model = am.Model
+	}
+	if am.Usage != nil && am.Model != syntheticModel {
+		msg.Tokens = am.Usage.tokens().Total()
 	}
 
-	duration := lastTS.Sub(firstTS)
+	// Claude Code writes each content block of a message on its own
+	// line; merge them back into one message.
+	if n := len(p.messages); n > 0 && am.ID != "" && am.ID == p.lastID {
+		mergeMessage(&p.messages[n-1], msg)
+	} else {
+		p.messages = append(p.messages, msg)
+	}
+	p.lastID = am.ID
 
-	return &agent.SessionData{
-		SessionID:   sessionID,
-		Agent:       "claude-code",
-		Prompt:      prompt,
-		Transcript:  messages,
-		Context:     generateContext(messages),
-		TotalTokens: totalTokens,
-		Duration:    duration,
-		PlanSlug:    slug,
-	}, nil
+	// First human message is the prompt
+	if p.prompt == "" && (msg.Role == "human" || msg.Role == "user") && msg.Content != "" {
+		p.prompt = msg.Content
+	}
 }
 
-// extractText pulls text content from various JSONL entry formats.
-func extractText(entry jsonlEntry) string {
-	// Try content blocks first
-	if len(entry.ContentBlocks) > 0 {
-		var text string
-		for _, b := range entry.ContentBlocks {
-			if b.Type == "text" {
-				text += b.Text
-			}
+// usageTracker totals token usage by message ID, so repeated lines of the
+// same message are counted once; the last line wins, as output tokens grow
+// while a message streams.
+type usageTracker struct {
+	usage map[string]assistantMessage
+	order []string
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{usage: make(map[string]assistantMessage)}
+}
+
+// add records the usage reported by entry, if any, and returns the entry's
+// assistant message fields.
+func (t *usageTracker) add(entry jsonlEntry) assistantMessage {
+	am, hasUsage := parseUsage(entry)
+	if !hasUsage {
+		return am
+	}
+	key := am.ID
+	if key == "" {
+		key = fmt.Sprintf("line-%d", len(t.order))
+	}
+	if _, seen := t.usage[key]; !seen {
+		t.order = append(t.order, key)
+	}
+	t.usage[key] = am
+	return am
+}
+
+// apply adds the recorded usage to data.
+func (t *usageTracker) apply(data *agent.SessionData) {
+	for _, key := range t.order {
+		am := t.usage[key]
+		data.AddUsage(am.Model, am.Usage.tokens())
+	}
+}
+
+// subAgentID returns the ID of the sub-agent whose result entry reports,
+// if any.
+func subAgentID(entry jsonlEntry) string {
+	var result struct {
+		AgentID string `json:"agentId"`
+	}
+	if entry.ToolUseResult == nil || json.Unmarshal(entry.ToolUseResult, &result) != nil {
+		return ""
+	}
+	return result.AgentID
+}
+
+// threadCall returns the ID of the main-transcript call that started a
+// sub-agent: the one whose result names the agent, or else the one whose
+// prompt input matches the sub-agent's first message.
+func threadCall(agentID, prompt string, agentCalls map[string]string, calls []agent.ToolCall) string {
+	if id, ok := agentCalls[agentID]; ok {
+		return id
+	}
+	if prompt == "" {
+		return ""
+	}
+	for _, c := range calls {
+		var input struct {
+			Prompt string `json:"prompt"`
+		}
+		if json.Unmarshal(c.Input, &input) == nil && input.Prompt == prompt {
+			return c.ID
 		}
-		return text
+	}
+	return ""
+}
+
+// parseUsage returns the model and token usage of an assistant entry, if it
+// reports any.
+func parseUsage(entry jsonlEntry) (assistantMessage, bool) {
+	var am assistantMessage
+	if entry.Type != "assistant" || entry.Message == nil {
+		return am, false
+	}
+	if json.Unmarshal(entry.Message, &am) != nil || am.Usage == nil || am.Model == syntheticModel {
+		return am, false
+	}
+	return am, true
+}
+
+// tokens converts API usage to the agent's token breakdown.
+func (u *messageUsage) tokens() agent.TokenUsage {
+	return agent.TokenUsage{
+		Input:         u.InputTokens,
+		Output:        u.OutputTokens,
+		CacheCreation: u.CacheCreationInputTokens,
+		CacheRead:     u.CacheReadInputTokens,
+	}
+}
+
+// entryBlocks returns the content blocks of an entry, wherever the entry
+// format puts them. Plain string content is returned as one text block.
+func entryBlocks(entry jsonlEntry) []contentBlock {
+	if len(entry.ContentBlocks) > 0 {
+		return entry.ContentBlocks
 	}
 
-	// Try message.content
 	if entry.Message != nil {
 		var mc messageContent
-		if json.Unmarshal(entry.Message, &mc) == nil && len(mc.Content) > 0 {
-			var text string
-			for _, b := range mc.Content {
-				if b.Type == "text" {
-					text += b.Text
-				}
+		if json.Unmarshal(entry.Message, &mc) == nil && mc.Content != nil {
+			if blocks := parseBlocks(mc.Content); len(blocks) > 0 {
+				return blocks
 			}
-			return text
 		}
 
 		// Try as plain string
 		var s string
-		if json.Unmarshal(entry.Message, &s) == nil {
-			return s
+		if json.Unmarshal(entry.Message, &s) == nil && s != "" {
+			return []contentBlock{{Type: "text", Text: s}}
 		}
 	}
 
-	// Try direct content
 	if entry.Content != nil {
-		// Try as array of blocks
-		var blocks []contentBlock
-		if json.Unmarshal(entry.Content, &blocks) == nil && len(blocks) > 0 {
-			var text string
-			for _, b := range blocks {
-				if b.Type == "text" {
-					text += b.Text
-				}
+		return parseBlocks(entry.Content)
+	}
+	return nil
+}
+
+// parseBlocks decodes content that is either a list of blocks or a plain
+// string.
+func parseBlocks(raw json.RawMessage) []contentBlock {
+	var blocks []contentBlock
+	if json.Unmarshal(raw, &blocks) == nil {
+		return blocks
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil && s != "" {
+		return []contentBlock{{Type: "text", Text: s}}
+	}
+	return nil
+}
+
+// blockMessage builds the content of a transcript message from blocks:
+// text, thinking, the IDs of tool calls and attachments. Tool results are
+// recorded on their calls instead.
+func blockMessage(blocks []contentBlock) agent.Message {
+	var msg agent.Message
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			msg.Content += b.Text
+		case "thinking":
+			if b.Thinking != "" {
+				msg.Thinking = append(msg.Thinking, b.Thinking)
+			}
+		case "tool_use":
+			if b.ID != "" {
+				msg.ToolCallIDs = append(msg.ToolCallIDs, b.ID)
 			}
-			return text
+		case "image", "document":
+			msg.Attachments = append(msg.Attachments, blockAttachment(b))
 		}
+	}
+	return msg
+}
 
-		// Try as plain string
-		var s string
-		if json.Unmarshal(entry.Content, &s) == nil {
-			return s
+// blockAttachment describes an image or document block without its data.
+func blockAttachment(b contentBlock) agent.Attachment {
+	a := agent.Attachment{Type: b.Type, Name: b.Title}
+	if src := b.Source; src != nil {
+		a.MediaType = src.MediaType
+		if a.Name == "" {
+			a.Name = src.URL
+		}
+		if src.Type == "base64" {
+			a.Size = base64.StdEncoding.DecodedLen(len(src.Data))
 		}
 	}
+	return a
+}
 
-	return ""
+// mergeMessage adds the blocks of a later line of the same message to m.
+func mergeMessage(m *agent.Message, next agent.Message) {
+	if m.Content != "" && next.Content != "" {
+		m.Content += "\n\n"
+	}
+	m.Content += next.Content
+	m.Thinking = append(m.Thinking, next.Thinking...)
+	for _, id := range next.ToolCallIDs {
+		if !slices.Contains(m.ToolCallIDs, id) {
+			m.ToolCallIDs = append(m.ToolCallIDs, id)
+		}
+	}
+	m.Attachments = append(m.Attachments, next.Attachments...)
+	if next.Model != "" {
+		m.Model = next.Model
+	}
+	if next.Tokens > 0 {
+		m.Tokens = next.Tokens
+	}
 }
 
 // generateContext creates a human-readable summary of the session.
@@ -167,7 +357,7 @@ func generateContext(messages []agent.Message) string {
 
 	summary := "AI coding session"
 	for _, m := range messages {
-		if m.Role == "human" || m.Role == "user" {
+		if (m.Role == "human" || m.Role == "user") && m.Content != "" {
 			if len(m.Content) > 200 {
 				summary = m.Content[:200] + "..."
 			} else {
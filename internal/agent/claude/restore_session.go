@@ -0,0 +1,103 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// RestoreSession writes transcript to <sessionID>.jsonl in the session
+// directory of repoRoot, ~/.claude/projects/<sanitized-path>/, where
+// `claude --resume <sessionID>` looks for it when launched from repoRoot.
+func (d *Detector) RestoreSession(repoRoot, sessionID string, transcript []byte) (string, bool, error) {
+	path, err := restorePath(repoRoot, sessionID)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+	if err := writeSession(path, transcript); err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
+
+// ForkSession writes transcript like RestoreSession. If sessionID is
+// already present, the copy is written under a new random session ID with
+// the sessionId of every entry rewritten to it, as Claude Code expects of
+// the entries in a session file.
+func (d *Detector) ForkSession(repoRoot, sessionID string, transcript []byte) (string, string, error) {
+	path, err := restorePath(repoRoot, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return sessionID, path, writeSession(path, transcript)
+	}
+
+	newID := uuid.NewString()
+	remapped, err := remapSessionID(transcript, newID)
+	if err != nil {
+		return "", "", err
+	}
+	path = filepath.Join(filepath.Dir(path), newID+".jsonl")
+	return newID, path, writeSession(path, remapped)
+}
+
+// restorePath returns the file session sessionID of repoRoot is kept in.
+func restorePath(repoRoot, sessionID string) (string, error) {
+	if sessionID == "" || filepath.Base(sessionID) != sessionID {
+		return "", fmt.Errorf("invalid session ID %q", sessionID)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude", "projects", sanitizePath(repoRoot), sessionID+".jsonl"), nil
+}
+
+// writeSession writes a session transcript, creating its directory.
+func writeSession(path string, transcript []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+	if err := os.WriteFile(path, transcript, 0o600); err != nil {
+		return fmt.Errorf("writing session transcript: %w", err)
+	}
+	return nil
+}
+
+// remapSessionID sets the sessionId of every JSONL entry that has one to id.
+// Lines that are not JSON objects are kept as they are.
+func remapSessionID(transcript []byte, id string) ([]byte, error) {
+	newID, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	for line := range bytes.Lines(transcript) {
+		var entry map[string]json.RawMessage
+		trimmed := bytes.TrimSpace(line)
+		if json.Unmarshal(trimmed, &entry) != nil {
+			out.Write(line)
+			continue
+		}
+		if _, ok := entry["sessionId"]; !ok {
+			out.Write(line)
+			continue
+		}
+		entry["sessionId"] = newID
+		if err := enc.Encode(entry); err != nil {
+			return nil, fmt.Errorf("remapping session ID: %w", err)
+		}
+	}
+	return out.Bytes(), nil
+}
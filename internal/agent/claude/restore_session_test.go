@@ -0,0 +1,80 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	d := New()
+	want := filepath.Join(home, ".claude", "projects", "-work-repo", "s1.jsonl")
+
+	path, restored, err := d.RestoreSession("/work/repo", "s1", []byte("first\n"))
+	if err != nil {
+		t.Fatalf("RestoreSession: %v", err)
+	}
+	if path != want || !restored {
+		t.Errorf("RestoreSession = %q, %v; want %q, true", path, restored, want)
+	}
+
+	// An existing session is never overwritten.
+	path, restored, err = d.RestoreSession("/work/repo", "s1", []byte("second\n"))
+	if err != nil {
+		t.Fatalf("RestoreSession again: %v", err)
+	}
+	if path != want || restored {
+		t.Errorf("RestoreSession again = %q, %v; want %q, false", path, restored, want)
+	}
+	if data, _ := os.ReadFile(want); string(data) != "first\n" {
+		t.Errorf("transcript = %q, want the first one kept", data)
+	}
+
+	for _, id := range []string{"", "../s1", "a/b"} {
+		if _, _, err := d.RestoreSession("/work/repo", id, nil); err == nil {
+			t.Errorf("RestoreSession(%q) succeeded, want error", id)
+		}
+	}
+}
+
+func TestForkSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	d := New()
+	dir := filepath.Join(home, ".claude", "projects", "-work-repo")
+	transcript := []byte(`{"type":"user","sessionId":"s1","message":{"content":"a <b>"}}` + "\n" +
+		`{"type":"summary","summary":"no session"}` + "\n" +
+		"not json\n")
+
+	id, path, err := d.ForkSession("/work/repo", "s1", transcript)
+	if err != nil {
+		t.Fatalf("ForkSession: %v", err)
+	}
+	if id != "s1" || path != filepath.Join(dir, "s1.jsonl") {
+		t.Errorf("ForkSession = %q, %q; want the original session", id, path)
+	}
+
+	// With the original present, the copy gets a new ID.
+	id, path, err = d.ForkSession("/work/repo", "s1", transcript)
+	if err != nil {
+		t.Fatalf("ForkSession again: %v", err)
+	}
+	if id == "s1" || path != filepath.Join(dir, id+".jsonl") {
+		t.Fatalf("ForkSession again = %q, %q; want a new session", id, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"message":{"content":"a <b>"},"sessionId":"` + id + `","type":"user"}` + "\n" +
+		`{"type":"summary","summary":"no session"}` + "\n" +
+		"not json\n"
+	if string(data) != want {
+		t.Errorf("forked transcript =\n%s\nwant:\n%s", data, want)
+	}
+	if orig, _ := os.ReadFile(filepath.Join(dir, "s1.jsonl")); string(orig) != string(transcript) {
+		t.Error("original session was modified")
+	}
+}
@@ -0,0 +1,242 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// sessionLog is a logical Claude Code session reassembled from transcript
+// files. A session resumed with `claude --resume` continues in a new file
+// whose first entry links back into the old one; compaction keeps the same
+// file but starts a new root that links back logically; sub-agents (the Task
+// tool) run in sidechains, stored inline or in their own files.
+type sessionLog struct {
+	// files lists the transcripts read: the continued ones oldest first,
+	// then the one asked for, then sub-agent transcripts.
+	files []string
+	// main is the main conversation, oldest entry first.
+	main []jsonlEntry
+	// sidechains holds the sub-agent conversations in order of appearance.
+	sidechains []sidechain
+}
+
+// sidechain is one sub-agent conversation.
+type sidechain struct {
+	// id is the sub-agent's ID, or the UUID of its first entry.
+	id      string
+	entries []jsonlEntry
+}
+
+// loadSession reads the transcript at path along with the transcripts it
+// continues and those of its sub-agents. Transcripts without entry UUIDs are
+// read as a single flat conversation.
+func loadSession(path string) (*sessionLog, error) {
+	entries, err := readEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	log := &sessionLog{files: []string{path}}
+
+	leaf := -1
+	for i, e := range entries {
+		if e.UUID != "" && !e.IsSidechain {
+			leaf = i
+		}
+	}
+	if leaf < 0 {
+		log.main = entries
+		return log, nil
+	}
+
+	byUUID := make(map[string]jsonlEntry)
+	index := func(entries []jsonlEntry) {
+		for _, e := range entries {
+			if e.UUID != "" {
+				byUUID[e.UUID] = e
+			}
+		}
+	}
+	index(entries)
+	all := entries
+
+	// Pull in earlier transcripts until the chain reaches its root.
+	dir := filepath.Dir(path)
+	for {
+		log.main = walkChain(entries[leaf], byUUID)
+		missing := log.main[0].parent()
+		if missing == "" {
+			break
+		}
+		if _, ok := byUUID[missing]; ok {
+			break // a cycle; keep what was read
+		}
+		file := findEntryFile(dir, missing, log.files)
+		if file == "" {
+			break
+		}
+		earlier, err := readEntries(file)
+		if err != nil {
+			return nil, err
+		}
+		index(earlier)
+		all = append(earlier, all...)
+		log.files = append([]string{file}, log.files...)
+	}
+
+	var sessionIDs []string
+	for _, e := range log.main {
+		if e.SessionID != "" && !slices.Contains(sessionIDs, e.SessionID) {
+			sessionIDs = append(sessionIDs, e.SessionID)
+		}
+	}
+	for _, file := range sidechainFiles(dir, sessionIDs) {
+		if slices.Contains(log.files, file) {
+			continue
+		}
+		sub, err := readEntries(file)
+		if err != nil {
+			continue
+		}
+		all = append(all, sub...)
+		log.files = append(log.files, file)
+	}
+
+	log.sidechains = groupSidechains(all)
+	return log, nil
+}
+
+// readEntries parses every line of a JSONL transcript, skipping malformed
+// lines.
+func readEntries(path string) ([]jsonlEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening JSONL file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []jsonlEntry
+	scanner := bufio.NewScanner(f)
+	// Allow for large lines (Claude transcripts can be big)
+	buf := make([]byte, 0, 1024*1024)
+	scanner.Buffer(buf, 10*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry jsonlEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue // Skip malformed lines
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning JSONL: %w", err)
+	}
+	return entries, nil
+}
+
+// walkChain returns the conversation ending at leaf, oldest entry first.
+// Entries on abandoned branches (e.g. after editing a prompt) are left out.
+func walkChain(leaf jsonlEntry, byUUID map[string]jsonlEntry) []jsonlEntry {
+	var chain []jsonlEntry
+	seen := make(map[string]bool)
+	for e, ok := leaf, true; ok && !seen[e.UUID]; e, ok = byUUID[e.parent()] {
+		seen[e.UUID] = true
+		chain = append(chain, e)
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+// findEntryFile returns the transcript in dir, other than those in skip,
+// that holds the entry with the given UUID, or "". Recently modified files
+// are searched first.
+func findEntryFile(dir, uuid string, skip []string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	mtimes := make(map[string]int64, len(files))
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			mtimes[f] = info.ModTime().UnixNano()
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return mtimes[files[i]] > mtimes[files[j]] })
+
+	needle := []byte(`"uuid":"` + uuid + `"`)
+	for _, f := range files {
+		if slices.Contains(skip, f) || isSidechainFile(f) {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err == nil && bytes.Contains(data, needle) {
+			return f
+		}
+	}
+	return ""
+}
+
+// isSidechainFile reports whether path is a sub-agent transcript rather
+// than a session of its own.
+func isSidechainFile(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), "agent-")
+}
+
+// sidechainFiles lists the sub-agent transcripts of the given sessions:
+// agent-*.jsonl files next to the session naming one of them, and the files
+// in each session's subagents directory.
+func sidechainFiles(dir string, sessionIDs []string) []string {
+	var files []string
+	agents, _ := filepath.Glob(filepath.Join(dir, "agent-*.jsonl"))
+	for _, f := range agents {
+		if slices.Contains(sessionIDs, PeekSessionID(f)) {
+			files = append(files, f)
+		}
+	}
+	for _, id := range sessionIDs {
+		sub, _ := filepath.Glob(filepath.Join(dir, id, "subagents", "*.jsonl"))
+		files = append(files, sub...)
+	}
+	return files
+}
+
+// groupSidechains splits sidechain entries into conversations: entries of
+// the same sub-agent, or linked by parent UUID, belong together.
+func groupSidechains(entries []jsonlEntry) []sidechain {
+	var threads []sidechain
+	threadOf := make(map[string]int) // entry UUID or agent ID -> thread
+	for _, e := range entries {
+		if !e.IsSidechain || e.UUID == "" {
+			continue
+		}
+		if _, seen := threadOf[e.UUID]; seen {
+			continue
+		}
+
+		i, ok := threadOf[e.AgentID]
+		if e.AgentID == "" {
+			i, ok = threadOf[e.ParentUUID]
+		}
+		if !ok {
+			id := e.AgentID
+			if id == "" {
+				id = e.UUID
+			}
+			i = len(threads)
+			threads = append(threads, sidechain{id: id})
+			if e.AgentID != "" {
+				threadOf[e.AgentID] = i
+			}
+		}
+		threadOf[e.UUID] = i
+		threads[i].entries = append(threads[i].entries, e)
+	}
+	return threads
+}
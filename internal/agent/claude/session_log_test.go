@@ -0,0 +1,184 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTranscript(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func TestParseJSONLCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+
+	// u2 was abandoned when the prompt was edited (u2b); the compaction
+	// boundary c1 starts a new root linked logically to a2.
+	writeTranscript(t, path,
+		`{"type":"user","uuid":"u1","parentUuid":null,"sessionId":"s1","message":{"role":"user","content":"Build the parser"},"timestamp":"2025-01-01T00:00:00Z"}`,
+		`{"type":"assistant","uuid":"a1","parentUuid":"u1","sessionId":"s1","message":{"id":"m1","role":"assistant","content":[{"type":"text","text":"What format?"}]},"timestamp":"2025-01-01T00:00:01Z"}`,
+		`{"type":"user","uuid":"u2","parentUuid":"a1","sessionId":"s1","message":{"role":"user","content":"XML"},"timestamp":"2025-01-01T00:00:02Z"}`,
+		`{"type":"user","uuid":"u2b","parentUuid":"a1","sessionId":"s1","message":{"role":"user","content":"JSON"},"timestamp":"2025-01-01T00:00:03Z"}`,
+		`{"type":"assistant","uuid":"a2","parentUuid":"u2b","sessionId":"s1","message":{"id":"m2","role":"assistant","content":[{"type":"text","text":"Parsing JSON."}]},"timestamp":"2025-01-01T00:00:04Z"}`,
+		`{"type":"system","subtype":"compact_boundary","uuid":"c1","parentUuid":null,"logicalParentUuid":"a2","sessionId":"s1","content":"Conversation compacted","timestamp":"2025-01-01T00:10:00Z"}`,
+		`{"type":"user","uuid":"u3","parentUuid":"c1","sessionId":"s1","isCompactSummary":true,"message":{"role":"user","content":"Summary: building a JSON parser."},"timestamp":"2025-01-01T00:10:01Z"}`,
+		`{"type":"assistant","uuid":"a3","parentUuid":"u3","sessionId":"s1","message":{"id":"m3","role":"assistant","content":[{"type":"text","text":"Done."}]},"timestamp":"2025-01-01T00:10:02Z"}`,
+		`{"type":"last-prompt","lastPrompt":"JSON","leafUuid":"a3","sessionId":"s1"}`,
+	)
+
+	data, err := ParseJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	var got []string
+	for _, m := range data.Transcript {
+		got = append(got, m.Role+":"+m.Content)
+	}
+	want := []string{
+		"user:Build the parser",
+		"assistant:What format?",
+		"user:JSON",
+		"assistant:Parsing JSON.",
+		"system:Conversation compacted",
+		"system:Summary: building a JSON parser.",
+		"assistant:Done.",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("transcript =\n%v\nwant\n%v", got, want)
+	}
+	if data.Prompt != "Build the parser" || data.Duration != 10*time.Minute+2*time.Second {
+		t.Errorf("prompt = %q, duration = %s", data.Prompt, data.Duration)
+	}
+	if data.SourceFiles != nil {
+		t.Errorf("SourceFiles = %v, want nil for a single file", data.SourceFiles)
+	}
+}
+
+func TestParseJSONLResumed(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "s1.jsonl")
+	newPath := filepath.Join(dir, "s2.jsonl")
+
+	writeTranscript(t, filepath.Join(dir, "unrelated.jsonl"),
+		`{"type":"user","uuid":"x1","parentUuid":null,"sessionId":"s0","message":{"role":"user","content":"Other work"},"timestamp":"2024-12-31T00:00:00Z"}`,
+	)
+	writeTranscript(t, oldPath,
+		`{"type":"user","uuid":"u1","parentUuid":null,"sessionId":"s1","slug":"old-slug","message":{"role":"user","content":"Add caching"},"timestamp":"2025-01-01T00:00:00Z"}`,
+		`{"type":"assistant","uuid":"a1","parentUuid":"u1","sessionId":"s1","message":{"id":"m1","role":"assistant","content":[{"type":"text","text":"Added an LRU cache."}]},"timestamp":"2025-01-01T00:00:01Z"}`,
+	)
+	writeTranscript(t, newPath,
+		`{"type":"summary","summary":"Caching","leafUuid":"a1"}`,
+		`{"type":"user","uuid":"u2","parentUuid":"a1","sessionId":"s2","slug":"new-slug","message":{"role":"user","content":"Now add eviction"},"timestamp":"2025-01-02T00:00:00Z"}`,
+		`{"type":"assistant","uuid":"a2","parentUuid":"u2","sessionId":"s2","message":{"id":"m2","role":"assistant","content":[{"type":"text","text":"Eviction added."}]},"timestamp":"2025-01-02T00:00:01Z"}`,
+	)
+
+	data, err := ParseJSONL(newPath)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	if len(data.Transcript) != 4 || data.Prompt != "Add caching" {
+		t.Errorf("transcript = %+v, prompt = %q", data.Transcript, data.Prompt)
+	}
+	if data.SessionID != "s2" || data.PlanSlug != "new-slug" {
+		t.Errorf("session = %q, slug = %q", data.SessionID, data.PlanSlug)
+	}
+	if len(data.SourceFiles) != 2 || data.SourceFiles[0] != oldPath || data.SourceFiles[1] != newPath {
+		t.Errorf("SourceFiles = %v", data.SourceFiles)
+	}
+}
+
+func TestParseJSONLSidechains(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s1.jsonl")
+
+	// One sub-agent is inline (older transcripts), the other in its own
+	// file and named by the Task result.
+	writeTranscript(t, path,
+		`{"type":"user","uuid":"u1","parentUuid":null,"sessionId":"s1","message":{"role":"user","content":"Review the code"},"timestamp":"2025-01-01T00:00:00Z"}`,
+		`{"type":"assistant","uuid":"a1","parentUuid":"u1","sessionId":"s1","message":{"id":"m1","model":"claude-opus-4-1","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Task","input":{"prompt":"Find bugs in main.go"}}],"usage":{"input_tokens":10,"output_tokens":10}},"timestamp":"2025-01-01T00:00:01Z"}`,
+		`{"type":"user","uuid":"s-u1","parentUuid":null,"isSidechain":true,"sessionId":"s1","message":{"role":"user","content":"Find bugs in main.go"},"timestamp":"2025-01-01T00:00:02Z"}`,
+		`{"type":"assistant","uuid":"s-a1","parentUuid":"s-u1","isSidechain":true,"sessionId":"s1","message":{"id":"m2","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"tool_use","id":"toolu_2","name":"Read","input":{"file_path":"/repo/main.go"}}],"usage":{"input_tokens":100,"output_tokens":5}},"timestamp":"2025-01-01T00:00:03Z"}`,
+		`{"type":"assistant","uuid":"s-a2","parentUuid":"s-a1","isSidechain":true,"sessionId":"s1","message":{"id":"m3","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"One bug found."}],"usage":{"input_tokens":100,"output_tokens":20}},"timestamp":"2025-01-01T00:00:04Z"}`,
+		`{"type":"user","uuid":"u2","parentUuid":"a1","sessionId":"s1","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"One bug found."}]},"timestamp":"2025-01-01T00:00:05Z"}`,
+		`{"type":"assistant","uuid":"a2","parentUuid":"u2","sessionId":"s1","message":{"id":"m4","model":"claude-opus-4-1","role":"assistant","content":[{"type":"tool_use","id":"toolu_3","name":"Task","input":{"prompt":"Write tests"}}],"usage":{"input_tokens":10,"output_tokens":10}},"timestamp":"2025-01-01T00:00:06Z"}`,
+		`{"type":"user","uuid":"u3","parentUuid":"a2","sessionId":"s1","toolUseResult":{"agentId":"ag1","status":"completed"},"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_3","content":"Tests written."}]},"timestamp":"2025-01-01T00:00:09Z"}`,
+	)
+	writeTranscript(t, filepath.Join(dir, "agent-ag1.jsonl"),
+		`{"type":"user","uuid":"g-u1","parentUuid":null,"isSidechain":true,"agentId":"ag1","sessionId":"s1","message":{"role":"user","content":"Write tests for the parser"},"timestamp":"2025-01-01T00:00:07Z"}`,
+		`{"type":"assistant","uuid":"g-a1","parentUuid":"g-u1","isSidechain":true,"agentId":"ag1","sessionId":"s1","message":{"id":"m5","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"Tests written."}],"usage":{"input_tokens":50,"output_tokens":50}},"timestamp":"2025-01-01T00:00:08Z"}`,
+	)
+	writeTranscript(t, filepath.Join(dir, "agent-other.jsonl"),
+		`{"type":"user","uuid":"o-u1","parentUuid":null,"isSidechain":true,"agentId":"other","sessionId":"s9","message":{"role":"user","content":"Unrelated"},"timestamp":"2025-01-01T00:00:07Z"}`,
+	)
+
+	data, err := ParseJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	for _, m := range data.Transcript {
+		if m.Content == "Find bugs in main.go" || m.Content == "Tests written." {
+			t.Errorf("sub-agent message in main transcript: %+v", m)
+		}
+	}
+	if len(data.ToolCalls) != 2 {
+		t.Errorf("main tool calls = %+v", data.ToolCalls)
+	}
+
+	if len(data.Threads) != 2 {
+		t.Fatalf("threads = %+v", data.Threads)
+	}
+	inline, file := data.Threads[0], data.Threads[1]
+	if inline.ID != "s-u1" || inline.ToolCallID != "toolu_1" || len(inline.Messages) != 3 || len(inline.ToolCalls) != 1 {
+		t.Errorf("inline thread = %+v", inline)
+	}
+	if file.ID != "ag1" || file.ToolCallID != "toolu_3" || len(file.Messages) != 2 {
+		t.Errorf("file thread = %+v", file)
+	}
+
+	// Sub-agent usage counts towards the session.
+	if got := data.ModelTokens["claude-sonnet-4-5"]; got.Input != 250 || got.Output != 75 {
+		t.Errorf("sonnet tokens = %+v", got)
+	}
+	if len(data.SourceFiles) != 2 || filepath.Base(data.SourceFiles[1]) != "agent-ag1.jsonl" {
+		t.Errorf("SourceFiles = %v", data.SourceFiles)
+	}
+}
+
+func TestFindLatestJSONLPathSkipsSidechains(t *testing.T) {
+	tmpDir := t.TempDir()
+	home := filepath.Join(tmpDir, "home")
+	repoRoot := filepath.Join(tmpDir, "repo")
+	sessionDir := filepath.Join(home, ".claude", "projects", sanitizePath(repoRoot))
+	if err := os.MkdirAll(sessionDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+
+	now := time.Now()
+	for name, age := range map[string]time.Duration{"session.jsonl": time.Hour, "agent-ag1.jsonl": time.Minute} {
+		p := filepath.Join(sessionDir, name)
+		if err := os.WriteFile(p, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := (&Detector{}).FindLatestJSONLPath(repoRoot)
+	if err != nil {
+		t.Fatalf("FindLatestJSONLPath: %v", err)
+	}
+	if filepath.Base(got) != "session.jsonl" {
+		t.Errorf("got %s, want session.jsonl", got)
+	}
+}
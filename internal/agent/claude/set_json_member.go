@@ -0,0 +1,122 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonMember is a member of a JSON object, as byte offsets into its source.
+type jsonMember struct {
+	key        string
+	keyStart   int // the key's opening quote
+	valueStart int
+	valueEnd   int
+}
+
+// setJSONMember sets the top-level key of the JSON object data to value, or
+// removes it when value is nil, leaving every other byte of data as it was.
+// A new member is added last, indented like the first one.
+func setJSONMember(data []byte, key string, value json.RawMessage) ([]byte, error) {
+	members, closing, err := jsonMembers(data)
+	if err != nil {
+		return nil, err
+	}
+
+	open := bytes.IndexByte(data, '{')
+	indent, colon := "\n  ", ": "
+	if len(members) > 0 {
+		indent = string(data[open+1 : members[0].keyStart])
+	}
+	if value != nil {
+		var buf bytes.Buffer
+		if prefix, ok := strings.CutPrefix(strings.TrimLeft(indent, "\r"), "\n"); ok {
+			err = json.Indent(&buf, value, prefix, "  ")
+		} else {
+			// A single-line file stays on one line.
+			indent, colon = "", ":"
+			err = json.Compact(&buf, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		value = buf.Bytes()
+	}
+
+	var out []byte
+	for i, m := range members {
+		if m.key != key {
+			continue
+		}
+		switch {
+		case value != nil:
+			out = splice(data, m.valueStart, m.valueEnd, value)
+		case i > 0:
+			// Drop the member along with the comma before it.
+			out = splice(data, members[i-1].valueEnd, m.valueEnd, nil)
+		case len(members) > 1:
+			out = splice(data, m.keyStart, members[1].keyStart, nil)
+		default:
+			out = splice(data, open+1, closing, nil)
+		}
+		return out, nil
+	}
+	if value == nil {
+		return data, nil
+	}
+
+	name, _ := json.Marshal(key)
+	member := append([]byte(indent), name...)
+	member = append(append(member, colon...), value...)
+	if len(members) == 0 {
+		if indent != "" {
+			member = append(member, '\n')
+		}
+		return splice(data, open+1, closing, member), nil
+	}
+	last := members[len(members)-1].valueEnd
+	return splice(data, last, last, append([]byte{','}, member...)), nil
+}
+
+// jsonMembers locates the members of the JSON object data and its closing
+// brace.
+func jsonMembers(data []byte) ([]jsonMember, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, 0, fmt.Errorf("not a JSON object")
+	}
+
+	var members []jsonMember
+	for dec.More() {
+		prev := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, 0, err
+		}
+		end := int(dec.InputOffset())
+		members = append(members, jsonMember{
+			key:        key,
+			keyStart:   prev + bytes.IndexByte(data[prev:], '"'),
+			valueStart: end - len(raw),
+			valueEnd:   end,
+		})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, 0, err
+	}
+	return members, int(dec.InputOffset()) - 1, nil
+}
+
+// splice returns data with data[start:end] replaced by insert.
+func splice(data []byte, start, end int, insert []byte) []byte {
+	out := make([]byte, 0, len(data)-(end-start)+len(insert))
+	out = append(out, data[:start]...)
+	out = append(out, insert...)
+	return append(out, data[end:]...)
+}
@@ -0,0 +1,83 @@
+package claude
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSetJSONMember(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		value string // "" removes the member
+		want  string
+	}{
+		{
+			name:  "replaces a member in place",
+			data:  "{\n  \"model\": \"opus\",\n  \"hooks\": {},\n  \"env\": {\"A\":  \"1\"}\n}\n",
+			value: `{"Stop":[]}`,
+			want:  "{\n  \"model\": \"opus\",\n  \"hooks\": {\n    \"Stop\": []\n  },\n  \"env\": {\"A\":  \"1\"}\n}\n",
+		},
+		{
+			name:  "adds a member last",
+			data:  "{\n    \"zeta\": 1,\n    \"alpha\": [1, 2]\n}\n",
+			value: `{"Stop":[]}`,
+			want:  "{\n    \"zeta\": 1,\n    \"alpha\": [1, 2],\n    \"hooks\": {\n      \"Stop\": []\n    }\n}\n",
+		},
+		{
+			name:  "adds to an empty object",
+			data:  "{}\n",
+			value: `{"Stop":[]}`,
+			want:  "{\n  \"hooks\": {\n    \"Stop\": []\n  }\n}\n",
+		},
+		{
+			name:  "keeps a single-line file on one line",
+			data:  `{"model":"opus"}`,
+			value: `{"Stop": []}`,
+			want:  `{"model":"opus","hooks":{"Stop":[]}}`,
+		},
+		{
+			name: "removes a middle member",
+			data: "{\n  \"model\": \"opus\",\n  \"hooks\": {},\n  \"env\": {}\n}\n",
+			want: "{\n  \"model\": \"opus\",\n  \"env\": {}\n}\n",
+		},
+		{
+			name: "removes the first member",
+			data: "{\n  \"hooks\": {},\n  \"model\": \"opus\"\n}\n",
+			want: "{\n  \"model\": \"opus\"\n}\n",
+		},
+		{
+			name: "removes the only member",
+			data: "{\n  \"hooks\": {}\n}\n",
+			want: "{}\n",
+		},
+		{
+			name: "removing a missing member changes nothing",
+			data: "{\n  \"model\": \"opus\"\n}\n",
+			want: "{\n  \"model\": \"opus\"\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value json.RawMessage
+			if tt.value != "" {
+				value = json.RawMessage(tt.value)
+			}
+			got, err := setJSONMember([]byte(tt.data), "hooks", value)
+			if err != nil {
+				t.Fatalf("setJSONMember: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("setJSONMember =\n%s\nwant:\n%s", got, tt.want)
+			}
+			if !json.Valid(got) {
+				t.Errorf("result is not valid JSON:\n%s", got)
+			}
+		})
+	}
+
+	if _, err := setJSONMember([]byte(`["not", "an", "object"]`), "hooks", nil); err == nil {
+		t.Error("setJSONMember accepted a JSON array")
+	}
+}
@@ -0,0 +1,98 @@
+package claude
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/partio-io/cli/internal/agent"
+)
+
+// toolCollector gathers tool calls from tool_use blocks and completes them
+// with the matching tool_result blocks, which arrive in later user entries.
+type toolCollector struct {
+	calls []agent.ToolCall
+	byID  map[string]int
+}
+
+func newToolCollector() *toolCollector {
+	return &toolCollector{byID: make(map[string]int)}
+}
+
+// add records the tool calls and results found in an entry's blocks.
+func (c *toolCollector) add(blocks []contentBlock, ts time.Time) {
+	for _, b := range blocks {
+		switch b.Type {
+		case "tool_use":
+			if _, seen := c.byID[b.ID]; seen && b.ID != "" {
+				continue // repeated line of a streamed message
+			}
+			call := agent.ToolCall{
+				ID:           b.ID,
+				Name:         b.Name,
+				Input:        b.Input,
+				FilesTouched: inputFiles(b.Input),
+				Timestamp:    ts,
+			}
+			if b.ID != "" {
+				c.byID[b.ID] = len(c.calls)
+			}
+			c.calls = append(c.calls, call)
+
+		case "tool_result":
+			i, ok := c.byID[b.ToolUseID]
+			if !ok {
+				continue
+			}
+			call := &c.calls[i]
+			result := resultText(b.Content)
+			call.ResultSummary = agent.SummarizeResult(result)
+			call.IsError = b.IsError
+			if code, ok := agent.ParseExitCode(result); ok {
+				call.ExitCode = &code
+			} else if call.Name == "Bash" && !b.IsError {
+				code := 0
+				call.ExitCode = &code
+			}
+		}
+	}
+}
+
+// inputFiles returns the file paths named by a tool's input (Read, Edit,
+// MultiEdit, Write and NotebookEdit all use one of these fields).
+func inputFiles(input json.RawMessage) []string {
+	var fields struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	}
+	if json.Unmarshal(input, &fields) != nil {
+		return nil
+	}
+	var files []string
+	for _, f := range []string{fields.FilePath, fields.NotebookPath} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// resultText flattens tool_result content, which is either a string or a
+// list of blocks, to text.
+func resultText(content json.RawMessage) string {
+	var s string
+	if json.Unmarshal(content, &s) == nil {
+		return s
+	}
+	var blocks []contentBlock
+	if json.Unmarshal(content, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
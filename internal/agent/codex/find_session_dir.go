@@ -83,3 +83,37 @@ func (d *Detector) FindLatestSession(repoRoot string) (string, *agent.SessionDat
 
 	return "", nil, fmt.Errorf("no Codex session found for repo %s", repoRoot)
 }
+
+// SessionPath returns the session file of the Codex thread sessionID. Codex
+// names session files rollout-<timestamp>-<thread id>.jsonl.
+func (d *Detector) SessionPath(sessionID string) (string, error) {
+	sessionDir, err := d.FindSessionDir("")
+	if err != nil {
+		return "", err
+	}
+
+	var found string
+	suffix := "-" + sessionID + ".jsonl"
+	err = filepath.WalkDir(sessionDir, func(path string, e os.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip errors
+		}
+		if !e.IsDir() && strings.HasSuffix(path, suffix) {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("walking session directory: %w", err)
+	}
+	if found == "" {
+		return "", fmt.Errorf("no Codex session file found for %s", sessionID)
+	}
+	return found, nil
+}
+
+// ReadSession parses the Codex session file at path.
+func (d *Detector) ReadSession(path string) (*agent.SessionData, error) {
+	return ParseJSONL(path)
+}
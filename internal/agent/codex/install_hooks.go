@@ -0,0 +1,108 @@
+package codex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/partio-io/cli/internal/agent"
+)
+
+// InstallHooks sets the notify program in the user's ~/.codex/config.toml to
+// partio. Codex runs it when the agent finishes a turn, passing the thread ID
+// as JSON, which partio records as a stop event. An existing notify program
+// is left alone and reported as an error, since Codex runs only one.
+func (d *Detector) InstallHooks(bin string) (bool, error) {
+	path, err := configPath()
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		return false, nil // Codex has never run here
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	want := "notify = " + tomlArray(agent.EventArgs(bin, "codex", "stop"))
+	i := notifyLine(lines)
+	switch {
+	case i < 0:
+		lines = append([]string{want}, lines...)
+	case lines[i] == want:
+		return false, nil
+	case !agent.IsEventCommand(lines[i]):
+		return false, fmt.Errorf("%s already sets notify; add %q to it to report turns to partio", path, agent.EventCommand(bin, "codex", "stop"))
+	default:
+		lines[i] = want
+	}
+
+	if err := agent.WriteConfigFile(path, []byte(strings.Join(lines, "\n"))); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UninstallHooks removes partio's notify program from ~/.codex/config.toml.
+func (d *Detector) UninstallHooks() (bool, error) {
+	path, err := configPath()
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	i := notifyLine(lines)
+	if i < 0 || !agent.IsEventCommand(lines[i]) {
+		return false, nil
+	}
+	lines = append(lines[:i], lines[i+1:]...)
+	if err := agent.WriteConfigFile(path, []byte(strings.Join(lines, "\n"))); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func configPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".codex", "config.toml"), nil
+}
+
+// notifyLine returns the index of the top-level notify key in a TOML file,
+// or -1. Keys after the first table header belong to that table.
+func notifyLine(lines []string) int {
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			break
+		}
+		key, _, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "notify" {
+			return i
+		}
+	}
+	return -1
+}
+
+// tomlArray formats strings as a TOML array.
+func tomlArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,96 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallHooks(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "adds notify before the first table",
+			existing: "model = \"o3\"\n\n[profiles.fast]\nnotify = [\"other\"]\n",
+			want:     "notify = [\"partio\", \"_agent-event\", \"stop\", \"--agent\", \"codex\"]\nmodel = \"o3\"\n\n[profiles.fast]\nnotify = [\"other\"]\n",
+		},
+		{
+			name:     "updates partio's notify",
+			existing: "notify = [\"/old/partio\", \"_agent-event\", \"stop\", \"--agent\", \"codex\"]\n",
+			want:     "notify = [\"partio\", \"_agent-event\", \"stop\", \"--agent\", \"codex\"]\n",
+		},
+		{
+			name:     "keeps another notify program",
+			existing: "notify = [\"notify-send\", \"codex\"]\n",
+			want:     "notify = [\"notify-send\", \"codex\"]\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			path := filepath.Join(home, ".codex", "config.toml")
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := New().InstallHooks("partio")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InstallHooks error = %v, wantErr %v", err, tt.wantErr)
+			}
+			data, _ := os.ReadFile(path)
+			if string(data) != tt.want {
+				t.Errorf("config.toml =\n%s\nwant\n%s", data, tt.want)
+			}
+
+			if tt.wantErr {
+				return
+			}
+			if changed, err := New().UninstallHooks(); err != nil || !changed {
+				t.Fatalf("UninstallHooks = %v, %v", changed, err)
+			}
+			data, _ = os.ReadFile(path)
+			if strings.Contains(string(data), "_agent-event") {
+				t.Errorf("config.toml after uninstall =\n%s", data)
+			}
+		})
+	}
+}
+
+func TestInstallHooksWithoutCodex(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if changed, err := New().InstallHooks("partio"); err != nil || changed {
+		t.Errorf("InstallHooks = %v, %v; want no change when Codex is not set up", changed, err)
+	}
+}
+
+func TestSessionPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".codex", "sessions", "2025", "01", "02")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "rollout-2025-01-02T03-04-05-thread-1.jsonl")
+	if err := os.WriteFile(want, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := New().SessionPath("thread-1")
+	if err != nil || got != want {
+		t.Errorf("SessionPath = %q, %v; want %q", got, err, want)
+	}
+	if _, err := New().SessionPath("thread-2"); err == nil {
+		t.Error("SessionPath found a missing thread")
+	}
+}
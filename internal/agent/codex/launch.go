@@ -0,0 +1,27 @@
+package codex
+
+import "fmt"
+
+// Executable returns the name of the Codex CLI.
+func (d *Detector) Executable() string {
+	return "codex"
+}
+
+// ResumeArgs returns `resume <sessionID>` if the session's rollout file is
+// still in ~/.codex/sessions. Codex sessions are not tied to a directory, so
+// repoRoot is unused.
+func (d *Detector) ResumeArgs(repoRoot, sessionID string) ([]string, bool) {
+	if sessionID == "" {
+		return nil, false
+	}
+	if _, err := d.SessionPath(sessionID); err != nil {
+		return nil, false
+	}
+	return []string{"resume", sessionID}, true
+}
+
+// PromptArgs returns an initial prompt pointing Codex at promptFile, which
+// starts an interactive session working from it.
+func (d *Detector) PromptArgs(promptFile string) []string {
+	return []string{fmt.Sprintf("Read %s for full context on a previous session, then continue that work.", promptFile)}
+}
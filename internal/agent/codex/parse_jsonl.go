@@ -2,9 +2,11 @@ package codex
 
 import (
 	"bufio"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/partio-io/cli/internal/agent"
@@ -26,15 +28,62 @@ type sessionMeta struct {
 type eventMsg struct {
 	Type    string `json:"type"`
 	Message string `json:"message,omitempty"`
+	// Images attached to a user_message, as data URLs or paths.
+	Images []string `json:"images,omitempty"`
+
+	// Set on exec_command_end events.
+	CallID   string `json:"call_id,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+
+	// Set on token_count events.
+	Info *struct {
+		TotalTokenUsage tokenUsage `json:"total_token_usage"`
+	} `json:"info,omitempty"`
+}
+
+// turnContext is logged at the start of each turn and names the model used.
+type turnContext struct {
+	Model string `json:"model"`
+}
+
+// tokenUsage is Codex's cumulative usage for a session. InputTokens includes
+// CachedInputTokens.
+type tokenUsage struct {
+	InputTokens       int `json:"input_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+}
+
+// since returns the usage accrued after prev, as an agent token breakdown.
+func (u tokenUsage) since(prev tokenUsage) agent.TokenUsage {
+	cached := u.CachedInputTokens - prev.CachedInputTokens
+	return agent.TokenUsage{
+		Input:     u.InputTokens - prev.InputTokens - cached,
+		Output:    u.OutputTokens - prev.OutputTokens,
+		CacheRead: cached,
+	}
 }
 
 type responseItem struct {
 	Type      string `json:"type"`
+	Role      string `json:"role,omitempty"`
 	Name      string `json:"name,omitempty"`
 	Arguments string `json:"arguments,omitempty"`
 	Content   []struct {
 		Text string `json:"text"`
 	} `json:"content,omitempty"`
+
+	// Summary holds the readable part of a reasoning item.
+	Summary []struct {
+		Text string `json:"text"`
+	} `json:"summary,omitempty"`
+
+	// Tool calls (function_call, custom_tool_call, local_shell_call) and
+	// their outputs (function_call_output, custom_tool_call_output).
+	CallID string          `json:"call_id,omitempty"`
+	Input  string          `json:"input,omitempty"`
+	Output string          `json:"output,omitempty"`
+	Action json.RawMessage `json:"action,omitempty"`
 }
 
 // ParseJSONL parses a Codex JSONL session file into SessionData.
@@ -49,7 +98,16 @@ func ParseJSONL(path string) (*agent.SessionData, error) {
 		Agent: "codex",
 	}
 
-	var firstTS, lastTS time.Time
+	var (
+		firstTS, lastTS time.Time
+		model           string
+		usage           tokenUsage
+		// counted is the transcript length at the last token_count
+		// event; only messages after it are charged for new usage.
+		counted int
+
+		tools = newToolCollector()
+	)
 	scanner := bufio.NewScanner(f)
 	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
 
@@ -72,6 +130,13 @@ func ParseJSONL(path string) (*agent.SessionData, error) {
 			var meta sessionMeta
 			if err := json.Unmarshal(line.Payload, &meta); err == nil {
 				data.SessionID = meta.ID
+				data.CWD = meta.CWD
+			}
+
+		case "turn_context":
+			var tc turnContext
+			if err := json.Unmarshal(line.Payload, &tc); err == nil && tc.Model != "" {
+				model = tc.Model
 			}
 
 		case "event_msg":
@@ -85,28 +150,38 @@ func ParseJSONL(path string) (*agent.SessionData, error) {
 				if data.Prompt == "" {
 					data.Prompt = evt.Message
 				}
-				data.Transcript = append(data.Transcript, agent.Message{
+				msg := agent.Message{
 					Role:      "user",
 					Content:   evt.Message,
 					Timestamp: lastTS,
-				})
+				}
+				for _, img := range evt.Images {
+					msg.Attachments = append(msg.Attachments, imageAttachment(img))
+				}
+				data.Transcript = append(data.Transcript, msg)
 
 			case "agent_message":
-				data.Transcript = append(data.Transcript, agent.Message{
-					Role:      "assistant",
-					Content:   evt.Message,
-					Timestamp: lastTS,
-				})
+				addAssistantText(data, evt.Message, lastTS)
+
+			case "exec_command_end":
+				tools.exitCode(evt.CallID, evt.ExitCode)
 
 			case "token_count":
-				// Token counts come as a separate event; extract if needed
-				var tc struct {
-					InputTokens  int `json:"input_tokens"`
-					OutputTokens int `json:"output_tokens"`
+				// Usage is cumulative and may be repeated; attribute only
+				// the growth since the last event to the current model.
+				if evt.Info == nil {
+					continue
 				}
-				if err := json.Unmarshal(line.Payload, &tc); err == nil {
-					data.TotalTokens += tc.InputTokens + tc.OutputTokens
+				total := evt.Info.TotalTokenUsage
+				if delta := total.since(usage); delta.Total() > 0 {
+					data.AddUsage(model, delta)
+					if n := len(data.Transcript); n > counted && data.Transcript[n-1].Role == "assistant" {
+						data.Transcript[n-1].Model = model
+						data.Transcript[n-1].Tokens = delta.Total()
+					}
 				}
+				usage = total
+				counted = len(data.Transcript)
 			}
 
 		case "response_item":
@@ -115,29 +190,91 @@ func ParseJSONL(path string) (*agent.SessionData, error) {
 				continue
 			}
 
-			if item.Type == "message" {
+			if id := tools.add(item, lastTS); id != "" {
+				turn := assistantTurn(data, lastTS)
+				turn.ToolCallIDs = append(turn.ToolCallIDs, id)
+			}
+
+			switch item.Type {
+			case "message":
+				// User and developer messages repeat the user_message
+				// events and the injected instructions.
+				if item.Role != "" && item.Role != "assistant" {
+					continue
+				}
 				var text string
 				for _, c := range item.Content {
 					text += c.Text
 				}
-				if text != "" {
-					data.Transcript = append(data.Transcript, agent.Message{
-						Role:      "assistant",
-						Content:   text,
-						Timestamp: lastTS,
-					})
+				addAssistantText(data, text, lastTS)
+
+			case "reasoning":
+				var parts []string
+				for _, s := range item.Summary {
+					if s.Text != "" {
+						parts = append(parts, s.Text)
+					}
+				}
+				if len(parts) > 0 {
+					turn := assistantTurn(data, lastTS)
+					turn.Thinking = append(turn.Thinking, strings.Join(parts, "\n\n"))
 				}
 			}
 		}
 	}
 
 	if !firstTS.IsZero() && !lastTS.IsZero() {
+		data.StartedAt = firstTS
 		data.Duration = lastTS.Sub(firstTS)
 	}
+	data.ToolCalls = tools.calls
 
 	return data, scanner.Err()
 }
 
+// assistantTurn returns the assistant message that reasoning and tool calls
+// belong to: the last message while the assistant has not yet replied with
+// text, otherwise a new one.
+func assistantTurn(data *agent.SessionData, ts time.Time) *agent.Message {
+	if n := len(data.Transcript); n > 0 && data.Transcript[n-1].Role == "assistant" && data.Transcript[n-1].Content == "" {
+		return &data.Transcript[n-1]
+	}
+	data.Transcript = append(data.Transcript, agent.Message{Role: "assistant", Timestamp: ts})
+	return &data.Transcript[len(data.Transcript)-1]
+}
+
+// addAssistantText records an assistant reply. Codex logs each reply both as
+// a response item and as an agent_message event; the second copy is dropped.
+func addAssistantText(data *agent.SessionData, text string, ts time.Time) {
+	if text == "" {
+		return
+	}
+	for i := len(data.Transcript) - 1; i >= 0; i-- {
+		if m := data.Transcript[i]; m.Role == "assistant" && m.Content != "" {
+			if m.Content == text {
+				return
+			}
+			break
+		}
+	}
+	turn := assistantTurn(data, ts)
+	turn.Content = text
+}
+
+// imageAttachment describes an image attached to a user message, given as a
+// data URL or a path.
+func imageAttachment(img string) agent.Attachment {
+	a := agent.Attachment{Type: "image"}
+	header, payload, isData := strings.Cut(strings.TrimPrefix(img, "data:"), ",")
+	if !strings.HasPrefix(img, "data:") || !isData {
+		a.Name = img
+		return a
+	}
+	a.MediaType, _, _ = strings.Cut(header, ";")
+	a.Size = base64.StdEncoding.DecodedLen(len(payload))
+	return a
+}
+
 // PeekSessionID reads just the session ID from a Codex JSONL file.
 func PeekSessionID(path string) string {
 	f, err := os.Open(path)
@@ -0,0 +1,136 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/partio-io/cli/internal/agent"
+)
+
+func TestParseJSONLTokenUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+
+	// token_count totals are cumulative and may repeat; the model switches
+	// between turns.
+	lines := `{"timestamp":"2025-01-01T00:00:00.000Z","type":"session_meta","payload":{"id":"codex-1","cwd":"/repo"}}
+{"timestamp":"2025-01-01T00:00:01.000Z","type":"turn_context","payload":{"model":"gpt-5-codex"}}
+{"timestamp":"2025-01-01T00:00:02.000Z","type":"event_msg","payload":{"type":"user_message","message":"Add tests"}}
+{"timestamp":"2025-01-01T00:00:03.000Z","type":"event_msg","payload":{"type":"agent_message","message":"Added."}}
+{"timestamp":"2025-01-01T00:00:04.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1000,"cached_input_tokens":600,"output_tokens":100}}}}
+{"timestamp":"2025-01-01T00:00:05.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1000,"cached_input_tokens":600,"output_tokens":100}}}}
+{"timestamp":"2025-01-01T00:00:06.000Z","type":"event_msg","payload":{"type":"token_count","info":null}}
+{"timestamp":"2025-01-01T00:00:07.000Z","type":"turn_context","payload":{"model":"gpt-5"}}
+{"timestamp":"2025-01-01T00:00:08.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1500,"cached_input_tokens":900,"output_tokens":160}}}}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+
+	data, err := ParseJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	tests := []struct {
+		model string
+		want  agent.TokenUsage
+	}{
+		{"gpt-5-codex", agent.TokenUsage{Input: 400, Output: 100, CacheRead: 600}},
+		{"gpt-5", agent.TokenUsage{Input: 200, Output: 60, CacheRead: 300}},
+	}
+	for _, tt := range tests {
+		if got := data.ModelTokens[tt.model]; got != tt.want {
+			t.Errorf("ModelTokens[%q] = %+v, want %+v", tt.model, got, tt.want)
+		}
+	}
+
+	want := agent.TokenUsage{Input: 600, Output: 160, CacheRead: 900}
+	if data.Tokens != want || data.TotalTokens != want.Total() {
+		t.Errorf("Tokens = %+v (total %d), want %+v", data.Tokens, data.TotalTokens, want)
+	}
+	if m := data.Transcript[1]; m.Model != "gpt-5-codex" || m.Tokens != 1100 {
+		t.Errorf("agent message = %+v", m)
+	}
+}
+
+func TestParseJSONLToolCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+
+	lines := `{"timestamp":"2025-01-01T00:00:00.000Z","type":"session_meta","payload":{"id":"codex-1","cwd":"/repo"}}
+{"timestamp":"2025-01-01T00:00:01.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"go test ./...\"]}","call_id":"call_1"}}
+{"timestamp":"2025-01-01T00:00:02.000Z","type":"event_msg","payload":{"type":"exec_command_end","call_id":"call_1","exit_code":1}}
+{"timestamp":"2025-01-01T00:00:02.100Z","type":"response_item","payload":{"type":"function_call_output","call_id":"call_1","output":"{\"output\":\"FAIL\\n\",\"metadata\":{\"exit_code\":1,\"duration_seconds\":0.5}}"}}
+{"timestamp":"2025-01-01T00:00:03.000Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","call_id":"call_2","input":"*** Begin Patch\n*** Update File: main.go\n@@\n-a\n+b\n*** End Patch"}}
+{"timestamp":"2025-01-01T00:00:04.000Z","type":"response_item","payload":{"type":"custom_tool_call_output","call_id":"call_2","output":"{\"output\":\"Success. Updated the following files:\\nM main.go\\n\",\"metadata\":{\"exit_code\":0}}"}}
+{"timestamp":"2025-01-01T00:00:05.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"apply_patch\",\"*** Begin Patch\\n*** Add File: util.go\\n+package main\\n*** End Patch\"]}","call_id":"call_3"}}
+{"timestamp":"2025-01-01T00:00:06.000Z","type":"response_item","payload":{"type":"function_call_output","call_id":"call_3","output":"Exit code: 0\nWall time: 0 seconds\nOutput:\nDone!"}}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+
+	data, err := ParseJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	calls := data.ToolCalls
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 tool calls, got %d: %+v", len(calls), calls)
+	}
+
+	if c := calls[0]; c.Name != "shell" || !c.IsError || c.ExitCode == nil || *c.ExitCode != 1 || c.ResultSummary != "FAIL" {
+		t.Errorf("shell call = %+v", c)
+	}
+	if c := calls[1]; c.Name != "apply_patch" || len(c.FilesTouched) != 1 || c.FilesTouched[0] != "main.go" || c.IsError {
+		t.Errorf("apply_patch call = %+v", c)
+	}
+	if c := calls[2]; len(c.FilesTouched) != 1 || c.FilesTouched[0] != "util.go" || c.ExitCode == nil || *c.ExitCode != 0 {
+		t.Errorf("shell apply_patch call = %+v", c)
+	}
+}
+
+func TestParseJSONLTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+
+	// Replies appear both as response items and as agent_message events;
+	// user and developer response items repeat prompts and instructions.
+	lines := `{"timestamp":"2025-01-01T00:00:00.000Z","type":"session_meta","payload":{"id":"codex-1","cwd":"/repo"}}
+{"timestamp":"2025-01-01T00:00:01.000Z","type":"response_item","payload":{"type":"message","role":"developer","content":[{"type":"input_text","text":"<instructions>"}]}}
+{"timestamp":"2025-01-01T00:00:02.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"Fix the build"}]}}
+{"timestamp":"2025-01-01T00:00:02.000Z","type":"event_msg","payload":{"type":"user_message","message":"Fix the build","images":["data:image/png;base64,aGVsbG8="]}}
+{"timestamp":"2025-01-01T00:00:03.000Z","type":"response_item","payload":{"type":"reasoning","summary":[{"type":"summary_text","text":"Checking the build"}],"encrypted_content":"xyz"}}
+{"timestamp":"2025-01-01T00:00:04.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"make\"]}","call_id":"call_1"}}
+{"timestamp":"2025-01-01T00:00:05.000Z","type":"response_item","payload":{"type":"function_call_output","call_id":"call_1","output":"ok"}}
+{"timestamp":"2025-01-01T00:00:06.000Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Fixed."}]}}
+{"timestamp":"2025-01-01T00:00:06.000Z","type":"event_msg","payload":{"type":"agent_message","message":"Fixed."}}
+{"timestamp":"2025-01-01T00:00:07.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"make\",\"test\"]}","call_id":"call_2"}}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatalf("writing test file: %v", err)
+	}
+
+	data, err := ParseJSONL(path)
+	if err != nil {
+		t.Fatalf("ParseJSONL error: %v", err)
+	}
+
+	if len(data.Transcript) != 3 {
+		t.Fatalf("expected 3 messages, got %d: %+v", len(data.Transcript), data.Transcript)
+	}
+	user := data.Transcript[0]
+	if user.Role != "user" || len(user.Attachments) != 1 || user.Attachments[0].MediaType != "image/png" || user.Attachments[0].Size != 6 {
+		t.Errorf("user message = %+v", user)
+	}
+	reply := data.Transcript[1]
+	if reply.Role != "assistant" || reply.Content != "Fixed." || len(reply.Thinking) != 1 || len(reply.ToolCallIDs) != 1 || reply.ToolCallIDs[0] != "call_1" {
+		t.Errorf("reply = %+v", reply)
+	}
+	if next := data.Transcript[2]; next.Content != "" || len(next.ToolCallIDs) != 1 || next.ToolCallIDs[0] != "call_2" {
+		t.Errorf("next turn = %+v", next)
+	}
+	if data.StartedAt.IsZero() {
+		t.Error("StartedAt is not set")
+	}
+}
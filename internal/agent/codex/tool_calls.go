@@ -0,0 +1,126 @@
+package codex
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/partio-io/cli/internal/agent"
+)
+
+// toolCollector gathers tool calls from response items and completes them
+// with their outputs and exit codes, matched by call ID.
+type toolCollector struct {
+	calls []agent.ToolCall
+	byID  map[string]int
+}
+
+func newToolCollector() *toolCollector {
+	return &toolCollector{byID: make(map[string]int)}
+}
+
+// add records a tool call or its output. It returns the ID of the call item
+// starts, if any.
+func (c *toolCollector) add(item responseItem, ts time.Time) string {
+	switch item.Type {
+	case "function_call":
+		return c.start(item.CallID, agent.ToolCall{
+			Name:         item.Name,
+			Input:        jsonInput(item.Arguments),
+			FilesTouched: agent.PatchFiles(commandText(item.Arguments)),
+			Timestamp:    ts,
+		})
+
+	case "custom_tool_call":
+		return c.start(item.CallID, agent.ToolCall{
+			Name:         item.Name,
+			Input:        jsonInput(item.Input),
+			FilesTouched: agent.PatchFiles(item.Input),
+			Timestamp:    ts,
+		})
+
+	case "local_shell_call":
+		return c.start(item.CallID, agent.ToolCall{
+			Name:      "local_shell",
+			Input:     item.Action,
+			Timestamp: ts,
+		})
+
+	case "function_call_output", "custom_tool_call_output":
+		i, ok := c.byID[item.CallID]
+		if !ok {
+			return ""
+		}
+		output, exitCode := parseOutput(item.Output)
+		call := &c.calls[i]
+		call.ResultSummary = agent.SummarizeResult(output)
+		if exitCode != nil {
+			call.ExitCode = exitCode
+		}
+		if call.ExitCode != nil && *call.ExitCode != 0 {
+			call.IsError = true
+		}
+	}
+	return ""
+}
+
+// exitCode records the exit status reported by an exec_command_end event.
+func (c *toolCollector) exitCode(callID string, code *int) {
+	if i, ok := c.byID[callID]; ok && code != nil {
+		c.calls[i].ExitCode = code
+		c.calls[i].IsError = *code != 0
+	}
+}
+
+func (c *toolCollector) start(callID string, call agent.ToolCall) string {
+	call.ID = callID
+	if callID != "" {
+		c.byID[callID] = len(c.calls)
+	}
+	c.calls = append(c.calls, call)
+	return callID
+}
+
+// jsonInput returns s as JSON: verbatim when it already is (function call
+// arguments), otherwise as a JSON string (e.g. a raw patch).
+func jsonInput(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	b, _ := json.Marshal(s)
+	return b
+}
+
+// commandText returns the command of shell-style function call arguments
+// ({"command": [...]}) joined by newlines, so patches passed to apply_patch
+// through the shell can be inspected.
+func commandText(arguments string) string {
+	var args struct {
+		Command []string `json:"command"`
+	}
+	if json.Unmarshal([]byte(arguments), &args) != nil {
+		return ""
+	}
+	return strings.Join(args.Command, "\n")
+}
+
+// parseOutput unwraps tool output, which Codex records either as plain text
+// or as JSON carrying the text and metadata such as the exit code.
+func parseOutput(raw string) (string, *int) {
+	var structured struct {
+		Output   string `json:"output"`
+		Metadata struct {
+			ExitCode *int `json:"exit_code"`
+		} `json:"metadata"`
+	}
+	if json.Unmarshal([]byte(raw), &structured) == nil && (structured.Output != "" || structured.Metadata.ExitCode != nil) {
+		return structured.Output, structured.Metadata.ExitCode
+	}
+	if code, ok := agent.ParseExitCode(raw); ok {
+		return raw, &code
+	}
+	return raw, nil
+}
@@ -20,9 +20,68 @@ type SessionParser interface {
 	FindLatestSession(repoRoot string) (path string, data *SessionData, err error)
 }
 
+// SessionMatcher is implemented by session parsers that can choose among
+// several open sessions by their content. The post-commit hook uses it to
+// find the session that produced a commit, given the files it changed.
+type SessionMatcher interface {
+	FindMatchingSession(repoRoot string, files []string) (path string, data *SessionData, match *SessionMatch, err error)
+}
+
 // PIDProvider is implemented by detectors that can report the OS process ID of
 // the running agent. The value is recorded on the session and used to verify
 // process liveness during stale-session cleanup.
 type PIDProvider interface {
 	AgentPID() (int, bool)
 }
+
+// SessionReader is implemented by session parsers that can parse a session
+// file whose path is already known, such as one reported by the agent's own
+// hooks.
+type SessionReader interface {
+	ReadSession(path string) (*SessionData, error)
+}
+
+// SessionLocator is implemented by detectors that can find a session file
+// from the agent's session ID, for agents whose hooks report only the ID.
+type SessionLocator interface {
+	SessionPath(sessionID string) (string, error)
+}
+
+// HookInstaller is implemented by detectors whose agent can report session
+// events to partio through its own hook configuration (see EventCommand).
+type HookInstaller interface {
+	// InstallHooks registers the event hooks, running the partio binary
+	// bin, in the agent's user-level configuration. It is idempotent and
+	// reports whether the configuration changed.
+	InstallHooks(bin string) (changed bool, err error)
+	// UninstallHooks removes the hooks InstallHooks added.
+	UninstallHooks() (changed bool, err error)
+}
+
+// SessionRestorer is implemented by detectors that can put a captured raw
+// transcript back where the agent keeps its sessions, so the agent can
+// continue the session.
+type SessionRestorer interface {
+	// RestoreSession writes transcript as session sessionID of repoRoot. A
+	// session already present is left alone: restored is false and path is
+	// its file.
+	RestoreSession(repoRoot, sessionID string, transcript []byte) (path string, restored bool, err error)
+	// ForkSession writes transcript like RestoreSession, but if sessionID
+	// is already present the copy gets a new session ID instead, leaving
+	// the original alone. It returns the ID the session was written under.
+	ForkSession(repoRoot, sessionID string, transcript []byte) (id, path string, err error)
+}
+
+// Launcher is implemented by detectors that can start the agent's CLI to
+// pick up earlier work (see `partio resume`).
+type Launcher interface {
+	// Executable returns the name of the agent's CLI, looked up in PATH.
+	Executable() string
+	// ResumeArgs returns the arguments that continue the agent's own
+	// session sessionID when launched from repoRoot, and false if the agent
+	// no longer has that session.
+	ResumeArgs(repoRoot, sessionID string) ([]string, bool)
+	// PromptArgs returns the arguments that start a new session told to
+	// read the context prompt in promptFile and continue that work.
+	PromptArgs(promptFile string) []string
+}
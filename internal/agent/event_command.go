@@ -0,0 +1,35 @@
+package agent
+
+import "strings"
+
+// eventCommand is the hidden partio command agents' hooks invoke.
+const eventCommand = "_agent-event"
+
+// EventCommand returns the shell command line an agent hook runs to report
+// event (e.g. "start") for agentName to the partio binary bin.
+func EventCommand(bin, agentName, event string) string {
+	args := EventArgs(bin, agentName, event)
+	args[0] = shellQuote(bin)
+	return strings.Join(args, " ")
+}
+
+// EventArgs is EventCommand split into arguments, for agents that run hooks
+// without a shell.
+func EventArgs(bin, agentName, event string) []string {
+	return []string{bin, eventCommand, event, "--agent", agentName}
+}
+
+// IsEventCommand reports whether an agent hook command was installed by
+// partio.
+func IsEventCommand(command string) bool {
+	return strings.Contains(command, eventCommand)
+}
+
+// shellQuote quotes s for a POSIX shell unless it is made of characters the
+// shell leaves alone, as a plain binary name or path usually is.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
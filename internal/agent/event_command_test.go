@@ -0,0 +1,25 @@
+package agent
+
+import "testing"
+
+func TestEventCommand(t *testing.T) {
+	tests := []struct {
+		bin  string
+		want string
+	}{
+		{"partio", "partio _agent-event stop --agent claude-code"},
+		{"/usr/local/bin/partio", "/usr/local/bin/partio _agent-event stop --agent claude-code"},
+		{"/Users/jane/My Tools/partio", "'/Users/jane/My Tools/partio' _agent-event stop --agent claude-code"},
+		{"/opt/it's/partio", `'/opt/it'\''s/partio' _agent-event stop --agent claude-code`},
+	}
+
+	for _, tt := range tests {
+		got := EventCommand(tt.bin, "claude-code", "stop")
+		if got != tt.want {
+			t.Errorf("EventCommand(%q) = %q, want %q", tt.bin, got, tt.want)
+		}
+		if !IsEventCommand(got) {
+			t.Errorf("IsEventCommand(%q) = false", got)
+		}
+	}
+}
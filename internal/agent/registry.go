@@ -2,6 +2,7 @@ package agent
 
 import (
 	"fmt"
+	"sort"
 )
 
 // NewDetectorFunc is a factory function that creates a Detector.
@@ -25,6 +26,20 @@ func NewDetector(name string) (Detector, error) {
 	return fn(), nil
 }
 
+// All returns a detector for every registered agent, ordered by name.
+func All() []Detector {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	detectors := make([]Detector, 0, len(names))
+	for _, name := range names {
+		detectors = append(detectors, registry[name]())
+	}
+	return detectors
+}
+
 // DetectActive checks all registered detectors and returns those that are
 // currently running. This allows capturing sessions from any active agent
 // without requiring PARTIO_AGENT to be set.
@@ -0,0 +1,179 @@
+package agent
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SessionMatch records why a session was associated with a commit, so a
+// wrong association can be diagnosed after the fact.
+type SessionMatch struct {
+	// Score ranks the session among the candidates; higher is better.
+	Score float64 `json:"score"`
+	// Reason explains the score, e.g. "edited 2 of 3 committed file(s),
+	// modified 1m ago, cwd in repository".
+	Reason string `json:"reason"`
+	// Candidates is the number of sessions that were considered.
+	Candidates int `json:"candidates"`
+}
+
+// SessionCandidate is a session that may have produced a commit.
+type SessionCandidate struct {
+	Path    string
+	Data    *SessionData
+	ModTime time.Time
+}
+
+// RankedSession is a candidate with its match against a commit.
+type RankedSession struct {
+	SessionCandidate
+	Match SessionMatch
+}
+
+// The parts of a match score. Editing the committed files dominates;
+// recency and the working directory only separate sessions that edited
+// the same share of them.
+const (
+	overlapWeight = 100
+	recencyWeight = 10
+	cwdWeight     = 5
+
+	// recencyHalfLife is how quickly the recency part decays with the
+	// time since the session file was modified.
+	recencyHalfLife = time.Hour
+)
+
+// readOnlyTools are tools that name files without changing them.
+var readOnlyTools = map[string]bool{
+	"Read":         true,
+	"NotebookRead": true,
+	"Glob":         true,
+	"Grep":         true,
+	"LS":           true,
+}
+
+// RankSessions scores each candidate against a commit of files (paths
+// relative to repoRoot, as reported by git) and returns the candidates
+// best match first. Ties go to the most recently modified session.
+func RankSessions(candidates []SessionCandidate, repoRoot string, files []string, now time.Time) []RankedSession {
+	roots := repoRoots(repoRoot)
+	committed := make(map[string]bool, len(files))
+	for _, f := range files {
+		committed[filepath.ToSlash(f)] = true
+	}
+
+	ranked := make([]RankedSession, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, RankedSession{
+			SessionCandidate: c,
+			Match:            matchSession(c, roots, committed, now),
+		})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Match.Score != ranked[j].Match.Score {
+			return ranked[i].Match.Score > ranked[j].Match.Score
+		}
+		return ranked[i].ModTime.After(ranked[j].ModTime)
+	})
+	for i := range ranked {
+		ranked[i].Match.Candidates = len(ranked)
+	}
+	return ranked
+}
+
+func matchSession(c SessionCandidate, roots []string, committed map[string]bool, now time.Time) SessionMatch {
+	var (
+		score   float64
+		reasons []string
+	)
+
+	if c.Data != nil && len(committed) > 0 {
+		edited := make(map[string]bool)
+		for _, f := range c.Data.EditedFiles() {
+			if rel := repoRelative(roots, c.Data.CWD, f); committed[rel] {
+				edited[rel] = true
+			}
+		}
+		score += overlapWeight * float64(len(edited)) / float64(len(committed))
+		reasons = append(reasons, fmt.Sprintf("edited %d of %d committed file(s)", len(edited), len(committed)))
+	}
+
+	if !c.ModTime.IsZero() {
+		age := max(now.Sub(c.ModTime), 0)
+		score += recencyWeight * math.Exp2(-float64(age)/float64(recencyHalfLife))
+		reasons = append(reasons, fmt.Sprintf("modified %s ago", age.Round(time.Second)))
+	}
+
+	if c.Data != nil && c.Data.CWD != "" {
+		if repoRelative(roots, "", c.Data.CWD) != "" {
+			score += cwdWeight
+			reasons = append(reasons, "cwd in repository")
+		} else {
+			reasons = append(reasons, "cwd outside repository")
+		}
+	}
+
+	return SessionMatch{
+		Score:  math.Round(score*100) / 100,
+		Reason: strings.Join(reasons, ", "),
+	}
+}
+
+// EditedFiles returns the files the session's tool calls, including those
+// of its threads, may have changed, as the agent named them.
+func (d *SessionData) EditedFiles() []string {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(calls []ToolCall) {
+		for _, c := range calls {
+			if readOnlyTools[c.Name] {
+				continue
+			}
+			for _, f := range c.FilesTouched {
+				if !seen[f] {
+					seen[f] = true
+					files = append(files, f)
+				}
+			}
+		}
+	}
+	add(d.ToolCalls)
+	for _, t := range d.Threads {
+		add(t.ToolCalls)
+	}
+	return files
+}
+
+// repoRoots returns repoRoot and, when it differs, the path it resolves to,
+// since agents may record either.
+func repoRoots(repoRoot string) []string {
+	roots := []string{filepath.Clean(repoRoot)}
+	if resolved, err := filepath.EvalSymlinks(repoRoot); err == nil && resolved != roots[0] {
+		roots = append(roots, resolved)
+	}
+	return roots
+}
+
+// repoRelative returns path relative to the repository, in git's
+// slash-separated form, or "" if it lies outside it. A relative path is
+// taken relative to cwd, or to the repository root when cwd is empty. The
+// root itself is returned as ".".
+func repoRelative(roots []string, cwd, path string) string {
+	if !filepath.IsAbs(path) {
+		if cwd == "" {
+			return filepath.ToSlash(filepath.Clean(path))
+		}
+		path = filepath.Join(cwd, path)
+	}
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRankSessions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	edits := func(cwd string, files ...string) *SessionData {
+		return &SessionData{
+			CWD: cwd,
+			ToolCalls: []ToolCall{
+				{Name: "Read", FilesTouched: []string{"/repo/README.md"}},
+				{Name: "Edit", FilesTouched: files},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		candidates []SessionCandidate
+		files      []string
+		want       string
+		wantReason string
+	}{
+		{
+			name: "overlap beats recency",
+			candidates: []SessionCandidate{
+				{Path: "new", Data: edits("/repo", "/repo/other.go"), ModTime: now},
+				{Path: "old", Data: edits("/repo", "/repo/main.go", "/repo/util.go"), ModTime: now.Add(-2 * time.Hour)},
+			},
+			files:      []string{"main.go", "util.go"},
+			want:       "old",
+			wantReason: "edited 2 of 2 committed file(s), modified 2h0m0s ago, cwd in repository",
+		},
+		{
+			name: "relative paths resolve against cwd",
+			candidates: []SessionCandidate{
+				{Path: "root", Data: edits("/repo", "lib/a.go"), ModTime: now},
+				{Path: "parent", Data: edits("/", "repo/pkg/a.go"), ModTime: now.Add(-time.Minute)},
+			},
+			files:      []string{"pkg/a.go"},
+			want:       "parent",
+			wantReason: "edited 1 of 1 committed file(s), modified 1m0s ago, cwd outside repository",
+		},
+		{
+			name: "reads do not count",
+			candidates: []SessionCandidate{
+				{Path: "reader", Data: edits("/repo"), ModTime: now},
+				{Path: "thread", Data: &SessionData{CWD: "/repo", Threads: []Thread{{ToolCalls: []ToolCall{{Name: "Write", FilesTouched: []string{"/repo/README.md"}}}}}}, ModTime: now.Add(-time.Hour)},
+			},
+			files: []string{"README.md"},
+			want:  "thread",
+		},
+		{
+			name: "no overlap falls back to recency",
+			candidates: []SessionCandidate{
+				{Path: "older", Data: edits("/repo", "/repo/a.go"), ModTime: now.Add(-time.Hour)},
+				{Path: "newer", Data: edits("/repo", "/repo/b.go"), ModTime: now},
+			},
+			files:      []string{"c.go"},
+			want:       "newer",
+			wantReason: "edited 0 of 1 committed file(s), modified 0s ago, cwd in repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := RankSessions(tt.candidates, "/repo", tt.files, now)
+			if len(ranked) != len(tt.candidates) {
+				t.Fatalf("got %d ranked sessions, want %d", len(ranked), len(tt.candidates))
+			}
+			best := ranked[0]
+			if best.Path != tt.want {
+				t.Errorf("best = %s (%+v), want %s", best.Path, best.Match, tt.want)
+			}
+			if tt.wantReason != "" && best.Match.Reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", best.Match.Reason, tt.wantReason)
+			}
+			if best.Match.Candidates != len(tt.candidates) {
+				t.Errorf("candidates = %d", best.Match.Candidates)
+			}
+			if best.Match.Score < ranked[1].Match.Score {
+				t.Errorf("ranking not sorted: %+v", ranked)
+			}
+		})
+	}
+}
+
+func TestEditedFiles(t *testing.T) {
+	d := &SessionData{
+		ToolCalls: []ToolCall{
+			{Name: "Grep", FilesTouched: []string{"src"}},
+			{Name: "Edit", FilesTouched: []string{"a.go"}},
+			{Name: "apply_patch", FilesTouched: []string{"a.go", "b.go"}},
+		},
+	}
+	if got := strings.Join(d.EditedFiles(), ","); got != "a.go,b.go" {
+		t.Errorf("EditedFiles = %s, want a.go,b.go", got)
+	}
+}
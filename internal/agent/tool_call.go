@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ToolCall is a single tool invocation made by an agent, normalized across
+// agents: a file edit, a shell command, a search, and so on.
+type ToolCall struct {
+	// ID is the agent's identifier for the call, used to match its result.
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	// Input holds the call's arguments as JSON.
+	Input json.RawMessage `json:"input,omitempty"`
+	// ResultSummary is the start of the call's output.
+	ResultSummary string `json:"result_summary,omitempty"`
+	// FilesTouched lists the files the call read or wrote, as the agent
+	// named them.
+	FilesTouched []string `json:"files_touched,omitempty"`
+	// ExitCode is set for shell commands whose exit status is known.
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	IsError   bool      `json:"is_error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// maxResultSummary bounds ToolCall.ResultSummary, in bytes.
+const maxResultSummary = 500
+
+// SummarizeResult trims tool output to a short summary.
+func SummarizeResult(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) <= maxResultSummary {
+		return output
+	}
+	cut := maxResultSummary
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	return output[:cut] + "..."
+}
+
+// exitCodeRe matches the exit status agents prefix to failed command output,
+// e.g. "Error: Exit code 1" (Claude) or "Exit code: 1" (Codex).
+var exitCodeRe = regexp.MustCompile(`^(?:Error: )?Exit code:? (-?\d+)`)
+
+// ParseExitCode extracts a leading exit status from tool output.
+func ParseExitCode(output string) (int, bool) {
+	m := exitCodeRe.FindStringSubmatch(strings.TrimSpace(output))
+	if m == nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return code, true
+}
+
+// patchFileRe matches the file headers of an apply_patch style patch.
+var patchFileRe = regexp.MustCompile(`(?m)^\*\*\* (?:Add File|Update File|Delete File|Move to): (.+)$`)
+
+// PatchFiles returns the files named in an apply_patch style patch
+// ("*** Update File: path"), in order of first appearance.
+func PatchFiles(patch string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, m := range patchFileRe.FindAllStringSubmatch(patch, -1) {
+		path := strings.TrimSpace(m[1])
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	return files
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExitCode(t *testing.T) {
+	tests := []struct {
+		output string
+		want   int
+		ok     bool
+	}{
+		{"Error: Exit code 2\nmake: *** [test] Error 2", 2, true},
+		{"Exit code: 0\nWall time: 0.1 seconds", 0, true},
+		{"Exit code 127", 127, true},
+		{"all tests passed", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseExitCode(tt.output)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseExitCode(%q) = (%d, %v), want (%d, %v)", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPatchFiles(t *testing.T) {
+	patch := `*** Begin Patch
+*** Update File: src/app.go
+@@
+-old
++new
+*** Add File: src/new.go
++package src
+*** Update File: src/app.go
+*** Move to: src/main.go
+*** Delete File: old.txt
+*** End Patch`
+
+	want := []string{"src/app.go", "src/new.go", "src/main.go", "old.txt"}
+	if got := PatchFiles(patch); !reflect.DeepEqual(got, want) {
+		t.Errorf("PatchFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestSummarizeResult(t *testing.T) {
+	if got := SummarizeResult("  ok\n"); got != "ok" {
+		t.Errorf("SummarizeResult(short) = %q", got)
+	}
+	long := strings.Repeat("é", maxResultSummary)
+	got := SummarizeResult(long)
+	if !strings.HasSuffix(got, "...") || len(got) > maxResultSummary+3 || !strings.HasPrefix(long, strings.TrimSuffix(got, "...")) {
+		t.Errorf("SummarizeResult(long) = %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TranscriptVersion is the version of the normalized transcript format
+// written by Normalize.
+const TranscriptVersion = 1
+
+// Transcript is partio's agent-neutral form of a session. It is stored as
+// transcript.json next to the agent's raw session file so that tools can read
+// sessions of any agent without knowing its log format.
+type Transcript struct {
+	Version   int        `json:"version"`
+	Agent     string     `json:"agent"`
+	SessionID string     `json:"session_id,omitempty"`
+	Models    []string   `json:"models,omitempty"`
+	StartedAt time.Time  `json:"started_at,omitzero"`
+	EndedAt   time.Time  `json:"ended_at,omitzero"`
+	Tokens    TokenUsage `json:"tokens"`
+	Messages  []Message  `json:"messages"`
+	// ToolCalls lists every tool call in order; messages reference them
+	// by ID.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// Threads holds side conversations such as sub-agents.
+	Threads []Thread `json:"threads,omitempty"`
+}
+
+// Normalize returns the session as a normalized transcript.
+func (d *SessionData) Normalize() *Transcript {
+	t := &Transcript{
+		Version:   TranscriptVersion,
+		Agent:     d.Agent,
+		SessionID: d.SessionID,
+		Models:    d.Models(),
+		Tokens:    d.Tokens,
+		Messages:  make([]Message, len(d.Transcript)),
+		ToolCalls: d.ToolCalls,
+		Threads:   d.Threads,
+	}
+	if len(t.Models) == 0 {
+		t.Models = nil
+	}
+	for i, m := range d.Transcript {
+		// Roles are "user" and "assistant" whatever the agent calls them.
+		if m.Role == "human" {
+			m.Role = "user"
+		}
+		t.Messages[i] = m
+	}
+	if !d.StartedAt.IsZero() {
+		t.StartedAt = d.StartedAt
+		t.EndedAt = d.StartedAt.Add(d.Duration)
+	}
+	return t
+}
+
+// ParseTranscript decodes a transcript.json file. Transcripts written by a
+// newer version of partio are rejected rather than misread.
+func ParseTranscript(data []byte) (*Transcript, error) {
+	var t Transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("parsing transcript: %w", err)
+	}
+	if t.Version > TranscriptVersion {
+		return nil, fmt.Errorf("transcript version %d is newer than supported version %d", t.Version, TranscriptVersion)
+	}
+	return &t, nil
+}
+
+// ToolCall returns the call with the given ID.
+func (t *Transcript) ToolCall(id string) (ToolCall, bool) {
+	for _, c := range t.ToolCalls {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return ToolCall{}, false
+}
+
+// LastMessage returns the last message with the given role that has text.
+func (t *Transcript) LastMessage(role string) (Message, bool) {
+	for i := len(t.Messages) - 1; i >= 0; i-- {
+		if m := t.Messages[i]; m.Role == role && m.Content != "" {
+			return m, true
+		}
+	}
+	return Message{}, false
+}
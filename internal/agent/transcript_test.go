@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &SessionData{
+		SessionID: "s1",
+		Agent:     "claude-code",
+		StartedAt: start,
+		Duration:  time.Minute,
+		Transcript: []Message{
+			{Role: "human", Content: "Fix the bug"},
+			{Role: "assistant", Content: "Done.", ToolCallIDs: []string{"t1"}},
+		},
+		ToolCalls: []ToolCall{{ID: "t1", Name: "Edit"}},
+	}
+	d.AddUsage("claude-sonnet-4-5", TokenUsage{Input: 10, Output: 5})
+
+	tr := d.Normalize()
+	if tr.Version != TranscriptVersion || tr.Agent != "claude-code" || tr.SessionID != "s1" {
+		t.Errorf("header = %+v", tr)
+	}
+	if !tr.StartedAt.Equal(start) || !tr.EndedAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("times = %v, %v", tr.StartedAt, tr.EndedAt)
+	}
+	if len(tr.Models) != 1 || tr.Models[0] != "claude-sonnet-4-5" || tr.Tokens.Total() != 15 {
+		t.Errorf("models = %v, tokens = %+v", tr.Models, tr.Tokens)
+	}
+	if tr.Messages[0].Role != "user" {
+		t.Errorf("role = %q, want user", tr.Messages[0].Role)
+	}
+	if d.Transcript[0].Role != "human" {
+		t.Error("Normalize modified the session data")
+	}
+	if c, ok := tr.ToolCall("t1"); !ok || c.Name != "Edit" {
+		t.Errorf("ToolCall(t1) = %+v, %v", c, ok)
+	}
+	if m, ok := tr.LastMessage("assistant"); !ok || m.Content != "Done." {
+		t.Errorf("LastMessage = %+v, %v", m, ok)
+	}
+
+	raw, err := json.Marshal(tr)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	back, err := ParseTranscript(raw)
+	if err != nil {
+		t.Fatalf("ParseTranscript: %v", err)
+	}
+	if len(back.Messages) != 2 || back.Messages[1].ToolCallIDs[0] != "t1" {
+		t.Errorf("round trip = %+v", back)
+	}
+}
+
+func TestNormalizeEmpty(t *testing.T) {
+	raw, err := json.Marshal((&SessionData{Agent: "codex"}).Normalize())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"version":1,"agent":"codex","tokens":{"input":0,"output":0,"cache_creation":0,"cache_read":0},"messages":[]}`
+	if string(raw) != want {
+		t.Errorf("got %s\nwant %s", raw, want)
+	}
+}
+
+func TestParseTranscriptNewerVersion(t *testing.T) {
+	if _, err := ParseTranscript([]byte(`{"version":99,"agent":"x"}`)); err == nil {
+		t.Error("expected an error for a newer transcript version")
+	}
+	if _, err := ParseTranscript([]byte(`not json`)); err == nil {
+		t.Error("expected an error for malformed JSON")
+	}
+}
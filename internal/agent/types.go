@@ -12,6 +12,42 @@ type SessionData struct {
 	TotalTokens int           `json:"total_tokens"`
 	Duration    time.Duration `json:"duration"`
 	PlanSlug    string        `json:"plan_slug,omitempty"`
+
+	// CWD is the agent's working directory, the last one recorded when
+	// it changed during the session.
+	CWD string `json:"cwd,omitempty"`
+
+	// StartedAt is the time of the session's first recorded event.
+	StartedAt time.Time `json:"started_at"`
+
+	// Tokens breaks TotalTokens down by kind; ModelTokens further splits it
+	// by model name. Both are maintained by AddUsage.
+	Tokens      TokenUsage            `json:"tokens"`
+	ModelTokens map[string]TokenUsage `json:"model_tokens,omitempty"`
+
+	// ToolCalls lists the tools the agent invoked, in order.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// Threads holds side conversations, such as sub-agents, kept apart
+	// from the main transcript.
+	Threads []Thread `json:"threads,omitempty"`
+
+	// SourceFiles lists the agent files the session was read from, in
+	// order, when it spans more than the file returned by
+	// FindLatestSession (e.g. a resumed or compacted session).
+	SourceFiles []string `json:"source_files,omitempty"`
+}
+
+// Thread is a side conversation within a session, such as a sub-agent the
+// main agent delegated a task to. Its token usage is counted in the
+// session's totals.
+type Thread struct {
+	ID string `json:"id"`
+	// ToolCallID is the call in the main transcript that started the
+	// thread, when known.
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+	Messages   []Message  `json:"messages"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 }
 
 // Message represents a single message in an agent transcript.
@@ -20,4 +56,24 @@ type Message struct {
 	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
 	Tokens    int       `json:"tokens,omitempty"`
+	Model     string    `json:"model,omitempty"`
+
+	// Thinking holds the model's reasoning blocks, when the agent records
+	// them.
+	Thinking []string `json:"thinking,omitempty"`
+	// ToolCallIDs references the calls in SessionData.ToolCalls made by
+	// this message.
+	ToolCallIDs []string     `json:"tool_call_ids,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment describes a non-text input attached to a message, such as an
+// image or a document. Only the description is kept, never the content.
+type Attachment struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	// Name is the attachment's file name, title or URL, when known.
+	Name string `json:"name,omitempty"`
+	// Size is the decoded size in bytes of inline content.
+	Size int `json:"size,omitempty"`
 }
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"sort"
+
+	"github.com/partio-io/cli/internal/pricing"
+)
+
+// TokenUsage counts the tokens an agent consumed, split the way providers
+// bill them.
+type TokenUsage struct {
+	Input         int `json:"input"`
+	Output        int `json:"output"`
+	CacheCreation int `json:"cache_creation"`
+	CacheRead     int `json:"cache_read"`
+}
+
+// Total returns the sum of all token counts.
+func (t TokenUsage) Total() int {
+	return t.Input + t.Output + t.CacheCreation + t.CacheRead
+}
+
+// Add accumulates u into t.
+func (t *TokenUsage) Add(u TokenUsage) {
+	t.Input += u.Input
+	t.Output += u.Output
+	t.CacheCreation += u.CacheCreation
+	t.CacheRead += u.CacheRead
+}
+
+// Cost prices t as tokens of model, in USD. It reports false when table has
+// no price for the model.
+func (t TokenUsage) Cost(table pricing.Table, model string) (float64, bool) {
+	price, ok := table.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return price.Cost(t.Input, t.Output, t.CacheCreation, t.CacheRead), true
+}
+
+// AddUsage records tokens consumed by model, updating the session totals.
+// An empty model name is recorded as "unknown".
+func (d *SessionData) AddUsage(model string, u TokenUsage) {
+	if model == "" {
+		model = "unknown"
+	}
+	if d.ModelTokens == nil {
+		d.ModelTokens = make(map[string]TokenUsage)
+	}
+	m := d.ModelTokens[model]
+	m.Add(u)
+	d.ModelTokens[model] = m
+	d.Tokens.Add(u)
+	d.TotalTokens = d.Tokens.Total()
+}
+
+// Models returns the names of the models used in the session, sorted.
+func (d *SessionData) Models() []string {
+	models := make([]string, 0, len(d.ModelTokens))
+	for m := range d.ModelTokens {
+		models = append(models, m)
+	}
+	sort.Strings(models)
+	return models
+}
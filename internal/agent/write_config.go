@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteConfigFile replaces an agent's configuration file with data by
+// writing a temporary file next to it and renaming it into place, so an
+// interrupted write never leaves the user's settings truncated. A symlinked
+// file (e.g. settings kept in a dotfiles repository) is written through, so
+// the link stays in place. The file keeps its permissions; a new one is
+// created with 0644.
+func WriteConfigFile(path string, data []byte) error {
+	target, err := resolveSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	path = target
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// resolveSymlinks returns the file path finally refers to. Unlike
+// filepath.EvalSymlinks, it also follows a link to a file that does not
+// exist yet.
+func resolveSymlinks(path string) (string, error) {
+	for range 40 {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return resolved, nil
+		}
+		info, lerr := os.Lstat(path)
+		if lerr != nil {
+			// Nothing there yet: write a new file.
+			return path, nil
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return "", err
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic links")
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteConfigFileSymlink(t *testing.T) {
+	dir := t.TempDir()
+	dotfiles := filepath.Join(dir, "dotfiles")
+	if err := os.Mkdir(dotfiles, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(dotfiles, "settings.json")
+	if err := os.WriteFile(real, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "settings.json")
+	if err := os.Symlink(filepath.Join("dotfiles", "settings.json"), link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteConfigFile(link, []byte(`{"hooks":{}}`)); err != nil {
+		t.Fatalf("WriteConfigFile: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("settings.json is no longer a symlink (%v)", err)
+	}
+	data, err := os.ReadFile(real)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"hooks":{}}` {
+		t.Errorf("link target = %q", data)
+	}
+	if info, _ := os.Stat(real); info.Mode().Perm() != 0o600 {
+		t.Errorf("link target mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// A link to a file that does not exist yet creates the file.
+	dangling := filepath.Join(dir, "config.toml")
+	if err := os.Symlink(filepath.Join(dotfiles, "config.toml"), dangling); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConfigFile(dangling, []byte("x = 1\n")); err != nil {
+		t.Fatalf("WriteConfigFile: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dotfiles, "config.toml")); err != nil || string(data) != "x = 1\n" {
+		t.Errorf("dangling link target = %q, %v", data, err)
+	}
+}
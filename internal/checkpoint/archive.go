@@ -0,0 +1,229 @@
+package checkpoint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// archiveRef is the ref name recorded in archive bundles. It only exists
+// locally while a bundle is being written or read.
+const archiveRef = "refs/partio/archive"
+
+// Archive writes the given checkpoints to a git bundle at out and then
+// removes them from the checkpoint branch. The bundle holds a single
+// parentless commit with the same <shard>/<rest> layout as the branch, plus
+// the working-tree snapshot refs of archived manual checkpoints, so it can be
+// restored into any clone with RestoreArchive, or inspected with
+// `git bundle list-heads` and `git fetch`. out must not already exist.
+func (s *Store) Archive(ids []string, out string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := s.requireMigrated(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(out); err == nil {
+		return fmt.Errorf("%s already exists", out)
+	}
+	out, err := filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+
+	changes := make(map[string]string, len(ids))
+	var trailers []string
+	refs := []string{archiveRef}
+	for _, id := range ids {
+		cpTree, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch+":"+Shard(id)+"/"+Rest(id))
+		if err != nil {
+			return fmt.Errorf("checkpoint %s not found", id)
+		}
+		changes[id] = cpTree
+		if manifest := s.readManifest(cpTree); manifest != "" {
+			trailers = append(trailers, manifestTrailerLine(id, sha256Hex(manifest)))
+		}
+		if _, err := s.git("rev-parse", "--verify", "--quiet", SnapshotRef(id)); err == nil {
+			refs = append(refs, SnapshotRef(id))
+		}
+	}
+	sort.Strings(trailers)
+
+	emptyTree, err := s.mktree(nil)
+	if err != nil {
+		return fmt.Errorf("creating empty tree: %w", err)
+	}
+	tree, err := s.applyChanges(emptyTree, changes)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("archive: %d checkpoint(s)", len(ids))
+	if len(trailers) > 0 {
+		msg += "\n\n" + strings.Join(trailers, "\n")
+	}
+	commit, err := s.commitTree(tree, "", msg)
+	if err != nil {
+		return fmt.Errorf("creating archive commit: %w", err)
+	}
+
+	if _, err := s.git("update-ref", archiveRef, commit); err != nil {
+		return fmt.Errorf("updating ref: %w", err)
+	}
+	defer func() { _, _ = s.git("update-ref", "-d", archiveRef) }()
+
+	if _, err := s.git(append([]string{"bundle", "create", "-q", out}, refs...)...); err != nil {
+		_ = os.Remove(out)
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	// Never drop checkpoints unless the bundle can be read back.
+	if _, err := s.git("bundle", "verify", "-q", out); err != nil {
+		_ = os.Remove(out)
+		return fmt.Errorf("verifying bundle: %w", err)
+	}
+
+	for id := range changes {
+		changes[id] = ""
+	}
+	msg = fmt.Sprintf("archive: moved %d checkpoint(s) to %s", len(ids), filepath.Base(out))
+	return s.updateCheckpoints(changes, msg, removedTrailerLines(ids, "archived to "+filepath.Base(out)))
+}
+
+// ArchivedIDs lists the checkpoints stored in an archive bundle.
+func (s *Store) ArchivedIDs(file string) ([]string, error) {
+	tree, cleanup, err := s.fetchArchive(file)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	entries, err := s.treeEntries(tree)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.id)
+	}
+	return ids, nil
+}
+
+// RestoreArchive copies checkpoints from an archive bundle back onto the
+// checkpoint branch in a single commit. With no ids, every archived
+// checkpoint is restored, along with the snapshot refs of restored manual
+// checkpoints. Checkpoints already on the branch are skipped. It returns the
+// IDs restored.
+func (s *Store) RestoreArchive(file string, ids []string) ([]string, error) {
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch); err != nil {
+		return nil, fmt.Errorf("checkpoint branch does not exist")
+	}
+
+	tree, cleanup, err := s.fetchArchive(file)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if len(ids) == 0 {
+		entries, err := s.treeEntries(tree)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			ids = append(ids, e.id)
+		}
+	}
+
+	changes := make(map[string]string)
+	var restored, trailers []string
+	for _, id := range ids {
+		path := Shard(id) + "/" + Rest(id)
+		cpTree, err := s.git("rev-parse", "--verify", "--quiet", tree+":"+path)
+		if err != nil {
+			return nil, fmt.Errorf("checkpoint %s is not in %s", id, file)
+		}
+		if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch+":"+path); err == nil {
+			continue // already on the branch
+		}
+		changes[id] = cpTree
+		restored = append(restored, id)
+		if manifest := s.readManifest(cpTree); manifest != "" {
+			trailers = append(trailers, manifestTrailerLine(id, sha256Hex(manifest)))
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	sort.Strings(trailers)
+
+	if err := s.restoreSnapshots(file, restored); err != nil {
+		return nil, err
+	}
+
+	msg := fmt.Sprintf("archive: restored %d checkpoint(s) from %s", len(changes), filepath.Base(file))
+	if err := s.updateCheckpoints(changes, msg, trailers); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// fetchArchive imports an archive bundle's objects and returns its tree. The
+// returned cleanup removes the temporary ref.
+func (s *Store) fetchArchive(file string) (tree string, cleanup func(), err error) {
+	file, err = filepath.Abs(file)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := s.git("bundle", "verify", "-q", file); err != nil {
+		return "", nil, fmt.Errorf("%s is not a valid archive bundle", file)
+	}
+
+	if _, err := s.git("fetch", "-q", "--no-write-fetch-head", file, "+"+archiveRef+":"+archiveRef); err != nil {
+		return "", nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	cleanup = func() { _, _ = s.git("update-ref", "-d", archiveRef) }
+
+	tree, err = s.git("rev-parse", archiveRef+"^{tree}")
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("reading archive tree: %w", err)
+	}
+	return tree, cleanup, nil
+}
+
+// restoreSnapshots recreates the snapshot refs the archive bundle file holds
+// for the checkpoints ids.
+func (s *Store) restoreSnapshots(file string, ids []string) error {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	heads, err := s.git("bundle", "list-heads", file)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", file, err)
+	}
+	inBundle := make(map[string]bool)
+	for _, line := range strings.Split(heads, "\n") {
+		if _, ref, ok := strings.Cut(line, " "); ok {
+			inBundle[ref] = true
+		}
+	}
+
+	var refspecs []string
+	for _, id := range ids {
+		if ref := SnapshotRef(id); inBundle[ref] {
+			refspecs = append(refspecs, "+"+ref+":"+ref)
+		}
+	}
+	if len(refspecs) == 0 {
+		return nil
+	}
+	args := append([]string{"fetch", "-q", "--no-write-fetch-head", file}, refspecs...)
+	if _, err := s.git(args...); err != nil {
+		return fmt.Errorf("restoring snapshots from %s: %w", file, err)
+	}
+	return nil
+}
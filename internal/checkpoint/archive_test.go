@@ -0,0 +1,96 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiveRestore(t *testing.T) {
+	s := initCheckpointRepo(t)
+	writeTestCheckpoint(t, s, "ff0000000001")
+	writeTestCheckpoint(t, s, "ff0000000002")
+	writeTestCheckpoint(t, s, "ab0000000003")
+	if err := os.WriteFile(filepath.Join(s.repoRoot, "notes.txt"), []byte("wip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := s.Snapshot("ff0000000001", "", "snapshot")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "archive.bundle")
+	if err := s.Archive([]string{"ff0000000001", "ab0000000003"}, out); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+
+	ids, _ := s.IDs()
+	if strings.Join(ids, " ") != "ff0000000002" {
+		t.Errorf("IDs after archive = %v, want [ff0000000002]", ids)
+	}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", archiveRef); err == nil {
+		t.Error("temporary archive ref left behind")
+	}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", SnapshotRef("ff0000000001")); err == nil {
+		t.Error("snapshot ref of an archived checkpoint kept")
+	}
+
+	if err := s.Archive([]string{"ff0000000002"}, out); err == nil {
+		t.Error("expected error when archive file already exists")
+	}
+
+	archived, err := s.ArchivedIDs(out)
+	if err != nil {
+		t.Fatalf("ArchivedIDs: %v", err)
+	}
+	if strings.Join(archived, " ") != "ab0000000003 ff0000000001" {
+		t.Errorf("ArchivedIDs = %v", archived)
+	}
+
+	// Any clone can import the bundle.
+	other := initCheckpointRepo(t)
+	restored, err := other.RestoreArchive(out, nil)
+	if err != nil {
+		t.Fatalf("RestoreArchive into another repo: %v", err)
+	}
+	if strings.Join(restored, " ") != "ab0000000003 ff0000000001" {
+		t.Errorf("restored into another repo = %v", restored)
+	}
+	if got, _ := other.git("rev-parse", "--verify", "--quiet", SnapshotRef("ff0000000001")); got != snap {
+		t.Errorf("restored snapshot ref = %q, want %s", got, snap)
+	}
+
+	restored, err = s.RestoreArchive(out, []string{"ab0000000003"})
+	if err != nil {
+		t.Fatalf("RestoreArchive: %v", err)
+	}
+	if strings.Join(restored, " ") != "ab0000000003" {
+		t.Errorf("restored = %v", restored)
+	}
+
+	restored, err = s.RestoreArchive(out, nil)
+	if err != nil {
+		t.Fatalf("RestoreArchive: %v", err)
+	}
+	if strings.Join(restored, " ") != "ff0000000001" {
+		t.Errorf("restored = %v, want only the checkpoint not yet restored", restored)
+	}
+
+	if _, err := s.RestoreArchive(out, []string{"ffffffffffff"}); err == nil {
+		t.Error("expected error restoring a checkpoint not in the archive")
+	}
+
+	results, err := s.Verify(nil, false)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 checkpoints after restore, got %d", len(results))
+	}
+	for _, r := range results {
+		if checkStatus(r, "files") != CheckOK || checkStatus(r, "manifest") != CheckOK {
+			t.Errorf("%s does not verify after restore: %+v", r.ID, r.Checks)
+		}
+	}
+}
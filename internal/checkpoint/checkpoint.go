@@ -6,30 +6,82 @@ import (
 	"time"
 )
 
+// SchemaVersion is the checkpoint metadata schema written by this version.
+// Metadata without a schema_version field is version 1.
+const SchemaVersion = 2
+
 // Checkpoint represents a captured point-in-time snapshot.
 type Checkpoint struct {
-	ID          string    `json:"id"`
-	SessionID   string    `json:"session_id"`
-	CommitHash  string    `json:"commit_hash"`
-	Branch      string    `json:"branch"`
-	CreatedAt   time.Time `json:"created_at"`
-	Agent       string    `json:"agent"`
-	AgentPct    int       `json:"agent_percent"`
-	ContentHash string    `json:"content_hash"`
-	PlanSlug    string    `json:"plan_slug,omitempty"`
+	ID            string    `json:"id"`
+	SessionID     string    `json:"session_id"`
+	CommitHash    string    `json:"commit_hash"`
+	Branch        string    `json:"branch"`
+	CreatedAt     time.Time `json:"created_at"`
+	Agent         string    `json:"agent"`
+	AgentPct      int       `json:"agent_percent"`
+	ContentHash   string    `json:"content_hash"`
+	PlanSlug      string    `json:"plan_slug,omitempty"`
+	Author        string    `json:"author,omitempty"`
+	PartioVersion string    `json:"partio_version,omitempty"`
+	HookStrategy  string    `json:"hook_strategy,omitempty"`
+	// Manual checkpoints are taken with `partio checkpoint` rather than
+	// by a commit; CommitHash is the HEAD they were taken on.
+	Manual bool   `json:"manual,omitempty"`
+	Note   string `json:"note,omitempty"`
+	// Snapshot is the commit recording the working tree (see Store.Snapshot).
+	Snapshot string `json:"snapshot,omitempty"`
 }
 
 // Metadata is the JSON schema for checkpoint metadata stored on the orphan branch.
 type Metadata struct {
-	ID           string `json:"id"`
-	SessionID    string `json:"session_id"`
-	CommitHash   string `json:"commit_hash"`
-	Branch       string `json:"branch"`
-	CreatedAt    string `json:"created_at"`
-	Agent        string `json:"agent"`
-	AgentPercent int    `json:"agent_percent"`
-	ContentHash  string `json:"content_hash"`
-	PlanSlug     string `json:"plan_slug,omitempty"`
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	ID            string `json:"id"`
+	SessionID     string `json:"session_id"`
+	CommitHash    string `json:"commit_hash"`
+	Branch        string `json:"branch"`
+	CreatedAt     string `json:"created_at"`
+	Agent         string `json:"agent"`
+	AgentPercent  int    `json:"agent_percent"`
+	ContentHash   string `json:"content_hash"`
+	PlanSlug      string `json:"plan_slug,omitempty"`
+	// Author is the commit author as "Name <email>".
+	Author        string `json:"author,omitempty"`
+	PartioVersion string `json:"partio_version,omitempty"`
+	// HookStrategy is the capture strategy in effect (e.g. "manual-commit").
+	HookStrategy string `json:"hook_strategy,omitempty"`
+	// Sessions lists every session captured in the checkpoint, in
+	// subdirectory order.
+	Sessions []SessionRef `json:"sessions,omitempty"`
+	// Encrypted is true when the session files are encrypted; metadata files
+	// are always stored in clear.
+	Encrypted bool `json:"encrypted,omitempty"`
+	// Manual is true for checkpoints taken without a commit, whose working
+	// tree is recorded in the Snapshot commit on top of CommitHash.
+	Manual   bool   `json:"manual,omitempty"`
+	Note     string `json:"note,omitempty"`
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// upgrade fills in the v2 fields of metadata read from an older schema,
+// using the session metadata stored alongside it. SchemaVersion is left as
+// stored (1 when absent) so callers can tell which schema was on disk.
+func (m *Metadata) upgrade(sessions []SessionMetadata) {
+	if m.SchemaVersion == 0 {
+		m.SchemaVersion = 1
+	}
+	if len(m.Sessions) > 0 {
+		return
+	}
+	for i, sm := range sessions {
+		ref := sm.ref(i)
+		if ref.SessionID == "" && i == 0 {
+			ref.SessionID = m.SessionID
+		}
+		if ref.Agent == "" {
+			ref.Agent = m.Agent
+		}
+		m.Sessions = append(m.Sessions, ref)
+	}
 }
 
 // NewID generates a 12-character hex checkpoint ID.
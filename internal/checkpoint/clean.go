@@ -0,0 +1,94 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// CleanOptions controls which orphaned checkpoints Clean removes.
+type CleanOptions struct {
+	// IncludeRemoteRefs also treats commits reachable from remote-tracking
+	// branches as live, keeping their checkpoints.
+	IncludeRemoteRefs bool
+	// GracePeriod keeps orphaned checkpoints younger than this, so work that
+	// is mid-rebase or not yet on a branch isn't lost.
+	GracePeriod time.Duration
+	// DryRun reports what would be removed without changing the branch.
+	DryRun bool
+}
+
+// CleanResult holds the outcome of a clean operation.
+type CleanResult struct {
+	Removed []Metadata
+	// Recent lists orphaned checkpoints kept because of the grace period.
+	Recent []Metadata
+	Kept   int
+}
+
+// Clean removes checkpoints whose commit is unreachable from local branches,
+// tags and HEAD (plus remote-tracking branches with IncludeRemoteRefs). A
+// checkpoint is still considered linked when its stored commit was rewritten
+// but a reachable commit carries its Partio-Checkpoint trailer. All removals
+// happen in a single checkpoint branch commit.
+func (s *Store) Clean(opts CleanOptions) (*CleanResult, error) {
+	result := &CleanResult{}
+	if err := s.requireMigrated(); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.entries()
+	if err != nil || len(stored) == 0 {
+		return result, err
+	}
+
+	revArgs := []string{"--branches", "--tags"}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		revArgs = append(revArgs, "HEAD")
+	}
+	if opts.IncludeRemoteRefs {
+		revArgs = append(revArgs, "--remotes")
+	}
+	links, err := s.scanCommitLinks(revArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-opts.GracePeriod)
+	changes := make(map[string]string)
+	var removed []string
+	for _, e := range stored {
+		var meta Metadata
+		data, err := s.catBlob(e.tree + ":metadata.json")
+		if err != nil || json.Unmarshal([]byte(data), &meta) != nil {
+			// Broken checkpoints are left for fsck to diagnose.
+			result.Kept++
+			continue
+		}
+
+		if links.linked(e.id, meta.CommitHash) {
+			result.Kept++
+			continue
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, meta.CreatedAt)
+		if err != nil || createdAt.After(cutoff) {
+			result.Recent = append(result.Recent, meta)
+			continue
+		}
+
+		result.Removed = append(result.Removed, meta)
+		changes[e.id] = ""
+		removed = append(removed, e.id)
+	}
+
+	if len(changes) == 0 || opts.DryRun {
+		return result, nil
+	}
+
+	msg := fmt.Sprintf("clean: removed %d orphaned checkpoint(s)", len(changes))
+	if err := s.updateCheckpoints(changes, msg, removedTrailerLines(removed, "removed as orphaned")); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
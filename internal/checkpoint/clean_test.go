@@ -0,0 +1,120 @@
+package checkpoint
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClean(t *testing.T) {
+	s := initCheckpointRepo(t)
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	write := func(id, commit string, createdAt time.Time) {
+		t.Helper()
+		cp := &Checkpoint{ID: id, CommitHash: commit, Branch: "main", CreatedAt: createdAt}
+		if err := s.Write(cp, &SessionFiles{Prompt: "prompt " + id}); err != nil {
+			t.Fatalf("Write(%s): %v", id, err)
+		}
+	}
+
+	// Linked directly by commit hash.
+	onMain := commitWithTrailer(t, s, "cc0000000001")
+	write("cc0000000001", onMain, old)
+
+	// Stored hash was rewritten; the new commit still carries the trailer.
+	commitWithTrailer(t, s, "cc0000000002")
+	write("cc0000000002", "2222222222222222222222222222222222222222", old)
+
+	// Orphaned and old: removed.
+	write("cc0000000003", "3333333333333333333333333333333333333333", old)
+
+	// Orphaned but recent: kept by the grace period.
+	write("cc0000000004", "4444444444444444444444444444444444444444", time.Now())
+
+	// Only reachable from a remote-tracking branch.
+	if _, err := s.git("checkout", "-q", "-b", "feature"); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	remoteOnly := commitWithTrailer(t, s, "cc0000000005")
+	write("cc0000000005", remoteOnly, old)
+	if _, err := s.git("update-ref", "refs/remotes/origin/feature", remoteOnly); err != nil {
+		t.Fatalf("update-ref: %v", err)
+	}
+	if _, err := s.git("checkout", "-q", "-"); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if _, err := s.git("branch", "-D", "feature"); err != nil {
+		t.Fatalf("branch -D: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    CleanOptions
+		removed []string
+		recent  int
+	}{
+		{
+			name:    "remote refs and grace period keep checkpoints",
+			opts:    CleanOptions{IncludeRemoteRefs: true, GracePeriod: 7 * 24 * time.Hour, DryRun: true},
+			removed: []string{"cc0000000003"},
+			recent:  1,
+		},
+		{
+			name:    "local refs only",
+			opts:    CleanOptions{GracePeriod: 7 * 24 * time.Hour, DryRun: true},
+			removed: []string{"cc0000000003", "cc0000000005"},
+			recent:  1,
+		},
+		{
+			name:    "no grace period",
+			opts:    CleanOptions{IncludeRemoteRefs: true},
+			removed: []string{"cc0000000003", "cc0000000004"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Clean(tt.opts)
+			if err != nil {
+				t.Fatalf("Clean: %v", err)
+			}
+			var removed []string
+			for _, m := range result.Removed {
+				removed = append(removed, m.ID)
+			}
+			if len(removed) != len(tt.removed) {
+				t.Fatalf("removed = %v, want %v", removed, tt.removed)
+			}
+			for i := range removed {
+				if removed[i] != tt.removed[i] {
+					t.Errorf("removed[%d] = %s, want %s", i, removed[i], tt.removed[i])
+				}
+			}
+			if len(result.Recent) != tt.recent {
+				t.Errorf("recent = %d, want %d", len(result.Recent), tt.recent)
+			}
+		})
+	}
+
+	ids, err := s.IDs()
+	if err != nil {
+		t.Fatalf("IDs: %v", err)
+	}
+	want := []string{"cc0000000001", "cc0000000002", "cc0000000005"}
+	if len(ids) != len(want) {
+		t.Fatalf("remaining = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("remaining[%d] = %s, want %s", i, ids[i], want[i])
+		}
+	}
+
+	// A dry run never changes the branch.
+	before, _ := s.git("rev-parse", checkpointBranch)
+	if _, err := s.Clean(CleanOptions{DryRun: true}); err != nil {
+		t.Fatalf("Clean: %v", err)
+	}
+	if after, _ := s.git("rev-parse", checkpointBranch); after != before {
+		t.Error("dry run modified the checkpoint branch")
+	}
+}
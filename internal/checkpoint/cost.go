@@ -0,0 +1,47 @@
+package checkpoint
+
+import (
+	"sort"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/pricing"
+)
+
+// unknownModel names the model of tokens recorded without one.
+const unknownModel = "unknown"
+
+// ModelUsage returns the session's tokens by model. Sessions with a single
+// model but no per-model breakdown are attributed to that model; sessions
+// captured before token breakdowns existed report only a total, as input
+// tokens of an "unknown" model.
+func (sm SessionMetadata) ModelUsage() map[string]agent.TokenUsage {
+	switch {
+	case len(sm.ModelTokens) > 0:
+		return sm.ModelTokens
+	case sm.Tokens.Total() > 0:
+		model := unknownModel
+		if len(sm.Models) == 1 {
+			model = sm.Models[0]
+		}
+		return map[string]agent.TokenUsage{model: sm.Tokens}
+	case sm.TotalTokens > 0:
+		return map[string]agent.TokenUsage{unknownModel: {Input: sm.TotalTokens}}
+	}
+	return nil
+}
+
+// EstimatedCost prices the session's tokens with table, in USD. Models the
+// table has no price for are returned in unpriced, and their tokens are left
+// out of the cost.
+func (sm SessionMetadata) EstimatedCost(table pricing.Table) (cost float64, unpriced []string) {
+	for model, t := range sm.ModelUsage() {
+		c, ok := t.Cost(table, model)
+		if !ok {
+			unpriced = append(unpriced, model)
+			continue
+		}
+		cost += c
+	}
+	sort.Strings(unpriced)
+	return cost, unpriced
+}
@@ -0,0 +1,61 @@
+package checkpoint
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/pricing"
+)
+
+func TestEstimatedCost(t *testing.T) {
+	table := pricing.Table{
+		"claude-sonnet-4": {Input: 3, Output: 15, CacheCreation: 3.75, CacheRead: 0.30},
+		"gpt-5":           {Input: 1.25, Output: 10, CacheRead: 0.125},
+	}
+
+	tests := []struct {
+		name     string
+		session  SessionMetadata
+		want     float64
+		unpriced []string
+	}{
+		{
+			name: "per model",
+			session: SessionMetadata{ModelTokens: map[string]agent.TokenUsage{
+				"claude-sonnet-4-5": {Input: 1_000_000, CacheRead: 1_000_000},
+				"gpt-5-codex":       {Output: 100_000},
+				"local-llama":       {Input: 50},
+			}},
+			want:     3 + 0.3 + 1,
+			unpriced: []string{"local-llama"},
+		},
+		{
+			name:    "single model without breakdown",
+			session: SessionMetadata{Models: []string{"claude-sonnet-4"}, Tokens: agent.TokenUsage{Output: 1_000_000}},
+			want:    15,
+		},
+		{
+			name:     "total only",
+			session:  SessionMetadata{TotalTokens: 1234},
+			unpriced: []string{"unknown"},
+		},
+		{
+			name:    "no tokens",
+			session: SessionMetadata{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unpriced := tt.session.EstimatedCost(table)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("cost = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(unpriced, tt.unpriced) {
+				t.Errorf("unpriced = %v, want %v", unpriced, tt.unpriced)
+			}
+		})
+	}
+}
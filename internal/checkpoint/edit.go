@@ -0,0 +1,238 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// storedEntry is a checkpoint directory on the branch, identified by its
+// <shard><rest> path rather than the ID recorded in its metadata.
+type storedEntry struct {
+	id   string
+	tree string
+}
+
+// entries lists every <shard>/<rest> directory on the checkpoint branch.
+func (s *Store) entries() ([]storedEntry, error) {
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch); err != nil {
+		return nil, nil
+	}
+	return s.treeEntries(checkpointBranch)
+}
+
+// treeEntries lists every <shard>/<rest> directory in a checkpoint tree.
+func (s *Store) treeEntries(treeish string) ([]storedEntry, error) {
+	out, err := s.git("ls-tree", "-r", "-d", treeish)
+	if err != nil {
+		return nil, fmt.Errorf("listing checkpoints: %w", err)
+	}
+
+	var entries []storedEntry
+	for _, e := range parseTreeListing(out) {
+		path := strings.Split(e.name, "/")
+		if len(path) != 2 {
+			continue
+		}
+		entries = append(entries, storedEntry{id: path[0] + path[1], tree: e.hash})
+	}
+	return entries, nil
+}
+
+// updateCheckpoints commits a new checkpoint branch tree in which each
+// checkpoint in changes is set to the given tree, or removed when the tree is
+// "". Shards left empty are dropped, as are the snapshots of removed
+// checkpoints. Any trailers are appended to msg.
+func (s *Store) updateCheckpoints(changes map[string]string, msg string, trailers []string) error {
+	currentTree, err := s.getCurrentTree()
+	if err != nil {
+		return fmt.Errorf("getting current tree: %w", err)
+	}
+
+	newRoot, err := s.applyChanges(currentTree, changes)
+	if err != nil {
+		return err
+	}
+
+	parentCommit, err := s.git("rev-parse", checkpointBranch)
+	if err != nil {
+		return fmt.Errorf("getting parent commit: %w", err)
+	}
+
+	if len(trailers) > 0 {
+		msg += "\n\n" + strings.Join(trailers, "\n")
+	}
+	commitHash, err := s.commitTree(newRoot, parentCommit, msg)
+	if err != nil {
+		return fmt.Errorf("creating commit: %w", err)
+	}
+
+	if _, err := s.git("update-ref", "refs/heads/"+checkpointBranch, commitHash); err != nil {
+		return fmt.Errorf("updating ref: %w", err)
+	}
+
+	var removed []string
+	for id, tree := range changes {
+		if tree == "" {
+			removed = append(removed, id)
+		}
+	}
+	s.dropSnapshots(removed)
+	return nil
+}
+
+// applyChanges returns the root tree rootTree with changes applied (see
+// updateCheckpoints).
+func (s *Store) applyChanges(rootTree string, changes map[string]string) (string, error) {
+	byShard := make(map[string]map[string]string)
+	for id, tree := range changes {
+		shard := Shard(id)
+		if byShard[shard] == nil {
+			byShard[shard] = make(map[string]string)
+		}
+		byShard[shard][Rest(id)] = tree
+	}
+
+	rootListing, _ := s.git("ls-tree", rootTree)
+	var rootEntries []treeEntry
+	for _, e := range parseTreeListing(rootListing) {
+		shardChanges, ok := byShard[e.name]
+		if !ok || e.typ != "tree" {
+			rootEntries = append(rootEntries, e)
+			continue
+		}
+		delete(byShard, e.name)
+
+		shardListing, err := s.git("ls-tree", e.hash)
+		if err != nil {
+			return "", fmt.Errorf("reading shard %s: %w", e.name, err)
+		}
+		shardTree, err := s.applyShardChanges(parseTreeListing(shardListing), shardChanges)
+		if err != nil {
+			return "", fmt.Errorf("updating shard %s: %w", e.name, err)
+		}
+		if shardTree != "" {
+			rootEntries = append(rootEntries, treeEntry{mode: "040000", typ: "tree", hash: shardTree, name: e.name})
+		}
+	}
+
+	// Shards that did not exist yet
+	for shard, shardChanges := range byShard {
+		shardTree, err := s.applyShardChanges(nil, shardChanges)
+		if err != nil {
+			return "", fmt.Errorf("creating shard %s: %w", shard, err)
+		}
+		if shardTree != "" {
+			rootEntries = append(rootEntries, treeEntry{mode: "040000", typ: "tree", hash: shardTree, name: shard})
+		}
+	}
+
+	newRoot, err := s.mktree(rootEntries)
+	if err != nil {
+		return "", fmt.Errorf("creating root tree: %w", err)
+	}
+	return newRoot, nil
+}
+
+// applyShardChanges returns the hash of the shard tree with changes applied,
+// or "" when the shard ends up empty.
+func (s *Store) applyShardChanges(existing []treeEntry, changes map[string]string) (string, error) {
+	var entries []treeEntry
+	for _, e := range existing {
+		tree, ok := changes[e.name]
+		if !ok {
+			entries = append(entries, e)
+			continue
+		}
+		delete(changes, e.name)
+		if tree != "" {
+			entries = append(entries, treeEntry{mode: "040000", typ: "tree", hash: tree, name: e.name})
+		}
+	}
+
+	var added []string
+	for rest, tree := range changes {
+		if tree != "" {
+			added = append(added, rest)
+		}
+	}
+	sort.Strings(added)
+	for _, rest := range added {
+		entries = append(entries, treeEntry{mode: "040000", typ: "tree", hash: changes[rest], name: rest})
+	}
+
+	if len(entries) == 0 {
+		return "", nil
+	}
+	return s.mktree(entries)
+}
+
+// replaceMetadata returns a copy of the checkpoint tree cpTree with its
+// metadata.json replaced by meta. When the checkpoint has a manifest, it is
+// updated to match and its new hash is returned for the commit trailer.
+func (s *Store) replaceMetadata(cpTree string, meta Metadata) (tree, manifestHash string, err error) {
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling metadata: %w", err)
+	}
+	metaBlob, err := s.hashObject(string(metaJSON))
+	if err != nil {
+		return "", "", fmt.Errorf("hashing metadata: %w", err)
+	}
+
+	var manifestBlob string
+	if data := s.readManifest(cpTree); data != "" {
+		var m Manifest
+		if err := json.Unmarshal([]byte(data), &m); err == nil {
+			m.Files["metadata.json"] = sha256Hex(string(metaJSON))
+			var manifestJSON string
+			manifestJSON, manifestHash, err = m.encode()
+			if err != nil {
+				return "", "", err
+			}
+			if manifestBlob, err = s.hashObject(manifestJSON); err != nil {
+				return "", "", fmt.Errorf("hashing manifest: %w", err)
+			}
+		}
+	}
+
+	listing, err := s.git("ls-tree", cpTree)
+	if err != nil {
+		return "", "", fmt.Errorf("reading checkpoint tree: %w", err)
+	}
+	entries := parseTreeListing(listing)
+	hasMeta := false
+	for i, e := range entries {
+		switch {
+		case e.name == "metadata.json":
+			entries[i].hash = metaBlob
+			hasMeta = true
+		case e.name == manifestFile && manifestBlob != "":
+			entries[i].hash = manifestBlob
+		}
+	}
+	if !hasMeta {
+		entries = append(entries, treeEntry{mode: "100644", typ: "blob", hash: metaBlob, name: "metadata.json"})
+	}
+
+	tree, err = s.mktree(entries)
+	if err != nil {
+		return "", "", fmt.Errorf("creating checkpoint tree: %w", err)
+	}
+	return tree, manifestHash, nil
+}
+
+// parseTreeListing parses `git ls-tree` output into tree entries.
+func parseTreeListing(listing string) []treeEntry {
+	var entries []treeEntry
+	for _, line := range strings.Split(listing, "\n") {
+		tabParts := strings.SplitN(line, "\t", 2)
+		parts := strings.Fields(line)
+		if len(tabParts) < 2 || len(parts) < 4 {
+			continue
+		}
+		entries = append(entries, treeEntry{mode: parts[0], typ: parts[1], hash: parts[2], name: tabParts[1]})
+	}
+	return entries
+}
@@ -0,0 +1,388 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/partio-io/cli/internal/encrypt"
+)
+
+// FsckKind identifies a class of checkpoint branch inconsistency.
+type FsckKind string
+
+const (
+	// FsckBadMetadata: metadata.json is missing or cannot be parsed.
+	FsckBadMetadata FsckKind = "bad-metadata"
+	// FsckIDMismatch: the metadata id differs from the <shard>/<rest> path.
+	FsckIDMismatch FsckKind = "id-mismatch"
+	// FsckStaleCommit: the stored commit is gone, but a rewritten commit
+	// still carries the checkpoint's trailer.
+	FsckStaleCommit FsckKind = "stale-commit"
+	// FsckUnreachable: no commit in any ref links to the checkpoint.
+	FsckUnreachable FsckKind = "unreachable-commit"
+	// FsckMissing: a commit carries a trailer for a checkpoint that does not exist.
+	FsckMissing FsckKind = "missing-checkpoint"
+	// FsckDuplicate: the same session transcript was captured more than once.
+	FsckDuplicate FsckKind = "duplicate-session"
+	// FsckRemoved: a commit carries a trailer for a checkpoint that prune,
+	// retention, clean or archive removed on purpose.
+	FsckRemoved FsckKind = "removed-checkpoint"
+	// FsckLegacy: a commit carries a trailer for a checkpoint that is still
+	// on the legacy v1 branch (see partio migrate).
+	FsckLegacy FsckKind = "legacy-checkpoint"
+)
+
+// Info reports whether the kind is informational rather than a problem.
+func (k FsckKind) Info() bool {
+	return k == FsckRemoved || k == FsckLegacy
+}
+
+// FsckProblem is a single inconsistency found on the checkpoint branch.
+type FsckProblem struct {
+	Kind   FsckKind
+	ID     string
+	Detail string
+	// Repairable is true when Fsck can fix the problem; Repaired is true
+	// once it has.
+	Repairable bool
+	Repaired   bool
+}
+
+// FsckResult holds the outcome of a consistency check.
+type FsckResult struct {
+	Checked  int
+	Problems []FsckProblem
+}
+
+// fsckEntry is a checkpoint directory with its (possibly repaired) metadata.
+type fsckEntry struct {
+	storedEntry
+	meta    Metadata
+	changed bool
+}
+
+// Fsck checks every checkpoint on the branch against the repository's
+// commits. With repair set, fixable problems are corrected in a single new
+// checkpoint branch commit:
+//   - missing or malformed metadata is rebuilt from the commit that links to it;
+//   - a mismatched id is corrected, or the checkpoint moved to the path its
+//     commit trailer references;
+//   - a stale commit hash is updated to the rewritten commit carrying its trailer;
+//   - a checkpoint missing locally is restored from a remote-tracking
+//     checkpoint branch when one has it;
+//   - duplicate captures no commit links to are removed.
+//
+// Checkpoints no commit links to at all are reported but left for Clean.
+// Trailers naming checkpoints that were removed on purpose or are still on the
+// legacy branch are reported with an informational kind (see FsckKind.Info).
+func (s *Store) Fsck(repair bool) (*FsckResult, error) {
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch); err != nil {
+		return nil, fmt.Errorf("checkpoint branch does not exist")
+	}
+
+	stored, err := s.entries()
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.scanCommitLinks("--all")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FsckResult{Checked: len(stored)}
+	report := func(kind FsckKind, id string, repairable bool, format string, args ...any) {
+		result.Problems = append(result.Problems, FsckProblem{
+			Kind:       kind,
+			ID:         id,
+			Detail:     fmt.Sprintf(format, args...),
+			Repairable: repairable,
+			Repaired:   repair && repairable,
+		})
+	}
+
+	present := make(map[string]bool, len(stored))
+	for _, e := range stored {
+		present[e.id] = true
+	}
+
+	changes := make(map[string]string)
+	trailers := make(map[string]string)
+
+	var checked []*fsckEntry
+	for _, e := range stored {
+		entry := &fsckEntry{storedEntry: e}
+		checked = append(checked, entry)
+
+		if !s.fsckMetadata(entry, links, report) {
+			continue
+		}
+
+		if entry.meta.ID != e.id {
+			target := entry.meta.ID
+			if len(target) == 12 && !present[target] && len(links.byID[target]) > 0 && len(links.byID[e.id]) == 0 {
+				report(FsckIDMismatch, e.id, true, "metadata id is %s, which its commit references; moving it there", target)
+				if repair {
+					changes[e.id] = ""
+					changes[target] = e.tree
+					if data := s.readManifest(e.tree); data != "" {
+						trailers[target] = manifestTrailerLine(target, sha256Hex(data))
+					}
+					present[target] = true
+				}
+				continue
+			}
+			report(FsckIDMismatch, e.id, true, "metadata id is %q", entry.meta.ID)
+			entry.meta.ID = e.id
+			entry.changed = true
+		}
+
+		if !links.reachable[entry.meta.CommitHash] {
+			if commits := links.byID[e.id]; len(commits) > 0 {
+				report(FsckStaleCommit, e.id, true, "commit %s no longer exists; %s carries its trailer", shortHash(entry.meta.CommitHash), shortHash(commits[0]))
+				entry.meta.CommitHash = commits[0]
+				entry.changed = true
+			} else {
+				report(FsckUnreachable, e.id, false, "commit %s is not reachable from any ref (remove with partio clean)", shortHash(entry.meta.CommitHash))
+			}
+		}
+	}
+
+	if err := s.fsckMissing(links, present, changes, trailers, repair, report); err != nil {
+		return nil, err
+	}
+	removed := s.fsckDuplicates(checked, links, report)
+
+	if !repair {
+		return result, nil
+	}
+
+	for _, entry := range checked {
+		if removed[entry.id] {
+			changes[entry.id] = ""
+			delete(trailers, entry.id)
+			continue
+		}
+		if !entry.changed {
+			continue
+		}
+		tree, manifestHash, err := s.replaceMetadata(entry.tree, entry.meta)
+		if err != nil {
+			return nil, fmt.Errorf("repairing %s: %w", entry.id, err)
+		}
+		changes[entry.id] = tree
+		if manifestHash != "" {
+			trailers[entry.id] = manifestTrailerLine(entry.id, manifestHash)
+		}
+	}
+
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	var trailerLines []string
+	for _, line := range trailers {
+		trailerLines = append(trailerLines, line)
+	}
+	sort.Strings(trailerLines)
+
+	msg := fmt.Sprintf("fsck: repaired %d checkpoint(s)", len(changes))
+	if err := s.updateCheckpoints(changes, msg, trailerLines); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fsckMetadata loads the entry's metadata, rebuilding it from the linking
+// commit when it is missing or malformed. It returns false when there is no
+// usable metadata to check further.
+func (s *Store) fsckMetadata(entry *fsckEntry, links *commitLinks, report func(FsckKind, string, bool, string, ...any)) bool {
+	data, err := s.catBlob(entry.tree + ":metadata.json")
+	problem := ""
+	switch {
+	case err != nil:
+		problem = "metadata.json is missing"
+	case json.Unmarshal([]byte(data), &entry.meta) != nil:
+		problem = "metadata.json is malformed"
+	default:
+		return true
+	}
+
+	commits := links.byID[entry.id]
+	if len(commits) == 0 {
+		report(FsckBadMetadata, entry.id, false, "%s and no commit references the checkpoint", problem)
+		return false
+	}
+
+	report(FsckBadMetadata, entry.id, true, "%s; rebuilding from %s", problem, shortHash(commits[0]))
+	entry.meta = s.rebuildMetadata(entry, commits[0])
+	entry.changed = true
+	return true
+}
+
+// rebuildMetadata reconstructs checkpoint metadata from the commit that links
+// to it and whatever session files survive.
+func (s *Store) rebuildMetadata(entry *fsckEntry, commitHash string) Metadata {
+	meta := Metadata{ID: entry.id, CommitHash: commitHash, ContentHash: commitHash}
+
+	if date, err := s.git("log", "-1", "--format=%cI", commitHash); err == nil {
+		if t, err := time.Parse(time.RFC3339, date); err == nil {
+			meta.CreatedAt = t.UTC().Format(time.RFC3339)
+		}
+	}
+
+	var sessionMeta SessionMetadata
+	if data, err := s.catBlob(entry.tree + ":0/metadata.json"); err == nil && json.Unmarshal([]byte(data), &sessionMeta) == nil {
+		meta.Agent = sessionMeta.Agent
+	}
+
+	for _, name := range []string{"full.jsonl", "prompt.txt", "diff.patch"} {
+		if data, err := s.catBlob(entry.tree + ":0/" + name); err == nil && encrypt.IsEncrypted(data) {
+			meta.Encrypted = true
+			break
+		}
+	}
+	return meta
+}
+
+// fsckMissing reports trailers that reference checkpoints absent from the
+// branch, restoring them from a remote-tracking checkpoint branch if possible.
+// Checkpoints removed on purpose, or not yet migrated from the legacy branch,
+// are only noted.
+func (s *Store) fsckMissing(links *commitLinks, present map[string]bool, changes, trailers map[string]string, repair bool, report func(FsckKind, string, bool, string, ...any)) error {
+	var missing []string
+	for id := range links.byID {
+		if !present[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+
+	removed, err := s.removedCheckpoints(checkpointBranch)
+	if err != nil {
+		return err
+	}
+	remoteRefs := s.remoteCheckpointRefs()
+	for _, id := range missing {
+		commit := shortHash(links.byID[id][0])
+
+		if reason, ok := removed[id]; ok {
+			report(FsckRemoved, id, false, "referenced by %s; %s", commit, reason)
+			continue
+		}
+		if _, err := s.git("rev-parse", "--verify", "--quiet", legacyCheckpointBranch+":"+Shard(id)+"/"+Rest(id)); err == nil {
+			report(FsckLegacy, id, false, "referenced by %s; still on %s (run partio migrate)", commit, legacyCheckpointBranch)
+			continue
+		}
+
+		var tree, source string
+		for _, ref := range remoteRefs {
+			if t, err := s.git("rev-parse", "--verify", "--quiet", ref+":"+Shard(id)+"/"+Rest(id)); err == nil {
+				tree, source = t, ref
+				break
+			}
+		}
+		if tree == "" {
+			report(FsckMissing, id, false, "referenced by %s but not stored anywhere", commit)
+			continue
+		}
+
+		report(FsckMissing, id, true, "referenced by %s; restoring from %s", commit, source)
+		if repair {
+			changes[id] = tree
+			if data := s.readManifest(tree); data != "" {
+				trailers[id] = manifestTrailerLine(id, sha256Hex(data))
+			}
+		}
+	}
+	return nil
+}
+
+// remoteCheckpointRefs lists remote-tracking copies of the checkpoint branch.
+func (s *Store) remoteCheckpointRefs() []string {
+	out, err := s.git("for-each-ref", "--format=%(refname)", "refs/remotes/")
+	if err != nil {
+		return nil
+	}
+	var refs []string
+	for _, ref := range strings.Split(out, "\n") {
+		if strings.HasSuffix(ref, "/"+checkpointBranch) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// fsckDuplicates reports checkpoints that store the same session transcript
+// as another. Within each group the checkpoint a commit links to (or else the
+// oldest) is kept; the IDs of duplicates no commit links to are returned for
+// removal.
+func (s *Store) fsckDuplicates(checked []*fsckEntry, links *commitLinks, report func(FsckKind, string, bool, string, ...any)) map[string]bool {
+	groups := make(map[string][]*fsckEntry)
+	var keys []string
+	for _, entry := range checked {
+		if entry.meta.SessionID == "" {
+			continue
+		}
+		hash := s.transcriptHash(entry.tree)
+		if hash == "" {
+			continue
+		}
+		key := entry.meta.SessionID + "\x00" + hash
+		if groups[key] == nil {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], entry)
+	}
+	sort.Strings(keys)
+
+	removed := make(map[string]bool)
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].meta.CreatedAt < group[j].meta.CreatedAt })
+
+		keep := group[0]
+		for _, entry := range group {
+			if len(links.byID[entry.id]) > 0 {
+				keep = entry
+				break
+			}
+		}
+
+		for _, entry := range group {
+			if entry == keep {
+				continue
+			}
+			if len(links.byID[entry.id]) > 0 {
+				report(FsckDuplicate, entry.id, false, "same transcript of session %s as %s; both are linked from commits", entry.meta.SessionID, keep.id)
+				continue
+			}
+			report(FsckDuplicate, entry.id, true, "same transcript of session %s as %s", entry.meta.SessionID, keep.id)
+			removed[entry.id] = true
+		}
+	}
+	return removed
+}
+
+// transcriptHash returns the SHA-256 of the plaintext session transcript in
+// the checkpoint tree, or "" when it is empty or only stored encrypted
+// without a recorded hash.
+func (s *Store) transcriptHash(tree string) string {
+	var sessionMeta SessionMetadata
+	if data, err := s.catBlob(tree + ":0/metadata.json"); err == nil && json.Unmarshal([]byte(data), &sessionMeta) == nil && sessionMeta.TranscriptHash != "" {
+		return sessionMeta.TranscriptHash
+	}
+	data, err := s.catBlob(tree + ":0/full.jsonl")
+	if err != nil || data == "" || encrypt.IsEncrypted(data) {
+		return ""
+	}
+	return sha256Hex(data)
+}
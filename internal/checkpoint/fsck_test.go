@@ -0,0 +1,229 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/encrypt"
+)
+
+// commitWithTrailer creates an empty code commit linking to checkpoint id.
+func commitWithTrailer(t *testing.T, s *Store, id string) string {
+	t.Helper()
+	if _, err := s.git("commit", "--allow-empty", "-m", "change "+id+"\n\nPartio-Checkpoint: "+id); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	head, err := s.git("rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("rev-parse: %v", err)
+	}
+	return head
+}
+
+func writeSessionCheckpoint(t *testing.T, s *Store, id, commit, sessionID, transcript string) {
+	t.Helper()
+	cp := &Checkpoint{ID: id, SessionID: sessionID, CommitHash: commit, Branch: "main", CreatedAt: time.Now()}
+	if err := s.Write(cp, &SessionFiles{Prompt: "prompt " + id, FullJSONL: transcript}); err != nil {
+		t.Fatalf("Write(%s): %v", id, err)
+	}
+}
+
+func problemKinds(r *FsckResult) map[string]FsckKind {
+	kinds := make(map[string]FsckKind)
+	for _, p := range r.Problems {
+		kinds[p.ID+" "+string(p.Kind)] = p.Kind
+	}
+	return kinds
+}
+
+func sortedKeys(m map[string]FsckKind) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestFsck(t *testing.T) {
+	s := initCheckpointRepo(t)
+	const gone = "1111111111111111111111111111111111111111"
+
+	linked := commitWithTrailer(t, s, "aa0000000001")
+	writeSessionCheckpoint(t, s, "aa0000000001", linked, "sess-1", "{\"a\":1}\n")
+
+	commitWithTrailer(t, s, "aa0000000002")
+	writeSessionCheckpoint(t, s, "aa0000000002", gone, "", "")
+
+	writeSessionCheckpoint(t, s, "aa0000000003", gone, "", "")
+
+	commitWithTrailer(t, s, "aa0000000004")
+
+	writeSessionCheckpoint(t, s, "aa0000000005", linked, "sess-1", "{\"a\":1}\n")
+
+	mismatched := commitWithTrailer(t, s, "aa0000000006")
+	writeSessionCheckpoint(t, s, "aa0000000006", mismatched, "", "")
+	tamperCheckpointFile(t, s, "aa/0000000006/metadata.json", `{"id":"wrong","commit_hash":"`+mismatched+`"}`)
+
+	commitWithTrailer(t, s, "aa0000000007")
+	writeSessionCheckpoint(t, s, "aa0000000007", gone, "", "")
+	tamperCheckpointFile(t, s, "aa/0000000007/metadata.json", "{")
+
+	result, err := s.Fsck(false)
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+	if result.Checked != 6 {
+		t.Errorf("Checked = %d, want 6", result.Checked)
+	}
+
+	want := []string{
+		"aa0000000002 stale-commit",
+		"aa0000000003 unreachable-commit",
+		"aa0000000004 missing-checkpoint",
+		"aa0000000005 duplicate-session",
+		"aa0000000006 id-mismatch",
+		"aa0000000007 bad-metadata",
+	}
+	got := sortedKeys(problemKinds(result))
+	if len(got) != len(want) {
+		t.Fatalf("problems = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("problem[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	for _, p := range result.Problems {
+		if p.Repaired {
+			t.Errorf("%s reported as repaired without --repair", p.ID)
+		}
+	}
+
+	if _, err := s.Fsck(true); err != nil {
+		t.Fatalf("Fsck(repair): %v", err)
+	}
+
+	result, err = s.Fsck(false)
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+	got = sortedKeys(problemKinds(result))
+	want = []string{"aa0000000003 unreachable-commit", "aa0000000004 missing-checkpoint"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("problems after repair = %v, want %v", got, want)
+	}
+
+	// Repaired checkpoints keep verifying against their manifests.
+	results, err := s.Verify([]string{"aa0000000002", "aa0000000006", "aa0000000007"}, false)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	for _, r := range results {
+		if !r.OK() {
+			t.Errorf("%s fails verification after repair: %+v", r.ID, r.Checks)
+		}
+	}
+
+	metaJSON, err := s.git("show", checkpointBranch+":aa/0000000007/metadata.json")
+	if err != nil {
+		t.Fatalf("reading rebuilt metadata: %v", err)
+	}
+	var meta Metadata
+	if err := json.Unmarshal([]byte(metaJSON), &meta); err != nil {
+		t.Fatalf("rebuilt metadata is invalid: %v", err)
+	}
+	if meta.ID != "aa0000000007" || meta.CommitHash == "" || meta.CreatedAt == "" {
+		t.Errorf("rebuilt metadata = %+v", meta)
+	}
+}
+
+func TestFsckRestoresFromRemote(t *testing.T) {
+	s := initCheckpointRepo(t)
+
+	commitWithTrailer(t, s, "bb0000000001")
+	writeSessionCheckpoint(t, s, "bb0000000001", "", "", "")
+
+	// Simulate a remote that has the checkpoint while the local branch lost it.
+	remoteTip, _ := s.git("rev-parse", checkpointBranch)
+	if _, err := s.git("update-ref", "refs/remotes/origin/"+checkpointBranch, remoteTip); err != nil {
+		t.Fatalf("update-ref: %v", err)
+	}
+	if err := s.updateCheckpoints(map[string]string{"bb0000000001": ""}, "drop", nil); err != nil {
+		t.Fatalf("updateCheckpoints: %v", err)
+	}
+
+	result, err := s.Fsck(true)
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+	if len(result.Problems) != 1 || result.Problems[0].Kind != FsckMissing || !result.Problems[0].Repaired {
+		t.Fatalf("problems = %+v, want one repaired missing-checkpoint", result.Problems)
+	}
+
+	ids, err := s.IDs()
+	if err != nil {
+		t.Fatalf("IDs: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "bb0000000001" {
+		t.Errorf("IDs after repair = %v", ids)
+	}
+}
+
+func TestFsckDuplicatesEncrypted(t *testing.T) {
+	s := initCheckpointRepo(t)
+	id, err := encrypt.GenerateIdentity()
+	if err != nil {
+		t.Fatalf("GenerateIdentity: %v", err)
+	}
+	s.SetRecipients([]*encrypt.Recipient{id.Recipient()})
+
+	// Each write encrypts with fresh randomness, so the stored blobs differ.
+	linked := commitWithTrailer(t, s, "cc0000000001")
+	writeSessionCheckpoint(t, s, "cc0000000001", linked, "sess-1", "{\"a\":1}\n")
+	writeSessionCheckpoint(t, s, "cc0000000002", linked, "sess-1", "{\"a\":1}\n")
+	writeSessionCheckpoint(t, s, "cc0000000003", linked, "sess-1", "{\"a\":2}\n")
+
+	result, err := s.Fsck(false)
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+	got := sortedKeys(problemKinds(result))
+	if len(got) != 1 || got[0] != "cc0000000002 duplicate-session" {
+		t.Errorf("problems = %v, want [cc0000000002 duplicate-session]", got)
+	}
+}
+
+func TestFsckRemovedAndLegacy(t *testing.T) {
+	s := initCheckpointRepo(t)
+
+	commitWithTrailer(t, s, "dd0000000001")
+	writeSessionCheckpoint(t, s, "dd0000000001", "", "", "")
+	if _, err := s.Prune(0, "0000000000000000000000000000000000000000", false); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	// The record of the removal survives squashing the history.
+	if _, err := s.SquashHistory(nil); err != nil {
+		t.Fatalf("SquashHistory: %v", err)
+	}
+
+	commitWithTrailer(t, s, "dd0000000002")
+	writeLegacyCheckpoint(t, s, "dd0000000002")
+
+	result, err := s.Fsck(false)
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+	got := sortedKeys(problemKinds(result))
+	want := []string{"dd0000000001 removed-checkpoint", "dd0000000002 legacy-checkpoint"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("problems = %v, want %v", got, want)
+	}
+	for _, p := range result.Problems {
+		if !p.Kind.Info() || p.Repairable {
+			t.Errorf("%s %s should be informational", p.ID, p.Kind)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package checkpoint
+
+import "fmt"
+
+// Unmigrated returns the IDs of checkpoints on the legacy v1 branch that are
+// neither on the checkpoint branch nor were removed from it since migrating.
+func (s *Store) Unmigrated() ([]string, error) {
+	if _, err := s.git("rev-parse", "--verify", "--quiet", legacyCheckpointBranch); err != nil {
+		return nil, nil
+	}
+	legacy, err := s.treeEntries(legacyCheckpointBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[string]bool)
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch); err == nil {
+		current, err := s.entries()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range current {
+			done[e.id] = true
+		}
+		removed, err := s.removedCheckpoints(checkpointBranch)
+		if err != nil {
+			return nil, err
+		}
+		for id := range removed {
+			done[id] = true
+		}
+	}
+
+	var ids []string
+	for _, e := range legacy {
+		if !done[e.id] {
+			ids = append(ids, e.id)
+		}
+	}
+	return ids, nil
+}
+
+// requireMigrated fails while the legacy v1 branch holds checkpoints that
+// have not been migrated, since only Read and List look at that branch.
+func (s *Store) requireMigrated() error {
+	ids, err := s.Unmigrated()
+	if err != nil {
+		return err
+	}
+	switch len(ids) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("checkpoint %s is still on %s; run 'partio migrate' first", ids[0], legacyCheckpointBranch)
+	default:
+		return fmt.Errorf("%d checkpoints are still on %s; run 'partio migrate' first", len(ids), legacyCheckpointBranch)
+	}
+}
@@ -0,0 +1,82 @@
+package checkpoint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Trailer is the commit trailer linking a code commit to its checkpoint.
+const Trailer = "Partio-Checkpoint"
+
+// commitLinks records which commits are reachable from a set of refs and
+// which of them carry a Partio-Checkpoint trailer. A checkpoint whose stored
+// commit hash was rewritten (rebase, amend, cherry-pick) is still linked
+// through the trailer the rewritten commit carries.
+type commitLinks struct {
+	reachable map[string]bool
+	// byID maps a checkpoint ID to the commits whose trailers reference it,
+	// newest first.
+	byID map[string][]string
+}
+
+// linked reports whether the checkpoint is referenced by a reachable commit,
+// either through its stored commit hash or a trailer.
+func (l *commitLinks) linked(id, commitHash string) bool {
+	return (commitHash != "" && l.reachable[commitHash]) || len(l.byID[id]) > 0
+}
+
+// scanCommitLinks walks every commit selected by revArgs (e.g. "--all").
+func (s *Store) scanCommitLinks(revArgs ...string) (*commitLinks, error) {
+	commits, err := s.CommitTrailers(revArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	links := &commitLinks{reachable: make(map[string]bool), byID: make(map[string][]string)}
+	for _, c := range commits {
+		links.reachable[c.Hash] = true
+		for _, id := range c.IDs {
+			links.byID[id] = append(links.byID[id], c.Hash)
+		}
+	}
+	return links, nil
+}
+
+// LinkedCommit is a commit and the checkpoint IDs its Partio-Checkpoint
+// trailers name.
+type LinkedCommit struct {
+	Hash string
+	IDs  []string
+}
+
+// CommitTrailers returns every commit selected by revArgs (anything accepted
+// by git log, e.g. "--all" or "HEAD -- <path>"), newest first, with the
+// checkpoints its trailers name.
+func (s *Store) CommitTrailers(revArgs ...string) ([]LinkedCommit, error) {
+	args := append([]string{"log", "--format=%H%x00%(trailers:key=" + Trailer + ",valueonly)%x1e"}, revArgs...)
+	out, err := s.git(args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit history: %w", err)
+	}
+	return parseCommitTrailers(out), nil
+}
+
+// parseCommitTrailers parses git log records of the form
+// "<hash>\x00<trailer values>\x1e".
+func parseCommitTrailers(out string) []LinkedCommit {
+	var commits []LinkedCommit
+	for _, entry := range strings.Split(out, "\x1e") {
+		hash, values, _ := strings.Cut(strings.TrimSpace(entry), "\x00")
+		if hash == "" {
+			continue
+		}
+		c := LinkedCommit{Hash: hash}
+		for _, v := range strings.Split(values, "\n") {
+			if id := strings.TrimSpace(v); id != "" {
+				c.IDs = append(c.IDs, id)
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
@@ -0,0 +1,22 @@
+package checkpoint
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommitTrailers(t *testing.T) {
+	out := "aaa\x00a1b2c3d4e5f6\n\x1e\n" +
+		"bbb\x00\x1e\n" +
+		"ccc\x00111111111111\n222222222222\n\x1e\n"
+
+	got := parseCommitTrailers(out)
+	want := []LinkedCommit{
+		{Hash: "aaa", IDs: []string{"a1b2c3d4e5f6"}},
+		{Hash: "bbb"},
+		{Hash: "ccc", IDs: []string{"111111111111", "222222222222"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCommitTrailers = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IDs returns the IDs of all checkpoints on the checkpoint branch, derived
+// from the <shard>/<rest> directory layout. A missing branch yields no IDs.
+func (s *Store) IDs() ([]string, error) {
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch); err != nil {
+		return nil, nil
+	}
+
+	shards, err := s.git("ls-tree", "-d", "--name-only", checkpointBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, shard := range strings.Split(shards, "\n") {
+		if shard == "" {
+			continue
+		}
+		entries, err := s.git("ls-tree", "-d", "--name-only", checkpointBranch+":"+shard)
+		if err != nil {
+			continue
+		}
+		for _, rest := range strings.Split(entries, "\n") {
+			if rest != "" {
+				ids = append(ids, shard+rest)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// Summary is a checkpoint returned by List.
+type Summary struct {
+	ID       string
+	Metadata Metadata
+	// Sessions holds the metadata of each captured session.
+	Sessions []SessionMetadata
+	// Legacy is true for checkpoints that are only on the pre-v2 branch.
+	Legacy bool
+	// Err is set when the checkpoint's metadata could not be read.
+	Err error
+}
+
+// List returns every checkpoint on the checkpoint branch and the legacy v1
+// branch, sorted by ID. Legacy metadata is upgraded the same way as in Read.
+func (s *Store) List() ([]Summary, error) {
+	var summaries []Summary
+	seen := make(map[string]bool)
+	for _, branch := range []string{checkpointBranch, legacyCheckpointBranch} {
+		if _, err := s.git("rev-parse", "--verify", "--quiet", branch); err != nil {
+			continue
+		}
+		entries, err := s.treeEntries(branch)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if seen[e.id] {
+				continue
+			}
+			seen[e.id] = true
+
+			summary := Summary{ID: e.id, Legacy: branch == legacyCheckpointBranch}
+			summary.Metadata, summary.Sessions, summary.Err = s.readMetadata(e.tree)
+			summaries = append(summaries, summary)
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ID < summaries[j].ID })
+	return summaries, nil
+}
+
+// readMetadata reads and upgrades the metadata of the checkpoint tree cpTree,
+// along with its session metadata.
+func (s *Store) readMetadata(cpTree string) (Metadata, []SessionMetadata, error) {
+	var meta Metadata
+	data, err := s.catBlob(cpTree + ":metadata.json")
+	if err != nil {
+		return meta, nil, fmt.Errorf("metadata unavailable")
+	}
+	if err := json.Unmarshal([]byte(data), &meta); err != nil {
+		return meta, nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+
+	var sessions []SessionMetadata
+	if data, err := s.catBlob(cpTree + ":0/metadata.json"); err == nil {
+		var sm SessionMetadata
+		if json.Unmarshal([]byte(data), &sm) == nil {
+			sessions = append(sessions, sm)
+		}
+	}
+	meta.upgrade(sessions)
+	return meta, sessions, nil
+}
@@ -0,0 +1,72 @@
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// manifestFile is the name of the per-checkpoint manifest.
+const manifestFile = "manifest.json"
+
+// manifestTrailer records "<id> <manifest sha256>" in the commit message of
+// every checkpoint branch commit that adds a checkpoint, so that a signed
+// commit vouches for the checkpoint's content.
+const manifestTrailer = "Partio-Manifest"
+
+func manifestTrailerLine(id, manifestHash string) string {
+	return fmt.Sprintf("%s: %s %s", manifestTrailer, id, manifestHash)
+}
+
+// Manifest lists the SHA-256 of every file in a checkpoint directory, keyed by
+// path relative to the checkpoint (e.g. "0/full.jsonl").
+type Manifest struct {
+	Version int               `json:"version"`
+	Files   map[string]string `json:"files"`
+}
+
+// newManifest builds a manifest for the given path → content map.
+func newManifest(files map[string]string) Manifest {
+	m := Manifest{Version: 1, Files: make(map[string]string, len(files))}
+	for path, content := range files {
+		m.Files[path] = sha256Hex(content)
+	}
+	return m
+}
+
+// encode returns the canonical JSON form of the manifest and its hash.
+func (m Manifest) encode() (data string, hash string, err error) {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling manifest: %w", err)
+	}
+	data = string(b)
+	return data, sha256Hex(data), nil
+}
+
+// paths returns the manifest's file paths in sorted order.
+func (m Manifest) paths() []string {
+	paths := make([]string, 0, len(m.Files))
+	for p := range m.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// readManifest returns the raw manifest stored in the checkpoint tree cpTree,
+// or "" when the checkpoint has none.
+func (s *Store) readManifest(cpTree string) string {
+	data, err := s.catBlob(cpTree + ":" + manifestFile)
+	if err != nil {
+		return ""
+	}
+	return data
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,8 +1,46 @@
 package checkpoint
 
+import "github.com/partio-io/cli/internal/agent"
+
 // SessionMetadata is stored per-session within a checkpoint directory.
 type SessionMetadata struct {
-	Agent       string `json:"agent"`
-	TotalTokens int    `json:"total_tokens"`
-	Duration    string `json:"duration"`
+	// SchemaVersion is 0 for sessions written before schema v2.
+	SchemaVersion int              `json:"schema_version,omitempty"`
+	SessionID     string           `json:"session_id,omitempty"`
+	Agent         string           `json:"agent"`
+	Models        []string         `json:"models,omitempty"`
+	Tokens        agent.TokenUsage `json:"tokens"`
+	// ModelTokens splits Tokens by model name.
+	ModelTokens map[string]agent.TokenUsage `json:"model_tokens,omitempty"`
+	TotalTokens int                         `json:"total_tokens"`
+	Duration    string                      `json:"duration"`
+	// TranscriptHash is the SHA-256 of the plaintext full.jsonl, kept in
+	// clear so duplicate captures can be found when the transcript is
+	// encrypted.
+	TranscriptHash string `json:"transcript_hash,omitempty"`
+	// Match records why the session was associated with the commit, when
+	// it was chosen among several.
+	Match *SessionMatch `json:"match,omitempty"`
+}
+
+// SessionMatch explains the choice of a session for a commit.
+type SessionMatch struct {
+	Score      float64 `json:"score"`
+	Reason     string  `json:"reason"`
+	Candidates int     `json:"candidates"`
+}
+
+// SessionRef summarises one session captured in a checkpoint. Its files live
+// in the numbered subdirectory Index (e.g. "0/").
+type SessionRef struct {
+	Index     int              `json:"index"`
+	SessionID string           `json:"session_id,omitempty"`
+	Agent     string           `json:"agent,omitempty"`
+	Models    []string         `json:"models,omitempty"`
+	Tokens    agent.TokenUsage `json:"tokens"`
+}
+
+// ref summarises the session for the checkpoint's session list.
+func (sm SessionMetadata) ref(index int) SessionRef {
+	return SessionRef{Index: index, SessionID: sm.SessionID, Agent: sm.Agent, Models: sm.Models, Tokens: sm.Tokens}
 }
@@ -0,0 +1,207 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MigrateResult holds the outcome of a schema migration.
+type MigrateResult struct {
+	// Migrated lists the checkpoints copied to the v2 branch.
+	Migrated []string
+	// Skipped lists legacy checkpoints already on the v2 branch, or removed
+	// from it after migrating.
+	Skipped []string
+	// Failed maps checkpoints that could not be converted to the reason.
+	Failed map[string]error
+}
+
+// Migrate converts every checkpoint on the legacy v1 branch to schema v2 and
+// adds it to the checkpoint branch in a single commit. Both metadata files
+// are upgraded, a session's missing agent is taken from the checkpoint, and
+// manifest.json is (re)computed so the result verifies like a new checkpoint.
+// The legacy branch is left untouched. If dryRun is true, no changes are made.
+func (s *Store) Migrate(dryRun bool) (*MigrateResult, error) {
+	result := &MigrateResult{Failed: make(map[string]error)}
+
+	if _, err := s.git("rev-parse", "--verify", "--quiet", legacyCheckpointBranch); err != nil {
+		return result, nil
+	}
+	legacy, err := s.treeEntries(legacyCheckpointBranch)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.entries()
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool, len(current))
+	for _, e := range current {
+		present[e.id] = true
+	}
+	// Checkpoints pruned, cleaned or archived after an earlier migration
+	// stay gone.
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch); err == nil {
+		removed, err := s.removedCheckpoints(checkpointBranch)
+		if err != nil {
+			return nil, err
+		}
+		for id := range removed {
+			present[id] = true
+		}
+	}
+
+	changes := make(map[string]string)
+	var trailers []string
+	for _, e := range legacy {
+		if present[e.id] {
+			result.Skipped = append(result.Skipped, e.id)
+			continue
+		}
+		tree, manifestHash, err := s.migrateEntry(e)
+		if err != nil {
+			result.Failed[e.id] = err
+			continue
+		}
+		changes[e.id] = tree
+		trailers = append(trailers, manifestTrailerLine(e.id, manifestHash))
+		result.Migrated = append(result.Migrated, e.id)
+	}
+
+	if len(changes) == 0 || dryRun {
+		return result, nil
+	}
+	sort.Strings(trailers)
+
+	if err := s.ensureBranch(); err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("migrate: %d checkpoint(s) from %s", len(changes), legacyCheckpointBranch)
+	if err := s.updateCheckpoints(changes, msg, trailers); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// migrateEntry returns the v2 tree of a legacy checkpoint and the hash of its
+// new manifest.
+func (s *Store) migrateEntry(e storedEntry) (tree, manifestHash string, err error) {
+	data, err := s.catBlob(e.tree + ":metadata.json")
+	if err != nil {
+		return "", "", fmt.Errorf("metadata.json is missing")
+	}
+	var meta Metadata
+	if err := json.Unmarshal([]byte(data), &meta); err != nil {
+		return "", "", fmt.Errorf("metadata.json is malformed: %w", err)
+	}
+
+	files := make(map[string]string)
+	var sessions []SessionMetadata
+	if data, err := s.catBlob(e.tree + ":0/metadata.json"); err == nil {
+		var sm SessionMetadata
+		if err := json.Unmarshal([]byte(data), &sm); err != nil {
+			return "", "", fmt.Errorf("0/metadata.json is malformed: %w", err)
+		}
+		sm.SchemaVersion = SchemaVersion
+		if sm.Agent == "" {
+			sm.Agent = meta.Agent
+		}
+		if sm.SessionID == "" {
+			sm.SessionID = meta.SessionID
+		}
+		sessionJSON, err := json.MarshalIndent(sm, "", "  ")
+		if err != nil {
+			return "", "", fmt.Errorf("marshaling session metadata: %w", err)
+		}
+		files["0/metadata.json"] = string(sessionJSON)
+		sessions = append(sessions, sm)
+	}
+
+	meta.upgrade(sessions)
+	meta.SchemaVersion = SchemaVersion
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling metadata: %w", err)
+	}
+	files["metadata.json"] = string(metaJSON)
+
+	return s.rewriteFiles(e.tree, files)
+}
+
+// rewriteFiles returns a copy of the checkpoint tree cpTree with the given
+// files (keyed by path, e.g. "0/metadata.json") replaced or added, and a
+// manifest.json covering every file. It returns the new manifest's hash.
+func (s *Store) rewriteFiles(cpTree string, files map[string]string) (tree, manifestHash string, err error) {
+	listing, err := s.git("ls-tree", "-r", cpTree)
+	if err != nil {
+		return "", "", fmt.Errorf("reading checkpoint tree: %w", err)
+	}
+
+	contents := make(map[string]string)
+	for _, entry := range parseTreeListing(listing) {
+		if entry.typ != "blob" || entry.name == manifestFile {
+			continue
+		}
+		if _, ok := files[entry.name]; ok {
+			continue
+		}
+		content, err := s.catBlob(entry.hash)
+		if err != nil {
+			return "", "", fmt.Errorf("reading %s: %w", entry.name, err)
+		}
+		contents[entry.name] = content
+	}
+	for path, content := range files {
+		contents[path] = content
+	}
+
+	manifestJSON, manifestHash, err := newManifest(contents).encode()
+	if err != nil {
+		return "", "", err
+	}
+
+	blobs := make(map[string]string, len(contents)+1)
+	for path, content := range contents {
+		if blobs[path], err = s.hashObject(content); err != nil {
+			return "", "", fmt.Errorf("hashing %s: %w", path, err)
+		}
+	}
+	if blobs[manifestFile], err = s.hashObject(manifestJSON); err != nil {
+		return "", "", fmt.Errorf("hashing manifest: %w", err)
+	}
+
+	tree, err = s.buildTree(blobs)
+	if err != nil {
+		return "", "", fmt.Errorf("creating checkpoint tree: %w", err)
+	}
+	return tree, manifestHash, nil
+}
+
+// buildTree writes a tree holding the given blobs, keyed by slash-separated
+// path, creating subtrees as needed.
+func (s *Store) buildTree(blobs map[string]string) (string, error) {
+	subdirs := make(map[string]map[string]string)
+	var entries []treeEntry
+	for path, hash := range blobs {
+		dir, rest, nested := strings.Cut(path, "/")
+		if !nested {
+			entries = append(entries, treeEntry{mode: "100644", typ: "blob", hash: hash, name: path})
+			continue
+		}
+		if subdirs[dir] == nil {
+			subdirs[dir] = make(map[string]string)
+		}
+		subdirs[dir][rest] = hash
+	}
+	for dir, sub := range subdirs {
+		hash, err := s.buildTree(sub)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, treeEntry{mode: "040000", typ: "tree", hash: hash, name: dir})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return s.mktree(entries)
+}
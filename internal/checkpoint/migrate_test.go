@@ -0,0 +1,193 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// writeLegacyCheckpoint commits a checkpoint in the v1 layout (no schema
+// version, no manifest, empty session agent) to the legacy branch.
+func writeLegacyCheckpoint(t *testing.T, s *Store, id string) {
+	t.Helper()
+	meta := `{"id":"` + id + `","session_id":"sess-1","commit_hash":"c0ffee","branch":"main","created_at":"2025-01-02T03:04:05Z","agent":"claude-code","agent_percent":80,"content_hash":"c0ffee"}`
+	files := map[string]string{
+		"metadata.json":   meta,
+		"0/metadata.json": `{"agent":"","total_tokens":42,"duration":"1m0s"}`,
+		"0/full.jsonl":    `{"type":"user"}`,
+		"0/prompt.txt":    "legacy prompt",
+	}
+	blobs := make(map[string]string)
+	for path, content := range files {
+		hash, err := s.hashObject(content)
+		if err != nil {
+			t.Fatalf("hash-object: %v", err)
+		}
+		blobs[Shard(id)+"/"+Rest(id)+"/"+path] = hash
+	}
+
+	parent, _ := s.git("rev-parse", "--verify", "--quiet", legacyCheckpointBranch)
+	if parent != "" {
+		tree, err := s.git("ls-tree", "-r", parent)
+		if err != nil {
+			t.Fatalf("ls-tree: %v", err)
+		}
+		for _, e := range parseTreeListing(tree) {
+			blobs[e.name] = e.hash
+		}
+	}
+	tree, err := s.buildTree(blobs)
+	if err != nil {
+		t.Fatalf("buildTree: %v", err)
+	}
+	commit, err := s.commitTree(tree, parent, "checkpoint: "+id)
+	if err != nil {
+		t.Fatalf("commit-tree: %v", err)
+	}
+	if _, err := s.git("update-ref", "refs/heads/"+legacyCheckpointBranch, commit); err != nil {
+		t.Fatalf("update-ref: %v", err)
+	}
+}
+
+func TestReadLegacy(t *testing.T) {
+	s := initCheckpointRepo(t)
+	t.Chdir(s.repoRoot)
+	writeLegacyCheckpoint(t, s, "aa0000000001")
+	writeTestCheckpoint(t, s, "bb0000000002")
+
+	data, err := Read("aa0000000001")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if data.Metadata.SchemaVersion != 1 {
+		t.Errorf("SchemaVersion = %d, want 1", data.Metadata.SchemaVersion)
+	}
+	if data.Prompt != "legacy prompt" {
+		t.Errorf("Prompt = %q", data.Prompt)
+	}
+	sessions := data.Metadata.Sessions
+	if len(sessions) != 1 || sessions[0].SessionID != "sess-1" || sessions[0].Agent != "claude-code" {
+		t.Errorf("Sessions = %+v", sessions)
+	}
+
+	data, err = Read("bb0000000002")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if data.Metadata.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", data.Metadata.SchemaVersion, SchemaVersion)
+	}
+
+	summaries, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(summaries) != 2 || !summaries[0].Legacy || summaries[1].Legacy {
+		t.Errorf("List = %+v", summaries)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	s := initCheckpointRepo(t)
+	t.Chdir(s.repoRoot)
+	writeLegacyCheckpoint(t, s, "aa0000000001")
+	writeLegacyCheckpoint(t, s, "aa0000000002")
+	writeTestCheckpoint(t, s, "aa0000000002")
+
+	dry, err := s.Migrate(true)
+	if err != nil {
+		t.Fatalf("Migrate(dry run): %v", err)
+	}
+	if len(dry.Migrated) != 1 {
+		t.Fatalf("dry run Migrated = %v", dry.Migrated)
+	}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch+":aa/0000000001"); err == nil {
+		t.Fatal("dry run migrated a checkpoint")
+	}
+
+	result, err := s.Migrate(false)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(result.Migrated) != 1 || result.Migrated[0] != "aa0000000001" {
+		t.Errorf("Migrated = %v", result.Migrated)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != "aa0000000002" {
+		t.Errorf("Skipped = %v", result.Skipped)
+	}
+	if len(result.Failed) != 0 {
+		t.Errorf("Failed = %v", result.Failed)
+	}
+
+	data, err := Read("aa0000000001")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if data.Metadata.SchemaVersion != SchemaVersion || data.Metadata.AgentPercent != 80 {
+		t.Errorf("Metadata = %+v", data.Metadata)
+	}
+
+	var sm SessionMetadata
+	raw, err := s.catBlob(checkpointBranch + ":aa/0000000001/0/metadata.json")
+	if err != nil || json.Unmarshal([]byte(raw), &sm) != nil {
+		t.Fatalf("reading session metadata: %v", err)
+	}
+	if sm.SchemaVersion != SchemaVersion || sm.Agent != "claude-code" || sm.TotalTokens != 42 {
+		t.Errorf("SessionMetadata = %+v", sm)
+	}
+
+	results, err := s.Verify([]string{"aa0000000001"}, false)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	for _, name := range []string{"manifest", "files"} {
+		if status := checkStatus(results[0], name); status != CheckOK {
+			t.Errorf("%s check = %v: %+v", name, status, results[0].Checks)
+		}
+	}
+
+	// The legacy branch is left as it was.
+	if _, err := s.git("rev-parse", "--verify", "--quiet", legacyCheckpointBranch+":aa/0000000001/manifest.json"); err == nil {
+		t.Error("legacy branch was modified")
+	}
+}
+
+func TestRequireMigrated(t *testing.T) {
+	s := initCheckpointRepo(t)
+	writeLegacyCheckpoint(t, s, "bb0000000001")
+	writeTestCheckpoint(t, s, "bb0000000002")
+
+	if _, err := s.Verify([]string{"bb0000000001"}, false); err == nil || !strings.Contains(err.Error(), "partio migrate") {
+		t.Errorf("Verify before migrating: error = %v, want one pointing at partio migrate", err)
+	}
+	if _, err := s.Clean(CleanOptions{DryRun: true}); err == nil {
+		t.Error("Clean ran with unmigrated legacy checkpoints")
+	}
+
+	if _, err := s.Migrate(false); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	results, err := s.Verify([]string{"bb0000000001"}, false)
+	if err != nil {
+		t.Fatalf("Verify after migrating: %v", err)
+	}
+	if checkStatus(results[0], "files") != CheckOK {
+		t.Errorf("migrated checkpoint fails verification: %+v", results[0].Checks)
+	}
+
+	// Pruning a migrated checkpoint neither brings the requirement back nor
+	// lets the next migration restore it.
+	if _, err := s.Prune(0, "0000000000000000000000000000000000000000", false); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if ids, err := s.Unmigrated(); err != nil || len(ids) != 0 {
+		t.Errorf("Unmigrated after prune = %v, %v", ids, err)
+	}
+	result, err := s.Migrate(false)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(result.Migrated) != 0 {
+		t.Errorf("Migrated after prune = %v, want none", result.Migrated)
+	}
+}
@@ -11,6 +11,9 @@ import (
 type PruneResult struct {
 	Removed []Metadata
 	Kept    []Metadata
+	// Reasons maps removed checkpoint IDs to why they were removed, when
+	// known.
+	Reasons map[string]string
 }
 
 // Prune removes checkpoints older than the given duration, but never removes
@@ -25,6 +28,9 @@ func (s *Store) Prune(olderThan time.Duration, currentCommitHash string, dryRun
 	if err != nil {
 		return result, nil
 	}
+	if err := s.requireMigrated(); err != nil {
+		return nil, err
+	}
 
 	// List all shards
 	shards, err := s.git("ls-tree", "--name-only", checkpointBranch)
@@ -106,8 +112,14 @@ func (s *Store) Prune(olderThan time.Duration, currentCommitHash string, dryRun
 		return nil, fmt.Errorf("getting parent commit: %w", err)
 	}
 
+	var removed []string
+	for _, meta := range result.Removed {
+		removed = append(removed, meta.ID)
+	}
+
 	commitMsg := fmt.Sprintf("prune: removed %d checkpoint(s)", len(result.Removed))
-	commitHash, err := s.git("commit-tree", newRoot, "-p", parentCommit, "-m", commitMsg)
+	commitMsg += "\n\n" + strings.Join(removedTrailerLines(removed, "pruned"), "\n")
+	commitHash, err := s.commitTree(newRoot, parentCommit, commitMsg)
 	if err != nil {
 		return nil, fmt.Errorf("creating commit: %w", err)
 	}
@@ -118,6 +130,8 @@ func (s *Store) Prune(olderThan time.Duration, currentCommitHash string, dryRun
 		return nil, fmt.Errorf("updating ref: %w", err)
 	}
 
+	s.dropSnapshots(removed)
+
 	return result, nil
 }
 
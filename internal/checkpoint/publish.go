@@ -0,0 +1,125 @@
+package checkpoint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PublishRefPrefix is the namespace of the local refs that accumulate the
+// checkpoints published to each remote.
+const PublishRefPrefix = "refs/partio/published/"
+
+// RemoteRefPrefix is the namespace of the local refs holding each remote's
+// copy of the checkpoints, fetched before publishing to it.
+const RemoteRefPrefix = "refs/partio/remotes/"
+
+// PublishRefSuffix ends every publish and remote ref
+// (<prefix><remote><suffix>). It follows the checkpoint branch's schema
+// version so that checkpoints published before a schema change are never
+// mixed into the new branch.
+const PublishRefSuffix = "/v2"
+
+// Publish copies the given checkpoints from the local checkpoint branch onto
+// ref, so that ref only ever contains checkpoints that were explicitly
+// published. base names the local copy of the remote's checkpoint ref, if
+// any. Unless ref already contains it, ref is rebuilt on top of base, keeping
+// the checkpoints only ref has, so the next push fast-forwards the remote even
+// after another clone published to it. Without either, ref starts from an
+// empty tree. Checkpoints already present, or missing from the checkpoint
+// branch, are skipped.
+//
+// It returns the number of checkpoints added; when zero, ref is left untouched
+// or moved to base.
+func (s *Store) Publish(ref, base string, ids []string) (int, error) {
+	current, _ := s.git("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	parent := current
+
+	// sources maps each checkpoint to add to its tree.
+	type source struct{ id, tree string }
+	var sources []source
+	if base != "" {
+		tip, err := s.git("rev-parse", "--verify", "--quiet", base+"^{commit}")
+		if err == nil && (current == "" || !s.isAncestor(tip, current)) {
+			if current != "" {
+				carried, err := s.treeEntries(current)
+				if err != nil {
+					return 0, err
+				}
+				for _, e := range carried {
+					sources = append(sources, source{e.id, e.tree})
+				}
+			}
+			parent = tip
+		}
+	}
+	for _, id := range ids {
+		cpTree, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch+":"+Shard(id)+"/"+Rest(id))
+		if err != nil {
+			continue // not stored locally
+		}
+		sources = append(sources, source{id, cpTree})
+	}
+
+	var tree string
+	var err error
+	if parent != "" {
+		tree, err = s.git("rev-parse", parent+"^{tree}")
+		if err != nil {
+			return 0, fmt.Errorf("reading tree of %s: %w", ref, err)
+		}
+	} else {
+		tree, err = s.mktree(nil)
+		if err != nil {
+			return 0, fmt.Errorf("creating empty tree: %w", err)
+		}
+	}
+
+	added := 0
+	var trailers []string
+	for _, src := range sources {
+		if _, err := s.git("rev-parse", "--verify", "--quiet", tree+":"+Shard(src.id)+"/"+Rest(src.id)); err == nil {
+			continue // already published
+		}
+		tree, err = s.addToTree(tree, Shard(src.id), Rest(src.id), src.tree)
+		if err != nil {
+			return 0, fmt.Errorf("adding checkpoint %s: %w", src.id, err)
+		}
+		added++
+
+		// Carry the manifest hash over so the published commit vouches for
+		// the checkpoint the same way the original checkpoint commit did.
+		if manifest := s.readManifest(src.tree); manifest != "" {
+			trailers = append(trailers, manifestTrailerLine(src.id, sha256Hex(manifest)))
+		}
+	}
+
+	if added == 0 {
+		if parent != current {
+			if _, err := s.git("update-ref", ref, parent); err != nil {
+				return 0, fmt.Errorf("updating ref: %w", err)
+			}
+		}
+		return 0, nil
+	}
+
+	msg := fmt.Sprintf("publish: %d checkpoint(s)", added)
+	if len(trailers) > 0 {
+		msg += "\n\n" + strings.Join(trailers, "\n")
+	}
+	commitHash, err := s.commitTree(tree, parent, msg)
+	if err != nil {
+		return 0, fmt.Errorf("creating commit: %w", err)
+	}
+
+	if _, err := s.git("update-ref", ref, commitHash); err != nil {
+		return 0, fmt.Errorf("updating ref: %w", err)
+	}
+
+	return added, nil
+}
+
+// isAncestor reports whether commit a is an ancestor of (or equal to) b.
+func (s *Store) isAncestor(a, b string) bool {
+	_, err := s.git("merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
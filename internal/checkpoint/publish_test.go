@@ -0,0 +1,97 @@
+package checkpoint
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// initCheckpointRepo creates a git repository with an empty checkpoint branch.
+func initCheckpointRepo(t *testing.T) *Store {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	if out, err := exec.Command("git", "init", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+
+	s := NewStore(dir)
+	tree, err := s.mktree(nil)
+	if err != nil {
+		t.Fatalf("mktree: %v", err)
+	}
+	commit, err := s.git("commit-tree", tree, "-m", "init")
+	if err != nil {
+		t.Fatalf("commit-tree: %v", err)
+	}
+	if _, err := s.git("update-ref", "refs/heads/"+checkpointBranch, commit); err != nil {
+		t.Fatalf("update-ref: %v", err)
+	}
+	return s
+}
+
+func writeTestCheckpoint(t *testing.T, s *Store, id string) {
+	t.Helper()
+	cp := &Checkpoint{ID: id, CommitHash: "c0ffee", Branch: "main", CreatedAt: time.Now()}
+	if err := s.Write(cp, &SessionFiles{Prompt: "prompt " + id}); err != nil {
+		t.Fatalf("Write(%s): %v", id, err)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	s := initCheckpointRepo(t)
+	writeTestCheckpoint(t, s, "aa0000000001")
+	writeTestCheckpoint(t, s, "aa0000000002")
+	writeTestCheckpoint(t, s, "bb0000000003")
+
+	const ref = "refs/partio/published/origin/v1"
+
+	added, err := s.Publish(ref, "", []string{"aa0000000001", "ffffffffffff"})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("added = %d, want 1", added)
+	}
+
+	files, err := s.git("ls-tree", "-r", "--name-only", ref)
+	if err != nil {
+		t.Fatalf("ls-tree: %v", err)
+	}
+	if !strings.Contains(files, "aa/0000000001/metadata.json") {
+		t.Errorf("published ref missing requested checkpoint:\n%s", files)
+	}
+	if strings.Contains(files, "0000000002") || strings.Contains(files, "0000000003") {
+		t.Errorf("published ref contains unrequested checkpoints:\n%s", files)
+	}
+
+	// Publishing again appends on top of the existing ref and skips duplicates.
+	first, _ := s.git("rev-parse", ref)
+	added, err = s.Publish(ref, "", []string{"aa0000000001", "bb0000000003"})
+	if err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("second added = %d, want 1", added)
+	}
+	parent, _ := s.git("rev-parse", ref+"^")
+	if parent != first {
+		t.Errorf("expected new publish commit to build on %s, got parent %s", first, parent)
+	}
+
+	// Nothing new to publish leaves the ref untouched.
+	before, _ := s.git("rev-parse", ref)
+	added, err = s.Publish(ref, "", []string{"bb0000000003"})
+	if err != nil {
+		t.Fatalf("third Publish: %v", err)
+	}
+	after, _ := s.git("rev-parse", ref)
+	if added != 0 || before != after {
+		t.Errorf("expected no-op publish, added=%d ref %s -> %s", added, before, after)
+	}
+}
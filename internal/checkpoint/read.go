@@ -4,32 +4,42 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/partio-io/cli/internal/encrypt"
 	"github.com/partio-io/cli/internal/git"
 )
 
 // CheckpointData holds all readable data from a stored checkpoint.
 type CheckpointData struct {
 	Metadata Metadata
+	// Sessions holds the metadata of each captured session, in
+	// subdirectory order.
+	Sessions []SessionMetadata
 	Prompt   string
 	Plan     string
 	Diff     string
 	Context  string
+	// Tools is the JSON array of the session's tool calls, or "" for
+	// checkpoints captured before tool calls were recorded.
+	Tools string
+	// Transcript is the session's normalized transcript.json, or "" for
+	// checkpoints captured before it was recorded.
+	Transcript string
 }
 
-// Read retrieves all checkpoint data from the orphan branch by ID.
+// Read retrieves all checkpoint data from the orphan branch by ID. Checkpoints
+// not yet migrated are read from the legacy v1 branch, with their metadata
+// upgraded in memory (SchemaVersion stays 1). Encrypted checkpoints are
+// decrypted with the local identity file (see encrypt.DefaultIdentityPath);
+// an error is returned if no identity can.
 func Read(id string) (*CheckpointData, error) {
 	if len(id) != 12 {
 		return nil, fmt.Errorf("checkpoint ID must be 12 characters (got %d)", len(id))
 	}
 
-	shard := Shard(id)
-	rest := Rest(id)
-	prefix := git.CheckpointBranch + ":" + shard + "/" + rest
-
 	// Read metadata (required)
-	metaJSON, err := git.ExecGit("show", prefix+"/metadata.json")
+	prefix, metaJSON, err := locate(id)
 	if err != nil {
-		return nil, fmt.Errorf("checkpoint %s not found", id)
+		return nil, err
 	}
 
 	var meta Metadata
@@ -37,17 +47,72 @@ func Read(id string) (*CheckpointData, error) {
 		return nil, fmt.Errorf("invalid checkpoint metadata: %w", err)
 	}
 
+	var sessions []SessionMetadata
+	if sessionJSON, err := git.ExecGit("show", prefix+"/0/metadata.json"); err == nil {
+		var sm SessionMetadata
+		if json.Unmarshal([]byte(sessionJSON), &sm) == nil {
+			sessions = append(sessions, sm)
+		}
+	}
+	meta.upgrade(sessions)
+
 	// Read optional files — missing files are not errors
 	prompt, _ := git.ExecGit("show", prefix+"/0/prompt.txt")
 	plan, _ := git.ExecGit("show", prefix+"/0/plan.md")
 	diff, _ := git.ExecGit("show", prefix+"/0/diff.patch")
 	context, _ := git.ExecGit("show", prefix+"/0/context.md")
+	tools, _ := git.ExecGit("show", prefix+"/0/tools.json")
+	transcript, _ := git.ExecGit("show", prefix+"/0/transcript.json")
+
+	data := &CheckpointData{
+		Metadata:   meta,
+		Sessions:   sessions,
+		Prompt:     prompt,
+		Plan:       plan,
+		Diff:       diff,
+		Context:    context,
+		Tools:      tools,
+		Transcript: transcript,
+	}
+
+	if meta.Encrypted {
+		identities, err := loadIdentities()
+		if err != nil {
+			return nil, err
+		}
+		if err := open(data, identities); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
 
-	return &CheckpointData{
-		Metadata: meta,
-		Prompt:   prompt,
-		Plan:     plan,
-		Diff:     diff,
-		Context:  context,
-	}, nil
+// locate finds checkpoint id on the checkpoint branch, or else the legacy
+// one. It returns the "<branch>:<shard>/<rest>" prefix of its files and its
+// metadata.json.
+func locate(id string) (prefix, metaJSON string, err error) {
+	for _, branch := range []string{checkpointBranch, legacyCheckpointBranch} {
+		prefix = branch + ":" + Shard(id) + "/" + Rest(id)
+		if metaJSON, err = git.ExecGit("show", prefix+"/metadata.json"); err == nil {
+			return prefix, metaJSON, nil
+		}
+	}
+	return "", "", fmt.Errorf("checkpoint %s not found", id)
+}
+
+// loadIdentities reads the local identity file, failing if it holds no keys.
+func loadIdentities() ([]*encrypt.Identity, error) {
+	path, err := encrypt.DefaultIdentityPath()
+	if err != nil {
+		return nil, err
+	}
+	identities, err := encrypt.LoadIdentities(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("checkpoint is encrypted and no identity key was found at %s", path)
+	}
+	return identities, nil
 }
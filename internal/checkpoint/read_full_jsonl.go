@@ -0,0 +1,39 @@
+package checkpoint
+
+import (
+	"fmt"
+
+	"github.com/partio-io/cli/internal/encrypt"
+	"github.com/partio-io/cli/internal/git"
+)
+
+// ReadFullJSONL returns the raw agent transcript (0/full.jsonl) of
+// checkpoint id, decrypted if needed. Read leaves it out since it is by far
+// the largest file of a checkpoint. It returns "" if none was captured.
+func ReadFullJSONL(id string) (string, error) {
+	if len(id) != 12 {
+		return "", fmt.Errorf("checkpoint ID must be 12 characters (got %d)", len(id))
+	}
+	prefix, _, err := locate(id)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := git.ExecGit("show", prefix+"/0/full.jsonl")
+	if err != nil || raw == "" {
+		return "", nil
+	}
+	if !encrypt.IsEncrypted(raw) {
+		return raw + "\n", nil
+	}
+
+	identities, err := loadIdentities()
+	if err != nil {
+		return "", err
+	}
+	plain, err := encrypt.Decrypt(raw, identities)
+	if err != nil {
+		return "", fmt.Errorf("checkpoint %s: %w", id, err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,77 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/encrypt"
+)
+
+func TestWriteReadEncrypted(t *testing.T) {
+	s := initCheckpointRepo(t)
+	t.Chdir(s.repoRoot)
+
+	id, err := encrypt.GenerateIdentity()
+	if err != nil {
+		t.Fatalf("GenerateIdentity: %v", err)
+	}
+	identityPath := filepath.Join(t.TempDir(), "identity.txt")
+	if err := encrypt.WriteIdentity(identityPath, id); err != nil {
+		t.Fatalf("WriteIdentity: %v", err)
+	}
+
+	s.SetRecipients([]*encrypt.Recipient{id.Recipient()})
+	cp := &Checkpoint{ID: "ab0123456789", CommitHash: "c0ffee", Branch: "main", CreatedAt: time.Now()}
+	files := &SessionFiles{
+		Prompt:     "internal project codename",
+		Plan:       "# plan",
+		Diff:       "+secret line",
+		Context:    "internal project",
+		FullJSONL:  `{"type":"user"}`,
+		Tools:      `[{"name":"Bash","input":{"command":"make deploy"}}]`,
+		Transcript: `{"version":1,"agent":"claude-code","messages":[]}`,
+	}
+	if err := s.Write(cp, files); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// Stored blobs must not contain plaintext; metadata stays readable.
+	stored, err := s.git("show", checkpointBranch+":ab/0123456789/0/prompt.txt")
+	if err != nil {
+		t.Fatalf("show prompt: %v", err)
+	}
+	if strings.Contains(stored, "codename") || !encrypt.IsEncrypted(stored) {
+		t.Errorf("prompt stored in clear: %q", stored)
+	}
+	metaJSON, _ := s.git("show", checkpointBranch+":ab/0123456789/metadata.json")
+	if !strings.Contains(metaJSON, `"encrypted": true`) {
+		t.Errorf("metadata missing encrypted flag: %s", metaJSON)
+	}
+
+	t.Run("decrypts with identity", func(t *testing.T) {
+		t.Setenv("PARTIO_IDENTITY_FILE", identityPath)
+		data, err := Read(cp.ID)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if data.Prompt != files.Prompt || data.Plan != files.Plan || data.Diff != files.Diff || data.Context != files.Context || data.Tools != files.Tools || data.Transcript != files.Transcript {
+			t.Errorf("decrypted data mismatch: %+v", data)
+		}
+		full, err := ReadFullJSONL(cp.ID)
+		if err != nil {
+			t.Fatalf("ReadFullJSONL: %v", err)
+		}
+		if full != files.FullJSONL {
+			t.Errorf("ReadFullJSONL = %q, want %q", full, files.FullJSONL)
+		}
+	})
+
+	t.Run("fails without identity", func(t *testing.T) {
+		t.Setenv("PARTIO_IDENTITY_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+		if _, err := Read(cp.ID); err == nil {
+			t.Error("expected error reading encrypted checkpoint without identity")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package checkpoint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// removedTrailer records "<id> <reason>" in the commit message of the
+// checkpoint branch commit that deliberately removed a checkpoint (prune,
+// retention, clean, archive), so fsck can tell it from a lost one.
+const removedTrailer = "Partio-Removed"
+
+// removedTrailerLines returns a Partio-Removed trailer line for each of ids,
+// sorted.
+func removedTrailerLines(ids []string, reason string) []string {
+	lines := make([]string, 0, len(ids))
+	for _, id := range ids {
+		lines = append(lines, fmt.Sprintf("%s: %s %s", removedTrailer, id, reason))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// removedCheckpoints maps the IDs of checkpoints removed in the history of
+// rev to the reason recorded by the newest commit that removed them.
+func (s *Store) removedCheckpoints(rev string) (map[string]string, error) {
+	out, err := s.git("log", "--format=%(trailers:key="+removedTrailer+",valueonly)", rev)
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint history: %w", err)
+	}
+
+	removed := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		id, reason, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if id == "" {
+			continue
+		}
+		if _, seen := removed[id]; !seen { // log is newest first
+			removed[id] = reason
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,236 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionRule limits how long and how many checkpoints are kept. Zero
+// values mean no limit.
+type RetentionRule struct {
+	MaxAge   time.Duration
+	MaxCount int
+	// Keep never removes checkpoints the rule applies to.
+	Keep bool
+}
+
+// BranchRetention applies Rule to checkpoints captured on branches matching
+// Pattern (path.Match syntax, e.g. "feature/*").
+type BranchRetention struct {
+	Pattern string
+	Rule    RetentionRule
+}
+
+// RetentionPolicy decides which checkpoints ApplyRetention removes.
+type RetentionPolicy struct {
+	// Default applies to checkpoints no branch override matches.
+	Default RetentionRule
+	// Branches are checked in order; the first matching pattern wins.
+	Branches []BranchRetention
+	// MaxTotalSize caps the combined size in bytes of stored transcripts
+	// (0/full.jsonl and 0/transcript.json), removing the oldest checkpoints first.
+	MaxTotalSize int64
+	// MainBranch, when set, protects checkpoints whose commit is reachable
+	// from it.
+	MainBranch string
+}
+
+// retained is a checkpoint considered by ApplyRetention.
+type retained struct {
+	id        string
+	meta      Metadata
+	createdAt time.Time
+	size      int64
+	protected bool
+}
+
+// ApplyRetention removes the checkpoints the policy no longer allows, in a
+// single checkpoint branch commit. The checkpoint linked to
+// currentCommitHash, checkpoints on the main branch (when configured),
+// checkpoints matched by a Keep rule and checkpoints with unreadable
+// metadata are never removed, and don't count toward MaxCount or
+// MaxTotalSize. Reasons maps each removed ID to the limit that removed it.
+// If dryRun is true, no changes are made.
+func (s *Store) ApplyRetention(policy RetentionPolicy, currentCommitHash string, dryRun bool) (*PruneResult, error) {
+	result := &PruneResult{Reasons: make(map[string]string)}
+	if err := s.requireMigrated(); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.entries()
+	if err != nil || len(stored) == 0 {
+		return result, err
+	}
+
+	sizes, err := s.transcriptSizes()
+	if err != nil {
+		return nil, err
+	}
+
+	var onMain *commitLinks
+	if policy.MainBranch != "" {
+		if onMain, err = s.scanCommitLinks("refs/heads/" + policy.MainBranch); err != nil {
+			return nil, err
+		}
+	}
+
+	// Group checkpoints by the rule that applies to them; -1 is the default.
+	groups := make(map[int][]*retained)
+	var all []*retained
+	for _, e := range stored {
+		var meta Metadata
+		data, err := s.catBlob(e.tree + ":metadata.json")
+		if err != nil || json.Unmarshal([]byte(data), &meta) != nil {
+			continue
+		}
+
+		cp := &retained{id: e.id, meta: meta, size: sizes[e.id]}
+		createdAt, err := time.Parse(time.RFC3339, meta.CreatedAt)
+		ruleIdx := policy.match(meta.Branch)
+		rule := policy.rule(ruleIdx)
+		cp.createdAt = createdAt
+		cp.protected = err != nil ||
+			rule.Keep ||
+			(currentCommitHash != "" && meta.CommitHash == currentCommitHash) ||
+			(onMain != nil && onMain.linked(e.id, meta.CommitHash))
+
+		all = append(all, cp)
+		groups[ruleIdx] = append(groups[ruleIdx], cp)
+	}
+
+	now := time.Now()
+	removed := make(map[string]bool)
+	for ruleIdx, group := range groups {
+		rule := policy.rule(ruleIdx)
+
+		sort.Slice(group, func(i, j int) bool { return group[i].createdAt.After(group[j].createdAt) })
+		count := 0
+		for _, cp := range group {
+			if cp.protected {
+				continue
+			}
+			switch {
+			case rule.MaxAge > 0 && now.Sub(cp.createdAt) > rule.MaxAge:
+				removed[cp.id] = true
+				result.Reasons[cp.id] = "older than " + formatAge(rule.MaxAge)
+			case rule.MaxCount > 0 && count >= rule.MaxCount:
+				removed[cp.id] = true
+				result.Reasons[cp.id] = fmt.Sprintf("beyond the newest %d", rule.MaxCount)
+			default:
+				count++
+			}
+		}
+	}
+
+	if policy.MaxTotalSize > 0 {
+		sort.Slice(all, func(i, j int) bool { return all[i].createdAt.Before(all[j].createdAt) })
+		var total int64
+		for _, cp := range all {
+			if !cp.protected && !removed[cp.id] {
+				total += cp.size
+			}
+		}
+		for _, cp := range all {
+			if total <= policy.MaxTotalSize {
+				break
+			}
+			if cp.protected || removed[cp.id] || cp.size == 0 {
+				continue
+			}
+			removed[cp.id] = true
+			result.Reasons[cp.id] = "transcripts exceed total size limit"
+			total -= cp.size
+		}
+	}
+
+	changes := make(map[string]string)
+	var removedIDs []string
+	sort.Slice(all, func(i, j int) bool { return all[i].id < all[j].id })
+	for _, cp := range all {
+		if removed[cp.id] {
+			result.Removed = append(result.Removed, cp.meta)
+			changes[cp.id] = ""
+			removedIDs = append(removedIDs, cp.id)
+		} else {
+			result.Kept = append(result.Kept, cp.meta)
+		}
+	}
+
+	if len(changes) == 0 || dryRun {
+		return result, nil
+	}
+
+	msg := fmt.Sprintf("prune: removed %d checkpoint(s) by retention policy", len(changes))
+	if err := s.updateCheckpoints(changes, msg, removedTrailerLines(removedIDs, "pruned by retention policy")); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// match returns the index of the first branch override matching branch, or
+// -1 when none does.
+func (p RetentionPolicy) match(branch string) int {
+	for i, b := range p.Branches {
+		if ok, _ := path.Match(b.Pattern, branch); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// rule returns the effective rule for a match index, with unset override
+// limits falling back to the default.
+func (p RetentionPolicy) rule(idx int) RetentionRule {
+	if idx < 0 {
+		return p.Default
+	}
+	rule := p.Branches[idx].Rule
+	if rule.MaxAge == 0 {
+		rule.MaxAge = p.Default.MaxAge
+	}
+	if rule.MaxCount == 0 {
+		rule.MaxCount = p.Default.MaxCount
+	}
+	return rule
+}
+
+// transcriptSizes returns the combined size of each checkpoint's raw and
+// normalized transcripts.
+func (s *Store) transcriptSizes() (map[string]int64, error) {
+	out, err := s.git("ls-tree", "-r", "-l", checkpointBranch)
+	if err != nil {
+		return nil, fmt.Errorf("listing checkpoint files: %w", err)
+	}
+
+	sizes := make(map[string]int64)
+	for _, line := range strings.Split(out, "\n") {
+		tabParts := strings.SplitN(line, "\t", 2)
+		parts := strings.Fields(line)
+		if len(tabParts) < 2 || len(parts) < 4 {
+			continue
+		}
+		p := strings.Split(tabParts[1], "/")
+		if len(p) != 4 || p[2] != "0" || (p[3] != "full.jsonl" && p[3] != "transcript.json") {
+			continue
+		}
+		size, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[p[0]+p[1]] += size
+	}
+	return sizes, nil
+}
+
+// formatAge renders whole days as "Nd" and anything else as a Go duration.
+func formatAge(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
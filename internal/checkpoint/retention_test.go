@@ -0,0 +1,109 @@
+package checkpoint
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyRetention(t *testing.T) {
+	s := initCheckpointRepo(t)
+	if _, err := s.git("checkout", "-q", "-b", "main"); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	const day = 24 * time.Hour
+	onMain := commitWithTrailer(t, s, "dd0000000001")
+	head := commitWithTrailer(t, s, "dd0000000002")
+
+	write := func(id, commit, branch string, age time.Duration, transcript string) {
+		t.Helper()
+		cp := &Checkpoint{ID: id, CommitHash: commit, Branch: branch, CreatedAt: time.Now().Add(-age)}
+		if err := s.Write(cp, &SessionFiles{Prompt: "prompt " + id, FullJSONL: transcript}); err != nil {
+			t.Fatalf("Write(%s): %v", id, err)
+		}
+	}
+	const gone = "9999999999999999999999999999999999999999"
+	write("dd0000000001", onMain, "main", 60*day, "")
+	write("dd0000000002", head, "feature/x", 20*day, "")
+	write("dd0000000003", gone, "feature/y", 2*day, "")
+	write("dd0000000004", gone, "main", 40*day, strings.Repeat("x", 1000))
+	write("dd0000000005", gone, "release/1", 100*day, "")
+	write("dd0000000006", gone, "main", 1*day, strings.Repeat("y", 500))
+
+	tests := []struct {
+		name    string
+		policy  RetentionPolicy
+		current string
+		removed string
+	}{
+		{
+			name:    "max age",
+			policy:  RetentionPolicy{Default: RetentionRule{MaxAge: 30 * day}},
+			removed: "dd0000000001 dd0000000004 dd0000000005",
+		},
+		{
+			name:    "keep if on main",
+			policy:  RetentionPolicy{Default: RetentionRule{MaxAge: 30 * day}, MainBranch: "main"},
+			removed: "dd0000000004 dd0000000005",
+		},
+		{
+			name:    "current commit is protected",
+			policy:  RetentionPolicy{Default: RetentionRule{MaxAge: 30 * day}},
+			current: onMain,
+			removed: "dd0000000004 dd0000000005",
+		},
+		{
+			name: "branch overrides",
+			policy: RetentionPolicy{
+				Default: RetentionRule{MaxAge: 50 * day},
+				Branches: []BranchRetention{
+					{Pattern: "feature/*", Rule: RetentionRule{MaxAge: 10 * day}},
+					{Pattern: "release/*", Rule: RetentionRule{Keep: true}},
+				},
+			},
+			removed: "dd0000000001 dd0000000002",
+		},
+		{
+			name:    "max count keeps newest",
+			policy:  RetentionPolicy{Default: RetentionRule{MaxCount: 2}},
+			removed: "dd0000000001 dd0000000002 dd0000000004 dd0000000005",
+		},
+		{
+			name:    "max total size removes oldest transcripts",
+			policy:  RetentionPolicy{MaxTotalSize: 1200},
+			removed: "dd0000000004",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.ApplyRetention(tt.policy, tt.current, true)
+			if err != nil {
+				t.Fatalf("ApplyRetention: %v", err)
+			}
+			var removed []string
+			for _, m := range result.Removed {
+				removed = append(removed, m.ID)
+				if result.Reasons[m.ID] == "" {
+					t.Errorf("no reason recorded for %s", m.ID)
+				}
+			}
+			if got := strings.Join(removed, " "); got != tt.removed {
+				t.Errorf("removed = %q, want %q", got, tt.removed)
+			}
+		})
+	}
+
+	result, err := s.ApplyRetention(RetentionPolicy{Default: RetentionRule{MaxCount: 1}}, "", false)
+	if err != nil {
+		t.Fatalf("ApplyRetention: %v", err)
+	}
+	ids, err := s.IDs()
+	if err != nil {
+		t.Fatalf("IDs: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "dd0000000006" || len(result.Kept) != 1 {
+		t.Errorf("remaining = %v, want [dd0000000006]", ids)
+	}
+}
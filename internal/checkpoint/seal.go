@@ -0,0 +1,36 @@
+package checkpoint
+
+import (
+	"fmt"
+
+	"github.com/partio-io/cli/internal/encrypt"
+)
+
+// seal returns a copy of sf with every sensitive text field encrypted to the
+// store's recipients. ContentHash and Metadata are left in clear.
+func (s *Store) seal(sf *SessionFiles) (*SessionFiles, error) {
+	out := *sf
+	for _, f := range []*string{&out.Context, &out.Diff, &out.FullJSONL, &out.Plan, &out.Prompt, &out.Tools, &out.Transcript} {
+		armored, err := encrypt.Encrypt([]byte(*f), s.recipients)
+		if err != nil {
+			return nil, err
+		}
+		*f = armored
+	}
+	return &out, nil
+}
+
+// open decrypts any encrypted fields of data in place using identities.
+func open(data *CheckpointData, identities []*encrypt.Identity) error {
+	for _, f := range []*string{&data.Prompt, &data.Plan, &data.Diff, &data.Context, &data.Tools, &data.Transcript} {
+		if !encrypt.IsEncrypted(*f) {
+			continue
+		}
+		plain, err := encrypt.Decrypt(*f, identities)
+		if err != nil {
+			return fmt.Errorf("checkpoint %s: %w", data.Metadata.ID, err)
+		}
+		*f = string(plain)
+	}
+	return nil
+}
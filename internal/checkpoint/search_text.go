@@ -0,0 +1,90 @@
+package checkpoint
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/partio-io/cli/internal/encrypt"
+)
+
+// textFiles are the session files searched as plain text: what the user
+// asked for and the context they gave.
+var textFiles = []string{"prompt.txt", "context.md"}
+
+// TextCandidates returns the IDs of checkpoints on either checkpoint branch
+// whose request or context contains at least one of terms, ignoring case,
+// using a single git grep per branch. Callers confirm a match with PlainText,
+// since encrypted files can match by chance.
+func (s *Store) TextCandidates(terms []string) (map[string]bool, error) {
+	ids := make(map[string]bool)
+	if len(terms) == 0 {
+		return ids, nil
+	}
+	for _, branch := range []string{checkpointBranch, legacyCheckpointBranch} {
+		if _, err := s.git("rev-parse", "--verify", "--quiet", branch); err != nil {
+			continue
+		}
+		args := []string{"grep", "-l", "-i", "-F"}
+		for _, t := range terms {
+			args = append(args, "-e", t)
+		}
+		args = append(args, branch, "--")
+		for _, name := range textFiles {
+			args = append(args, "*/"+name)
+		}
+
+		out, err := s.git(args...)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			continue // no matches
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(out, "\n") {
+			// <branch>:<shard>/<rest>/<session>/<file>
+			_, path, _ := strings.Cut(line, ":")
+			if parts := strings.Split(path, "/"); len(parts) == 4 {
+				ids[parts[0]+parts[1]] = true
+			}
+		}
+	}
+	return ids, nil
+}
+
+// PlainText returns the request and context of each session of checkpoint
+// id, joined by newlines. Encrypted files are left out rather than
+// decrypted.
+func (s *Store) PlainText(id string) (string, error) {
+	var tree string
+	for _, branch := range []string{checkpointBranch, legacyCheckpointBranch} {
+		if t, err := s.git("rev-parse", "--verify", "--quiet", branch+":"+Shard(id)+"/"+Rest(id)); err == nil {
+			tree = t
+			break
+		}
+	}
+	if tree == "" {
+		return "", fmt.Errorf("checkpoint %s not found", id)
+	}
+
+	sessions, err := s.git("ls-tree", "-d", "--name-only", tree)
+	if err != nil {
+		return "", err
+	}
+	var texts []string
+	for _, session := range strings.Split(sessions, "\n") {
+		if session == "" {
+			continue
+		}
+		for _, name := range textFiles {
+			data, err := s.catBlob(tree + ":" + session + "/" + name)
+			if err != nil || data == "" || encrypt.IsEncrypted(data) {
+				continue
+			}
+			texts = append(texts, data)
+		}
+	}
+	return strings.Join(texts, "\n"), nil
+}
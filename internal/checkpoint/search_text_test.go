@@ -0,0 +1,49 @@
+package checkpoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/encrypt"
+)
+
+func TestSearchText(t *testing.T) {
+	s := initCheckpointRepo(t)
+	write := func(id, prompt, context string) {
+		t.Helper()
+		cp := &Checkpoint{ID: id, CommitHash: "c0ffee", Branch: "main", CreatedAt: time.Now()}
+		if err := s.Write(cp, &SessionFiles{Prompt: prompt, Context: context}); err != nil {
+			t.Fatalf("Write(%s): %v", id, err)
+		}
+	}
+	write("aa0000000001", "Add retry logic", "")
+	write("aa0000000002", "Fix the parser", "Flaky upstream API")
+	writeLegacyCheckpoint(t, s, "aa0000000003") // prompt "legacy prompt"
+
+	id, err := encrypt.GenerateIdentity()
+	if err != nil {
+		t.Fatalf("GenerateIdentity: %v", err)
+	}
+	s.SetRecipients([]*encrypt.Recipient{id.Recipient()})
+	write("aa0000000004", "Add retry logic to the secret service", "")
+
+	got, err := s.TextCandidates([]string{"RETRY", "upstream", "legacy"})
+	if err != nil {
+		t.Fatalf("TextCandidates: %v", err)
+	}
+	for _, want := range []string{"aa0000000001", "aa0000000002", "aa0000000003"} {
+		if !got[want] {
+			t.Errorf("TextCandidates missed %s: %v", want, got)
+		}
+	}
+
+	if text, err := s.PlainText("aa0000000002"); err != nil || text != "Fix the parser\nFlaky upstream API" {
+		t.Errorf("PlainText = %q, %v", text, err)
+	}
+	if text, err := s.PlainText("aa0000000004"); err != nil || text != "" {
+		t.Errorf("PlainText of an encrypted checkpoint = %q, %v", text, err)
+	}
+	if _, err := s.PlainText("ffffffffffff"); err == nil {
+		t.Error("PlainText of an unknown checkpoint succeeded")
+	}
+}
@@ -0,0 +1,82 @@
+package checkpoint
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// snapshotRefPrefix holds the refs keeping working-tree snapshots of manual
+// checkpoints reachable. They are local: pushes skip them, while archives
+// carry the snapshots of the checkpoints they hold.
+const snapshotRefPrefix = "refs/partio/snapshots/"
+
+// SnapshotRef returns the ref of the working-tree snapshot of checkpoint id.
+func SnapshotRef(id string) string {
+	return snapshotRefPrefix + id
+}
+
+// Snapshot records the working tree of the repository as it is on disk,
+// including staged and unstaged changes and untracked files that are not
+// ignored, as a commit on top of head, the way git stash does, and keeps it
+// reachable under SnapshotRef(id). The index and working tree are not
+// modified. It returns the snapshot commit.
+func (s *Store) Snapshot(id, head, msg string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "partio-snapshot-")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary index: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// Start from a copy of the real index so unchanged files are not hashed
+	// again.
+	index := filepath.Join(tmpDir, "index")
+	env := []string{"GIT_INDEX_FILE=" + index}
+	if real, err := s.git("rev-parse", "--path-format=absolute", "--git-path", "index"); err == nil {
+		if data, err := os.ReadFile(real); err == nil {
+			if err := os.WriteFile(index, data, 0o644); err != nil {
+				return "", fmt.Errorf("copying index: %w", err)
+			}
+		}
+	}
+
+	if _, err := s.gitEnv(env, "add", "--all", "--", "."); err != nil {
+		return "", fmt.Errorf("staging working tree: %w", err)
+	}
+	tree, err := s.gitEnv(env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("writing working tree: %w", err)
+	}
+
+	commit, err := s.commitTree(tree, head, msg)
+	if err != nil {
+		return "", fmt.Errorf("creating snapshot commit: %w", err)
+	}
+	if _, err := s.git("update-ref", SnapshotRef(id), commit); err != nil {
+		return "", fmt.Errorf("updating snapshot ref: %w", err)
+	}
+	return commit, nil
+}
+
+// dropSnapshots deletes the snapshot refs of removed checkpoints.
+func (s *Store) dropSnapshots(ids []string) {
+	for _, id := range ids {
+		if _, err := s.git("rev-parse", "--verify", "--quiet", SnapshotRef(id)); err == nil {
+			_, _ = s.git("update-ref", "-d", SnapshotRef(id))
+		}
+	}
+}
+
+// gitEnv runs git with extra environment variables.
+func (s *Store) gitEnv(env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = s.repoRoot
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,69 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	s := initCheckpointRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(s.repoRoot, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".gitignore", "*.log\n")
+	write("tracked.txt", "v1\n")
+	if _, err := s.git("add", "."); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := s.git("commit", "-m", "base"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	head, _ := s.git("rev-parse", "HEAD")
+
+	write("tracked.txt", "v2\n")
+	write("staged.txt", "staged\n")
+	if _, err := s.git("add", "staged.txt"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	write("untracked.txt", "new\n")
+	write("debug.log", "ignored\n")
+	statusBefore, _ := s.git("status", "--porcelain")
+
+	const id = "dd0000000001"
+	snap, err := s.Snapshot(id, head, "snapshot")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	if parent, _ := s.git("rev-parse", snap+"^"); parent != head {
+		t.Errorf("snapshot parent = %s, want %s", parent, head)
+	}
+	if ref, _ := s.git("rev-parse", SnapshotRef(id)); ref != snap {
+		t.Errorf("%s = %s, want %s", SnapshotRef(id), ref, snap)
+	}
+	for path, want := range map[string]string{"tracked.txt": "v2", "staged.txt": "staged", "untracked.txt": "new"} {
+		if got, err := s.git("show", snap+":"+path); err != nil || got != want {
+			t.Errorf("%s in snapshot = %q (%v), want %q", path, got, err, want)
+		}
+	}
+	if _, err := s.git("cat-file", "-e", snap+":debug.log"); err == nil {
+		t.Error("ignored file was included in the snapshot")
+	}
+	if statusAfter, _ := s.git("status", "--porcelain"); statusAfter != statusBefore {
+		t.Errorf("working tree status changed:\n%s\nwant:\n%s", statusAfter, statusBefore)
+	}
+
+	// Removing the checkpoint drops its snapshot.
+	writeTestCheckpoint(t, s, id)
+	if err := s.updateCheckpoints(map[string]string{id: ""}, "remove", nil); err != nil {
+		t.Fatalf("updateCheckpoints: %v", err)
+	}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", SnapshotRef(id)); err == nil {
+		t.Error("snapshot ref kept after its checkpoint was removed")
+	}
+}
@@ -0,0 +1,146 @@
+package checkpoint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SquashResult describes a checkpoint history rewrite.
+type SquashResult struct {
+	OldTip      string
+	NewTip      string
+	Checkpoints int
+	// PublishRefs lists the rewritten publish refs (see PublishRefPrefix);
+	// they must be force-pushed to their remotes.
+	PublishRefs []string
+	// RemoteRefs lists the deleted copies of remote checkpoint refs (see
+	// RemoteRefPrefix); the next push fetches them again.
+	RemoteRefs []string
+	// Legacy is true when the legacy v1 branch still exists and keeps its
+	// history reachable.
+	Legacy bool
+}
+
+// SquashHistory rewrites the checkpoint branch into a single root commit
+// holding its current tree, so checkpoints removed by earlier prunes are no
+// longer reachable through history. Each publish ref is rewritten the same
+// way, with the checkpoints in drop removed from it, and the local copies of
+// the remotes' checkpoint refs are deleted. The reflogs of the rewritten refs
+// are expired so that `git gc` can reclaim the old objects once no
+// remote-tracking ref (or the legacy branch) points at them either.
+func (s *Store) SquashHistory(drop []string) (*SquashResult, error) {
+	oldTip, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint branch does not exist")
+	}
+	if err := s.requireMigrated(); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	newTip, err := s.squashRef("refs/heads/"+checkpointBranch, oldTip, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &SquashResult{OldTip: oldTip, NewTip: newTip, Checkpoints: len(stored)}
+
+	refs, err := s.git("for-each-ref", "--format=%(refname)", PublishRefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing publish refs: %w", err)
+	}
+	for _, ref := range strings.Split(refs, "\n") {
+		if ref == "" {
+			continue
+		}
+		tip, err := s.git("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+		if err != nil {
+			continue
+		}
+		if _, err := s.squashRef(ref, tip, drop); err != nil {
+			return nil, fmt.Errorf("rewriting %s: %w", ref, err)
+		}
+		result.PublishRefs = append(result.PublishRefs, ref)
+	}
+
+	refs, err = s.git("for-each-ref", "--format=%(refname)", RemoteRefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing remote checkpoint refs: %w", err)
+	}
+	for _, ref := range strings.Split(refs, "\n") {
+		if ref == "" {
+			continue
+		}
+		if _, err := s.git("update-ref", "-d", ref); err != nil {
+			return nil, fmt.Errorf("deleting %s: %w", ref, err)
+		}
+		result.RemoteRefs = append(result.RemoteRefs, ref)
+	}
+
+	_, err = s.git("rev-parse", "--verify", "--quiet", legacyCheckpointBranch)
+	result.Legacy = err == nil
+
+	return result, nil
+}
+
+// squashRef replaces ref (currently at tip) with a parentless commit of its
+// tree minus the checkpoints in drop. The commit message carries a manifest
+// trailer for every remaining checkpoint so Verify keeps working, and the
+// removal trailers of the old history so Fsck does too.
+func (s *Store) squashRef(ref, tip string, drop []string) (string, error) {
+	tree, err := s.git("rev-parse", tip+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("reading tree: %w", err)
+	}
+
+	if len(drop) > 0 {
+		changes := make(map[string]string, len(drop))
+		for _, id := range drop {
+			changes[id] = ""
+		}
+		if tree, err = s.applyChanges(tree, changes); err != nil {
+			return "", err
+		}
+	}
+
+	entries, err := s.treeEntries(tree)
+	if err != nil {
+		return "", err
+	}
+	removed, err := s.removedCheckpoints(tip)
+	if err != nil {
+		return "", err
+	}
+	var trailers []string
+	for _, e := range entries {
+		delete(removed, e.id)
+		if manifest := s.readManifest(e.tree); manifest != "" {
+			trailers = append(trailers, manifestTrailerLine(e.id, sha256Hex(manifest)))
+		}
+	}
+	// Keep the record of deliberate removals the squashed history held.
+	for id, reason := range removed {
+		trailers = append(trailers, removedTrailerLines([]string{id}, reason)...)
+	}
+	sort.Strings(trailers)
+
+	msg := fmt.Sprintf("squash: %d checkpoint(s)", len(entries))
+	if len(trailers) > 0 {
+		msg += "\n\n" + strings.Join(trailers, "\n")
+	}
+	commit, err := s.commitTree(tree, "", msg)
+	if err != nil {
+		return "", fmt.Errorf("creating commit: %w", err)
+	}
+
+	// Only move the ref if nobody updated it meanwhile.
+	if _, err := s.git("update-ref", "-m", "partio: squash history", ref, commit, tip); err != nil {
+		return "", fmt.Errorf("updating ref: %w", err)
+	}
+	_, _ = s.git("reflog", "expire", "--expire=now", ref)
+	return commit, nil
+}
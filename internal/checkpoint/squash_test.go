@@ -0,0 +1,78 @@
+package checkpoint
+
+import "testing"
+
+func TestSquashHistory(t *testing.T) {
+	s := initCheckpointRepo(t)
+	writeTestCheckpoint(t, s, "ee0000000001")
+	writeTestCheckpoint(t, s, "ee0000000002")
+	writeTestCheckpoint(t, s, "ee0000000003")
+
+	const publishRef = PublishRefPrefix + "origin/v1"
+	if _, err := s.Publish(publishRef, "", []string{"ee0000000001", "ee0000000002"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	// A fetched copy of the remote's checkpoints from before the prune.
+	const remoteRef = RemoteRefPrefix + "origin" + PublishRefSuffix
+	if _, err := s.git("update-ref", remoteRef, checkpointBranch); err != nil {
+		t.Fatalf("update-ref: %v", err)
+	}
+
+	dropped, err := s.git("rev-parse", checkpointBranch+":ee/0000000002/0/prompt.txt")
+	if err != nil {
+		t.Fatalf("rev-parse: %v", err)
+	}
+	if err := s.updateCheckpoints(map[string]string{"ee0000000002": ""}, "prune", nil); err != nil {
+		t.Fatalf("updateCheckpoints: %v", err)
+	}
+
+	result, err := s.SquashHistory([]string{"ee0000000002"})
+	if err != nil {
+		t.Fatalf("SquashHistory: %v", err)
+	}
+	if result.Checkpoints != 2 {
+		t.Errorf("Checkpoints = %d, want 2", result.Checkpoints)
+	}
+	if len(result.PublishRefs) != 1 || result.PublishRefs[0] != publishRef {
+		t.Errorf("PublishRefs = %v, want [%s]", result.PublishRefs, publishRef)
+	}
+	if len(result.RemoteRefs) != 1 || result.RemoteRefs[0] != remoteRef {
+		t.Errorf("RemoteRefs = %v, want [%s]", result.RemoteRefs, remoteRef)
+	}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", remoteRef); err == nil {
+		t.Errorf("%s survived the squash", remoteRef)
+	}
+
+	for _, ref := range []string{checkpointBranch, publishRef} {
+		if n, _ := s.git("rev-list", "--count", ref); n != "1" {
+			t.Errorf("%s has %s commits, want 1", ref, n)
+		}
+	}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", publishRef+":ee/0000000002"); err == nil {
+		t.Error("dropped checkpoint still published")
+	}
+	if _, err := s.git("rev-parse", "--verify", "--quiet", publishRef+":ee/0000000001"); err != nil {
+		t.Error("published checkpoint lost")
+	}
+
+	results, err := s.Verify(nil, false)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 checkpoints after squash, got %d", len(results))
+	}
+	for _, r := range results {
+		if checkStatus(r, "manifest") != CheckOK {
+			t.Errorf("%s manifest not recorded after squash: %+v", r.ID, r.Checks)
+		}
+	}
+
+	// The pruned checkpoint's data is no longer reachable and can be collected.
+	if _, err := s.git("gc", "--prune=now", "--quiet"); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if _, err := s.git("cat-file", "-e", dropped); err == nil {
+		t.Error("pruned blob survived gc")
+	}
+}
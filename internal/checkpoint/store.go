@@ -4,13 +4,21 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/partio-io/cli/internal/encrypt"
+	"github.com/partio-io/cli/internal/git"
 )
 
-const checkpointBranch = "partio/checkpoints/v1"
+const (
+	checkpointBranch       = git.CheckpointBranch
+	legacyCheckpointBranch = git.LegacyCheckpointBranch
+)
 
 // Store writes checkpoint data to the orphan branch using git plumbing.
 type Store struct {
-	repoRoot string
+	repoRoot   string
+	recipients []*encrypt.Recipient
+	sign       bool
 }
 
 // NewStore creates a new checkpoint store.
@@ -18,6 +26,12 @@ func NewStore(repoRoot string) *Store {
 	return &Store{repoRoot: repoRoot}
 }
 
+// SetRecipients enables encryption of sensitive session files for all
+// subsequent writes. Passing no recipients disables encryption.
+func (s *Store) SetRecipients(recipients []*encrypt.Recipient) {
+	s.recipients = recipients
+}
+
 // SessionFiles holds the files to be stored for a session.
 type SessionFiles struct {
 	ContentHash string
@@ -27,6 +41,18 @@ type SessionFiles struct {
 	Metadata    SessionMetadata
 	Plan        string
 	Prompt      string
+	// Tools is the session's tool calls as a JSON array.
+	Tools string
+	// Transcript is the session in partio's normalized transcript format
+	// (see agent.Transcript).
+	Transcript string
+}
+
+// SetSigning makes all subsequent checkpoint commits signed with the user's
+// git signing configuration (user.signingkey, gpg.format). Commits are also
+// signed without this when git's commit.gpgSign is set.
+func (s *Store) SetSigning(enabled bool) {
+	s.sign = enabled
 }
 
 type treeEntry struct {
@@ -52,7 +78,11 @@ func (s *Store) mktree(entries []treeEntry) (string, error) {
 	for _, e := range entries {
 		lines = append(lines, fmt.Sprintf("%s %s %s\t%s", e.mode, e.typ, e.hash, e.name))
 	}
-	input := strings.Join(lines, "\n") + "\n"
+	// An empty tree needs empty input; a lone newline is rejected by mktree.
+	input := ""
+	if len(lines) > 0 {
+		input = strings.Join(lines, "\n") + "\n"
+	}
 
 	cmd := exec.Command("git", "mktree")
 	cmd.Dir = s.repoRoot
@@ -64,6 +94,26 @@ func (s *Store) mktree(entries []treeEntry) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// ensureBranch creates the checkpoint branch with an empty initial commit if
+// it does not exist yet, e.g. in repositories enabled before schema v2.
+func (s *Store) ensureBranch() error {
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch); err == nil {
+		return nil
+	}
+	emptyTree, err := s.mktree(nil)
+	if err != nil {
+		return fmt.Errorf("creating empty tree: %w", err)
+	}
+	commit, err := s.commitTree(emptyTree, "", "partio: initialize checkpoint storage")
+	if err != nil {
+		return fmt.Errorf("creating initial commit: %w", err)
+	}
+	if _, err := s.git("update-ref", "refs/heads/"+checkpointBranch, commit, ""); err != nil {
+		return fmt.Errorf("creating branch ref: %w", err)
+	}
+	return nil
+}
+
 func (s *Store) getCurrentTree() (string, error) {
 	return s.git("rev-parse", checkpointBranch+"^{tree}")
 }
@@ -173,6 +223,32 @@ func (s *Store) addToTree(currentTree, shard, rest, cpTree string) (string, erro
 	return s.mktree(rootEntries)
 }
 
+// commitTree creates a commit for tree with an optional parent, signing it
+// when signing is enabled.
+func (s *Store) commitTree(tree, parent, msg string) (string, error) {
+	args := []string{"commit-tree", tree}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	if s.sign {
+		args = append(args, "-S")
+	}
+	args = append(args, "-m", msg)
+	return s.git(args...)
+}
+
+// catBlob returns the exact content of a blob. Unlike git, it does not trim
+// whitespace, so the result can be hashed and compared byte-for-byte.
+func (s *Store) catBlob(spec string) (string, error) {
+	cmd := exec.Command("git", "cat-file", "blob", spec)
+	cmd.Dir = s.repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
 func (s *Store) git(args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
 	cmd.Dir = s.repoRoot
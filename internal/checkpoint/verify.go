@@ -0,0 +1,288 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CheckStatus is the outcome of a single verification check.
+type CheckStatus string
+
+const (
+	CheckOK   CheckStatus = "ok"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// Check is one verification step for a checkpoint.
+type Check struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+}
+
+// VerifyResult holds all checks run against a single checkpoint.
+type VerifyResult struct {
+	ID     string
+	Checks []Check
+}
+
+// OK returns true when no check failed.
+func (r *VerifyResult) OK() bool {
+	for _, c := range r.Checks {
+		if c.Status == CheckFail {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *VerifyResult) add(name string, status CheckStatus, format string, args ...any) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// manifestRecord is a Partio-Manifest trailer found on the checkpoint branch.
+type manifestRecord struct {
+	commit    string
+	hash      string
+	signature string // git's %G? code
+	key       string // the signing key, when signed
+}
+
+// Verify checks the integrity of the given checkpoints, or of every
+// checkpoint when ids is empty:
+//   - every file matches the checkpoint's manifest, and no file is unlisted;
+//   - the manifest hash matches the one recorded by the checkpoint branch
+//     commit that added it, and that commit's signature is valid;
+//   - the linked code commit exists and carries a matching Partio-Checkpoint
+//     trailer.
+//
+// Unsigned commits are reported as warnings unless requireSignature is set.
+func (s *Store) Verify(ids []string, requireSignature bool) ([]VerifyResult, error) {
+	if _, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch); err != nil {
+		return nil, fmt.Errorf("checkpoint branch does not exist")
+	}
+	if err := s.requireMigrated(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		all, err := s.IDs()
+		if err != nil {
+			return nil, fmt.Errorf("listing checkpoints: %w", err)
+		}
+		ids = all
+	}
+
+	records, err := s.manifestRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []VerifyResult
+	for _, id := range ids {
+		results = append(results, s.verifyOne(id, records[id], requireSignature))
+	}
+	return results, nil
+}
+
+func (s *Store) verifyOne(id string, record *manifestRecord, requireSignature bool) VerifyResult {
+	result := VerifyResult{ID: id}
+
+	cpTree, err := s.git("rev-parse", "--verify", "--quiet", checkpointBranch+":"+Shard(id)+"/"+Rest(id))
+	if err != nil {
+		result.add("checkpoint", CheckFail, "not found on %s", checkpointBranch)
+		return result
+	}
+
+	var meta Metadata
+	metaJSON, err := s.catBlob(cpTree + ":metadata.json")
+	if err != nil || json.Unmarshal([]byte(metaJSON), &meta) != nil {
+		result.add("metadata", CheckFail, "metadata.json missing or invalid")
+	}
+
+	manifestData := s.readManifest(cpTree)
+	switch {
+	case manifestData == "":
+		result.add("manifest", CheckWarn, "no manifest (checkpoint predates manifests)")
+	default:
+		s.verifyManifest(&result, cpTree, manifestData)
+
+		manifestHash := sha256Hex(manifestData)
+		switch {
+		case record == nil:
+			result.add("manifest", CheckFail, "no checkpoint branch commit records this manifest")
+		case record.hash != manifestHash:
+			result.add("manifest", CheckFail, "manifest hash differs from the one recorded in %s", shortHash(record.commit))
+		default:
+			result.add("manifest", CheckOK, "hash recorded in %s", shortHash(record.commit))
+		}
+	}
+
+	if record != nil {
+		status, detail := signatureCheck(record.signature, record.key, requireSignature)
+		result.add("signature", status, "%s (%s)", detail, shortHash(record.commit))
+	} else if requireSignature {
+		result.add("signature", CheckFail, "no signed commit found for this checkpoint")
+	}
+
+	switch {
+	case meta.Manual:
+		s.verifySnapshot(&result, id, meta)
+	case meta.CommitHash != "":
+		s.verifyLinkedCommit(&result, id, meta.CommitHash)
+	}
+
+	return result
+}
+
+// verifyManifest compares every file in the checkpoint tree with the manifest.
+func (s *Store) verifyManifest(result *VerifyResult, cpTree, manifestData string) {
+	var m Manifest
+	if err := json.Unmarshal([]byte(manifestData), &m); err != nil {
+		result.add("files", CheckFail, "manifest.json is invalid: %v", err)
+		return
+	}
+
+	listing, err := s.git("ls-tree", "-r", "--name-only", cpTree)
+	if err != nil {
+		result.add("files", CheckFail, "cannot list checkpoint files: %v", err)
+		return
+	}
+	present := make(map[string]bool)
+	for _, p := range strings.Split(listing, "\n") {
+		if p != "" && p != manifestFile {
+			present[p] = true
+		}
+	}
+
+	var problems []string
+	for _, p := range m.paths() {
+		if !present[p] {
+			problems = append(problems, p+" missing")
+			continue
+		}
+		delete(present, p)
+		content, err := s.catBlob(cpTree + ":" + p)
+		if err != nil || sha256Hex(content) != m.Files[p] {
+			problems = append(problems, p+" modified")
+		}
+	}
+	for p := range present {
+		problems = append(problems, p+" not in manifest")
+	}
+
+	if len(problems) > 0 {
+		result.add("files", CheckFail, "%s", strings.Join(problems, ", "))
+		return
+	}
+	result.add("files", CheckOK, "%d file(s) match manifest", len(m.Files))
+}
+
+// verifyLinkedCommit checks that the code commit exists and links back.
+func (s *Store) verifyLinkedCommit(result *VerifyResult, id, commitHash string) {
+	if _, err := s.git("cat-file", "-e", commitHash+"^{commit}"); err != nil {
+		result.add("commit", CheckFail, "linked commit %s does not exist", shortHash(commitHash))
+		return
+	}
+	trailers, err := s.git("log", "-1", "--format=%(trailers:key="+Trailer+",valueonly)", commitHash)
+	if err != nil {
+		result.add("commit", CheckFail, "cannot read trailers of %s: %v", shortHash(commitHash), err)
+		return
+	}
+	for _, v := range strings.Split(trailers, "\n") {
+		if strings.TrimSpace(v) == id {
+			result.add("commit", CheckOK, "%s carries matching trailer", shortHash(commitHash))
+			return
+		}
+	}
+	result.add("commit", CheckFail, "%s has no Partio-Checkpoint: %s trailer", shortHash(commitHash), id)
+}
+
+// verifySnapshot checks that a manual checkpoint's working-tree snapshot sits
+// on the commit it was taken on. Snapshot refs are never pushed, so one that
+// is missing (as in any other clone) is only a warning. Manual checkpoints
+// have no commit trailer to check.
+func (s *Store) verifySnapshot(result *VerifyResult, id string, meta Metadata) {
+	snap, err := s.git("rev-parse", "--verify", "--quiet", SnapshotRef(id))
+	switch {
+	case err != nil:
+		result.add("snapshot", CheckWarn, "%s is not in this clone; snapshots are kept locally only", SnapshotRef(id))
+	case snap != meta.Snapshot:
+		result.add("snapshot", CheckFail, "%s points at %s, not %s", SnapshotRef(id), shortHash(snap), shortHash(meta.Snapshot))
+	default:
+		parent, _ := s.git("rev-parse", "--verify", "--quiet", snap+"^")
+		if parent != meta.CommitHash {
+			result.add("snapshot", CheckFail, "snapshot %s is not based on %s", shortHash(snap), shortHash(meta.CommitHash))
+			return
+		}
+		result.add("snapshot", CheckOK, "working tree snapshot %s on %s", shortHash(snap), shortHash(meta.CommitHash))
+	}
+}
+
+// manifestRecords maps checkpoint IDs to the newest checkpoint branch commit
+// recording their manifest hash.
+func (s *Store) manifestRecords() (map[string]*manifestRecord, error) {
+	out, err := s.git("log", "--format=%H%x00%G?%x00%GK%x00%(trailers:key="+manifestTrailer+",valueonly)%x1e", checkpointBranch)
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint history: %w", err)
+	}
+
+	records := make(map[string]*manifestRecord)
+	for _, entry := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(entry), "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		for _, line := range strings.Split(fields[3], "\n") {
+			parts := strings.Fields(line)
+			if len(parts) != 2 {
+				continue
+			}
+			if _, seen := records[parts[0]]; seen {
+				continue // log is newest first; keep the newest record
+			}
+			records[parts[0]] = &manifestRecord{commit: fields[0], hash: parts[1], signature: fields[1], key: fields[2]}
+		}
+	}
+	return records, nil
+}
+
+// signatureCheck maps git's %G? signature code for a commit signed with key
+// to a check status.
+func signatureCheck(code, key string, requireSignature bool) (CheckStatus, string) {
+	switch code {
+	case "G":
+		return CheckOK, "good signature"
+	case "U":
+		detail := fmt.Sprintf("good signature from untrusted key %s", key)
+		if requireSignature {
+			return CheckFail, detail
+		}
+		return CheckWarn, detail
+	case "N":
+		if requireSignature {
+			return CheckFail, "commit is not signed"
+		}
+		return CheckWarn, "commit is not signed"
+	case "B":
+		return CheckFail, "bad signature"
+	case "R":
+		return CheckFail, "signed with a revoked key"
+	case "X", "Y":
+		return CheckWarn, "signature or key has expired"
+	default: // "E": cannot be checked, e.g. missing public key
+		if requireSignature {
+			return CheckFail, "signature cannot be checked"
+		}
+		return CheckWarn, "signature cannot be checked"
+	}
+}
+
+func shortHash(h string) string {
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
@@ -0,0 +1,179 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tamperCheckpointFile commits a change to a single file on the checkpoint
+// branch without updating its manifest.
+func tamperCheckpointFile(t *testing.T, s *Store, path, content string) {
+	t.Helper()
+	blob, err := s.hashObject(content)
+	if err != nil {
+		t.Fatalf("hash-object: %v", err)
+	}
+	index := filepath.Join(t.TempDir(), "index")
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = s.repoRoot
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+index)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("read-tree", checkpointBranch)
+	run("update-index", "--cacheinfo", "100644,"+blob+","+path)
+	tree := run("write-tree")
+	commit := run("commit-tree", tree, "-p", checkpointBranch, "-m", "tamper")
+	run("update-ref", "refs/heads/"+checkpointBranch, commit)
+}
+
+func checkStatus(r VerifyResult, name string) CheckStatus {
+	status := CheckOK
+	for _, c := range r.Checks {
+		if c.Name == name && c.Status != CheckOK {
+			status = c.Status
+		}
+	}
+	return status
+}
+
+func TestVerify(t *testing.T) {
+	s := initCheckpointRepo(t)
+
+	const id = "ab0123456789"
+	if _, err := s.git("commit", "--allow-empty", "-m", "feature\n\nPartio-Checkpoint: "+id); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	head, _ := s.git("rev-parse", "HEAD")
+
+	cp := &Checkpoint{ID: id, CommitHash: head, Branch: "main", CreatedAt: time.Now()}
+	if err := s.Write(cp, &SessionFiles{Prompt: "do the thing", Diff: "+line\n"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	unlinked := &Checkpoint{ID: "cd0123456789", CommitHash: head, Branch: "main", CreatedAt: time.Now()}
+	if err := s.Write(unlinked, &SessionFiles{Prompt: "other"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	results, err := s.Verify(nil, false)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		switch r.ID {
+		case id:
+			if !r.OK() {
+				t.Errorf("expected %s to verify, got %+v", id, r.Checks)
+			}
+			if got := checkStatus(r, "signature"); got != CheckWarn {
+				t.Errorf("unsigned commit status = %s, want warn", got)
+			}
+		case unlinked.ID:
+			if got := checkStatus(r, "commit"); got != CheckFail {
+				t.Errorf("commit without trailer status = %s, want fail", got)
+			}
+		}
+	}
+
+	// Requiring signatures turns the unsigned warning into a failure.
+	results, err = s.Verify([]string{id}, true)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if results[0].OK() {
+		t.Error("expected unsigned checkpoint to fail with requireSignature")
+	}
+
+	// Modifying a file after the fact is detected.
+	tamperCheckpointFile(t, s, "ab/0123456789/0/prompt.txt", "something else")
+	results, err = s.Verify([]string{id}, false)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got := checkStatus(results[0], "files"); got != CheckFail {
+		t.Errorf("tampered file status = %s, want fail", got)
+	}
+}
+
+func TestSignatureCheck(t *testing.T) {
+	tests := []struct {
+		code    string
+		require bool
+		want    CheckStatus
+	}{
+		{code: "G", want: CheckOK},
+		{code: "U", want: CheckWarn},
+		{code: "U", require: true, want: CheckFail},
+		{code: "N", want: CheckWarn},
+		{code: "N", require: true, want: CheckFail},
+		{code: "B", want: CheckFail},
+		{code: "E", want: CheckWarn},
+	}
+	for _, tt := range tests {
+		got, detail := signatureCheck(tt.code, "ABCD1234", tt.require)
+		if got != tt.want {
+			t.Errorf("signatureCheck(%q, require=%v) = %s (%s), want %s", tt.code, tt.require, got, detail, tt.want)
+		}
+	}
+	if _, detail := signatureCheck("U", "ABCD1234", false); !strings.Contains(detail, "ABCD1234") {
+		t.Errorf("untrusted key detail %q does not name the key", detail)
+	}
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	s := initCheckpointRepo(t)
+	if _, err := s.git("commit", "--allow-empty", "-m", "base"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	head, _ := s.git("rev-parse", "HEAD")
+
+	const id = "dd0000000002"
+	snap, err := s.Snapshot(id, head, "snapshot")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	cp := &Checkpoint{ID: id, CommitHash: head, Branch: "main", CreatedAt: time.Now(), Manual: true, Snapshot: snap}
+	if err := s.Write(cp, &SessionFiles{Prompt: "wip"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ref  string // where the snapshot ref points; "" deletes it
+		want CheckStatus
+	}{
+		{name: "kept", ref: snap, want: CheckOK},
+		{name: "moved", ref: head, want: CheckFail},
+		{name: "not in this clone", want: CheckWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := []string{"update-ref", "-d", SnapshotRef(id)}
+			if tt.ref != "" {
+				args = []string{"update-ref", SnapshotRef(id), tt.ref}
+			}
+			if _, err := s.git(args...); err != nil {
+				t.Fatalf("update-ref: %v", err)
+			}
+			results, err := s.Verify([]string{id}, false)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if got := checkStatus(results[0], "snapshot"); got != tt.want {
+				t.Errorf("snapshot status = %s, want %s: %+v", got, tt.want, results[0].Checks)
+			}
+		})
+	}
+}
@@ -10,74 +10,91 @@ func (s *Store) Write(cp *Checkpoint, sessionData *SessionFiles) error {
 	shard := Shard(cp.ID)
 	rest := Rest(cp.ID)
 
-	// Hash all the blob objects
-	metaJSON, err := json.MarshalIndent(cp.ToMetadata(), "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshaling metadata: %w", err)
+	if err := s.ensureBranch(); err != nil {
+		return err
 	}
 
-	metaHash, err := s.hashObject(string(metaJSON))
-	if err != nil {
-		return fmt.Errorf("hashing metadata: %w", err)
+	meta := cp.ToMetadata()
+	sessionMeta := sessionData.Metadata
+	sessionMeta.SchemaVersion = SchemaVersion
+	if sessionMeta.SessionID == "" {
+		sessionMeta.SessionID = cp.SessionID
 	}
-
-	contentHashHash, err := s.hashObject(sessionData.ContentHash)
-	if err != nil {
-		return fmt.Errorf("hashing content_hash: %w", err)
+	if sessionData.FullJSONL != "" {
+		sessionMeta.TranscriptHash = sha256Hex(sessionData.FullJSONL)
 	}
+	meta.Sessions = []SessionRef{sessionMeta.ref(0)}
 
-	contextHash, err := s.hashObject(sessionData.Context)
-	if err != nil {
-		return fmt.Errorf("hashing context: %w", err)
+	if len(s.recipients) > 0 {
+		sealed, err := s.seal(sessionData)
+		if err != nil {
+			return fmt.Errorf("encrypting session files: %w", err)
+		}
+		sessionData = sealed
+		meta.Encrypted = true
 	}
 
-	fullHash, err := s.hashObject(sessionData.FullJSONL)
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
-		return fmt.Errorf("hashing full.jsonl: %w", err)
+		return fmt.Errorf("marshaling metadata: %w", err)
 	}
 
-	sessionMetaJSON, err := json.MarshalIndent(sessionData.Metadata, "", "  ")
+	sessionMetaJSON, err := json.MarshalIndent(sessionMeta, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling session metadata: %w", err)
 	}
-	sessionMetaHash, err := s.hashObject(string(sessionMetaJSON))
-	if err != nil {
-		return fmt.Errorf("hashing session metadata: %w", err)
+
+	// Session subtree (0/) files, in tree order
+	sessionFiles := []struct{ name, content string }{
+		{"content_hash.txt", sessionData.ContentHash},
+		{"context.md", sessionData.Context},
+		{"diff.patch", sessionData.Diff},
+		{"full.jsonl", sessionData.FullJSONL},
+		{"metadata.json", string(sessionMetaJSON)},
+		{"plan.md", sessionData.Plan},
+		{"prompt.txt", sessionData.Prompt},
+		{"tools.json", sessionData.Tools},
+		{"transcript.json", sessionData.Transcript},
 	}
 
-	promptHash, err := s.hashObject(sessionData.Prompt)
-	if err != nil {
-		return fmt.Errorf("hashing prompt: %w", err)
+	manifestFiles := map[string]string{"metadata.json": string(metaJSON)}
+
+	// Hash all the blob objects
+	var sessionEntries []treeEntry
+	for _, f := range sessionFiles {
+		hash, err := s.hashObject(f.content)
+		if err != nil {
+			return fmt.Errorf("hashing %s: %w", f.name, err)
+		}
+		sessionEntries = append(sessionEntries, treeEntry{mode: "100644", typ: "blob", hash: hash, name: f.name})
+		manifestFiles["0/"+f.name] = f.content
 	}
 
-	diffHash, err := s.hashObject(sessionData.Diff)
+	metaHash, err := s.hashObject(string(metaJSON))
 	if err != nil {
-		return fmt.Errorf("hashing diff: %w", err)
+		return fmt.Errorf("hashing metadata: %w", err)
 	}
 
-	planHash, err := s.hashObject(sessionData.Plan)
+	manifestJSON, manifestHash, err := newManifest(manifestFiles).encode()
 	if err != nil {
-		return fmt.Errorf("hashing plan: %w", err)
+		return err
+	}
+	manifestBlob, err := s.hashObject(manifestJSON)
+	if err != nil {
+		return fmt.Errorf("hashing manifest: %w", err)
 	}
 
 	// Build session subtree (0/)
-	sessionTree, err := s.mktree([]treeEntry{
-		{mode: "100644", typ: "blob", hash: contentHashHash, name: "content_hash.txt"},
-		{mode: "100644", typ: "blob", hash: contextHash, name: "context.md"},
-		{mode: "100644", typ: "blob", hash: diffHash, name: "diff.patch"},
-		{mode: "100644", typ: "blob", hash: fullHash, name: "full.jsonl"},
-		{mode: "100644", typ: "blob", hash: sessionMetaHash, name: "metadata.json"},
-		{mode: "100644", typ: "blob", hash: planHash, name: "plan.md"},
-		{mode: "100644", typ: "blob", hash: promptHash, name: "prompt.txt"},
-	})
+	sessionTree, err := s.mktree(sessionEntries)
 	if err != nil {
 		return fmt.Errorf("creating session tree: %w", err)
 	}
 
 	// Build checkpoint subtree (<rest>/)
 	cpTree, err := s.mktree([]treeEntry{
-		{mode: "100644", typ: "blob", hash: metaHash, name: "metadata.json"},
 		{mode: "040000", typ: "tree", hash: sessionTree, name: "0"},
+		{mode: "100644", typ: "blob", hash: manifestBlob, name: manifestFile},
+		{mode: "100644", typ: "blob", hash: metaHash, name: "metadata.json"},
 	})
 	if err != nil {
 		return fmt.Errorf("creating checkpoint tree: %w", err)
@@ -101,8 +118,8 @@ func (s *Store) Write(cp *Checkpoint, sessionData *SessionFiles) error {
 		return fmt.Errorf("getting parent commit: %w", err)
 	}
 
-	commitMsg := fmt.Sprintf("checkpoint: %s", cp.ID)
-	commitHash, err := s.git("commit-tree", newRoot, "-p", parentCommit, "-m", commitMsg)
+	commitMsg := fmt.Sprintf("checkpoint: %s\n\n%s", cp.ID, manifestTrailerLine(cp.ID, manifestHash))
+	commitHash, err := s.commitTree(newRoot, parentCommit, commitMsg)
 	if err != nil {
 		return fmt.Errorf("creating commit: %w", err)
 	}
@@ -2,7 +2,12 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/partio-io/cli/internal/pricing"
 )
 
 // Duration is a time.Duration that marshals to and from a human-readable string
@@ -14,13 +19,13 @@ func (d Duration) MarshalJSON() ([]byte, error) {
 	return json.Marshal(time.Duration(d).String())
 }
 
-// UnmarshalJSON parses a duration string such as "10m".
+// UnmarshalJSON parses a duration string such as "10m" or "90d".
 func (d *Duration) UnmarshalJSON(b []byte) error {
 	var s string
 	if err := json.Unmarshal(b, &s); err != nil {
 		return err
 	}
-	v, err := time.ParseDuration(s)
+	v, err := ParseDuration(s)
 	if err != nil {
 		return err
 	}
@@ -28,19 +33,44 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// ParseDuration extends time.ParseDuration with a "d" (day) suffix, as in
+// "90d".
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseUint(days, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
 // Duration returns the value as a time.Duration.
 func (d Duration) Duration() time.Duration { return time.Duration(d) }
 
 // Config holds all partio configuration.
 type Config struct {
-	Enabled               bool            `json:"enabled"`
-	Strategy              string          `json:"strategy"`
-	Agent                 string          `json:"agent"`
-	LogLevel              string          `json:"log_level"`
-	CommitLinking         string          `json:"commit_linking"`
-	StrategyOptions       StrategyOptions `json:"strategy_options"`
-	Redact                RedactOptions   `json:"redact"`
-	StaleSessionThreshold Duration        `json:"stale_session_threshold"`
+	Enabled               bool              `json:"enabled"`
+	Strategy              string            `json:"strategy"`
+	Agent                 string            `json:"agent"`
+	LogLevel              string            `json:"log_level"`
+	CommitLinking         string            `json:"commit_linking"`
+	StrategyOptions       StrategyOptions   `json:"strategy_options"`
+	Redact                RedactOptions     `json:"redact"`
+	Encryption            EncryptionOptions `json:"encryption"`
+	SignCheckpoints       bool              `json:"sign_checkpoints"`
+	StaleSessionThreshold Duration          `json:"stale_session_threshold"`
+	// IdleThreshold is how long a session's transcript must be quiet before
+	// the session is considered IDLE.
+	IdleThreshold Duration `json:"idle_threshold"`
+	// IdleCommitLinking overrides CommitLinking for commits made while the
+	// agent session is IDLE. Empty means CommitLinking applies.
+	IdleCommitLinking string           `json:"idle_commit_linking,omitempty"`
+	Retention         RetentionOptions `json:"retention"`
+	// Pricing overrides or extends the built-in model price table used to
+	// estimate session costs, keyed by model name or name prefix.
+	Pricing pricing.Table `json:"pricing,omitempty"`
 }
 
 // CommitLinking values.
@@ -53,6 +83,13 @@ const (
 // StrategyOptions holds strategy-specific options.
 type StrategyOptions struct {
 	PushSessions bool `json:"push_sessions"`
+	// CheckpointRemote is the remote name or URL that checkpoints are pushed
+	// to. When empty, the remote the pre-push hook was invoked for is used.
+	CheckpointRemote string `json:"checkpoint_remote,omitempty"`
+	// CheckpointRef is the destination ref on the checkpoint remote (e.g.
+	// "refs/partio/checkpoints/v2"). When empty, the checkpoint branch is
+	// pushed under its local name.
+	CheckpointRef string `json:"checkpoint_ref,omitempty"`
 }
 
 // RedactOptions controls secret redaction in checkpoint data.
@@ -68,5 +105,54 @@ type RedactOptions struct {
 	EntropyMinLength int `json:"entropy_min_length"`
 }
 
+// EncryptionOptions controls encryption of checkpoint session blobs.
+type EncryptionOptions struct {
+	// Enabled toggles encryption of transcripts, prompts, plans, diffs and
+	// context. Metadata is always stored in clear. Defaults to false.
+	Enabled bool `json:"enabled"`
+	// Recipients lists the public keys ("partio-x25519:...") that can decrypt
+	// checkpoints. Generate one with "partio keygen".
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// RetentionOptions controls which checkpoints are pruned by policy. With no
+// limits set, checkpoints are kept forever.
+type RetentionOptions struct {
+	// MaxAge removes checkpoints older than this (e.g. "90d").
+	MaxAge Duration `json:"max_age,omitempty"`
+	// MaxCount keeps at most this many checkpoints, newest first.
+	MaxCount int `json:"max_count,omitempty"`
+	// MaxTotalSize caps the combined size of stored transcripts (e.g. "500MB"),
+	// removing the oldest checkpoints first.
+	MaxTotalSize ByteSize `json:"max_total_size,omitempty"`
+	// KeepIfOnMain never removes checkpoints whose commit is on MainBranch.
+	KeepIfOnMain bool `json:"keep_if_on_main"`
+	// MainBranch is the branch KeepIfOnMain refers to. Defaults to "main",
+	// or "master" when there is no main branch.
+	MainBranch string `json:"main_branch,omitempty"`
+	// Branches overrides the limits above for checkpoints captured on
+	// branches matching a glob. The first matching rule wins.
+	Branches []BranchRetention `json:"branches,omitempty"`
+	// Auto applies the policy from the post-commit hook, at most once per
+	// Interval. Defaults to true.
+	Auto     bool     `json:"auto"`
+	Interval Duration `json:"interval"`
+}
+
+// Configured reports whether any retention limit is set.
+func (r RetentionOptions) Configured() bool {
+	return r.MaxAge > 0 || r.MaxCount > 0 || r.MaxTotalSize > 0 || len(r.Branches) > 0
+}
+
+// BranchRetention overrides retention limits for branches matching Pattern
+// (e.g. "feature/*"). Unset limits fall back to the top-level ones.
+type BranchRetention struct {
+	Pattern  string   `json:"pattern"`
+	MaxAge   Duration `json:"max_age,omitempty"`
+	MaxCount int      `json:"max_count,omitempty"`
+	// Keep never removes checkpoints from matching branches.
+	Keep bool `json:"keep,omitempty"`
+}
+
 // PartioDir is the directory name for partio config within a repo.
 const PartioDir = ".partio"
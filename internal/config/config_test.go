@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDefaults(t *testing.T) {
@@ -67,6 +68,30 @@ func TestMergeFromFile(t *testing.T) {
 	}
 }
 
+func TestMergeFromFileStrategyOptions(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "settings.json")
+
+	err := os.WriteFile(settingsPath, []byte(`{"strategy_options": {"checkpoint_remote": "mirror", "checkpoint_ref": "refs/partio/checkpoints/v1"}}`), 0o644)
+	if err != nil {
+		t.Fatalf("writing test settings: %v", err)
+	}
+
+	cfg := Defaults()
+	mergeFromFile(&cfg, settingsPath)
+
+	if cfg.StrategyOptions.CheckpointRemote != "mirror" {
+		t.Errorf("expected checkpoint_remote=mirror, got %s", cfg.StrategyOptions.CheckpointRemote)
+	}
+	if cfg.StrategyOptions.CheckpointRef != "refs/partio/checkpoints/v1" {
+		t.Errorf("expected checkpoint_ref=refs/partio/checkpoints/v1, got %s", cfg.StrategyOptions.CheckpointRef)
+	}
+	// Keys absent from strategy_options keep their defaults
+	if !cfg.StrategyOptions.PushSessions {
+		t.Error("expected push_sessions to keep default true")
+	}
+}
+
 func TestEnvOverrides(t *testing.T) {
 	t.Setenv("PARTIO_STRATEGY", "env-strategy")
 	t.Setenv("PARTIO_LOG_LEVEL", "error")
@@ -121,3 +146,151 @@ func TestLoadLayered(t *testing.T) {
 		t.Errorf("expected log_level=debug, got %s", cfg.LogLevel)
 	}
 }
+
+func TestMergeFromFileRetention(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "settings.json")
+
+	err := os.WriteFile(settingsPath, []byte(`{"retention": {
+		"max_age": "90d",
+		"max_count": 500,
+		"max_total_size": "1.5GB",
+		"keep_if_on_main": true,
+		"branches": [{"pattern": "feature/*", "max_age": "14d"}, {"pattern": "release/*", "keep": true}]
+	}}`), 0o644)
+	if err != nil {
+		t.Fatalf("writing test settings: %v", err)
+	}
+
+	cfg := Defaults()
+	mergeFromFile(&cfg, settingsPath)
+	r := cfg.Retention
+
+	if got := r.MaxAge.Duration(); got != 90*24*time.Hour {
+		t.Errorf("expected max_age=90d, got %s", got)
+	}
+	if r.MaxCount != 500 {
+		t.Errorf("expected max_count=500, got %d", r.MaxCount)
+	}
+	if r.MaxTotalSize != ByteSize(1.5*(1<<30)) {
+		t.Errorf("expected max_total_size=1.5GB, got %d", r.MaxTotalSize)
+	}
+	if !r.KeepIfOnMain {
+		t.Error("expected keep_if_on_main=true")
+	}
+	if len(r.Branches) != 2 || r.Branches[0].MaxAge.Duration() != 14*24*time.Hour || !r.Branches[1].Keep {
+		t.Errorf("unexpected branch rules: %+v", r.Branches)
+	}
+	// Keys absent from retention keep their defaults
+	if !r.Auto || r.Interval.Duration() != 24*time.Hour {
+		t.Errorf("expected auto=true interval=24h defaults, got auto=%v interval=%s", r.Auto, r.Interval.Duration())
+	}
+	if !r.Configured() {
+		t.Error("expected retention to be configured")
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"10B", 10, false},
+		{"512KB", 512 << 10, false},
+		{"500mb", 500 << 20, false},
+		{"2 GB", 2 << 30, false},
+		{"1TB", 1 << 40, false},
+		{"lots", 0, true},
+		{"-1MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseByteSize(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseByteSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseByteSize(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeFromFilePricing(t *testing.T) {
+	dir := t.TempDir()
+	global := filepath.Join(dir, "global.json")
+	repo := filepath.Join(dir, "repo.json")
+
+	if err := os.WriteFile(global, []byte(`{"pricing": {"claude-sonnet-4": {"input": 2, "output": 10}}}`), 0o644); err != nil {
+		t.Fatalf("writing test settings: %v", err)
+	}
+	if err := os.WriteFile(repo, []byte(`{"pricing": {"local-llama": {"input": 0.1, "output": 0.2}}}`), 0o644); err != nil {
+		t.Fatalf("writing test settings: %v", err)
+	}
+
+	cfg := Defaults()
+	mergeFromFile(&cfg, global)
+	mergeFromFile(&cfg, repo)
+
+	if got := cfg.Pricing["claude-sonnet-4"]; got.Input != 2 || got.Output != 10 {
+		t.Errorf("expected global claude-sonnet-4 price to be kept, got %+v", got)
+	}
+	if got := cfg.Pricing["local-llama"]; got.Input != 0.1 || got.Output != 0.2 {
+		t.Errorf("expected repo local-llama price, got %+v", got)
+	}
+}
+
+func TestMergeFromFileIdleOptions(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, "settings.json")
+
+	err := os.WriteFile(settingsPath, []byte(`{"idle_threshold": "15m", "idle_commit_linking": "never"}`), 0o644)
+	if err != nil {
+		t.Fatalf("writing test settings: %v", err)
+	}
+
+	cfg := Defaults()
+	if cfg.IdleThreshold.Duration() != 5*time.Minute || cfg.IdleCommitLinking != "" {
+		t.Fatalf("unexpected idle defaults: %v, %q", cfg.IdleThreshold.Duration(), cfg.IdleCommitLinking)
+	}
+	mergeFromFile(&cfg, settingsPath)
+
+	if cfg.IdleThreshold.Duration() != 15*time.Minute {
+		t.Errorf("expected idle_threshold=15m, got %v", cfg.IdleThreshold.Duration())
+	}
+	if cfg.IdleCommitLinking != CommitLinkingNever {
+		t.Errorf("expected idle_commit_linking=never, got %s", cfg.IdleCommitLinking)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"1d", 24 * time.Hour, false},
+		{"365d", 365 * 24 * time.Hour, false},
+		{"24h", 24 * time.Hour, false},
+		{"10m", 10 * time.Minute, false},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"d", 0, true},
+		{"-5d", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
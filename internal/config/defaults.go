@@ -19,5 +19,10 @@ func Defaults() Config {
 			EntropyMinLength: 20,
 		},
 		StaleSessionThreshold: Duration(10 * time.Minute),
+		IdleThreshold:         Duration(5 * time.Minute),
+		Retention: RetentionOptions{
+			Auto:     true,
+			Interval: Duration(24 * time.Hour),
+		},
 	}
 }
@@ -22,9 +22,23 @@ func applyEnv(cfg *Config) {
 	if v := os.Getenv("PARTIO_COMMIT_LINKING"); v != "" {
 		cfg.CommitLinking = v
 	}
+	if v := os.Getenv("PARTIO_CHECKPOINT_REMOTE"); v != "" {
+		cfg.StrategyOptions.CheckpointRemote = v
+	}
+	if v := os.Getenv("PARTIO_CHECKPOINT_REF"); v != "" {
+		cfg.StrategyOptions.CheckpointRef = v
+	}
 	if v := os.Getenv("PARTIO_STALE_SESSION_THRESHOLD"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
 			cfg.StaleSessionThreshold = Duration(d)
 		}
 	}
+	if v := os.Getenv("PARTIO_IDLE_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.IdleThreshold = Duration(d)
+		}
+	}
+	if v := os.Getenv("PARTIO_IDLE_COMMIT_LINKING"); v != "" {
+		cfg.IdleCommitLinking = v
+	}
 }
@@ -63,7 +63,26 @@ func mergeFromFile(dst *Config, path string) {
 	if v, ok := raw["strategy_options"]; ok {
 		_ = json.Unmarshal(v, &dst.StrategyOptions)
 	}
+	if v, ok := raw["encryption"]; ok {
+		_ = json.Unmarshal(v, &dst.Encryption)
+	}
+	if v, ok := raw["sign_checkpoints"]; ok {
+		_ = json.Unmarshal(v, &dst.SignCheckpoints)
+	}
 	if v, ok := raw["stale_session_threshold"]; ok {
 		_ = json.Unmarshal(v, &dst.StaleSessionThreshold)
 	}
+	if v, ok := raw["idle_threshold"]; ok {
+		_ = json.Unmarshal(v, &dst.IdleThreshold)
+	}
+	if v, ok := raw["idle_commit_linking"]; ok {
+		_ = json.Unmarshal(v, &dst.IdleCommitLinking)
+	}
+	if v, ok := raw["retention"]; ok {
+		_ = json.Unmarshal(v, &dst.Retention)
+	}
+	if v, ok := raw["pricing"]; ok {
+		// Models from each layer are added to those of the layers below.
+		_ = json.Unmarshal(v, &dst.Pricing)
+	}
 }
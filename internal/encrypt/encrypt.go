@@ -0,0 +1,215 @@
+// Package encrypt implements age-style multi-recipient encryption for
+// checkpoint blobs using only the standard library.
+//
+// A random 256-bit file key encrypts the payload with AES-256-GCM. The file
+// key is then wrapped once per recipient: an ephemeral X25519 key agreement
+// with the recipient's public key, stretched with HKDF-SHA256, yields a
+// wrapping key that seals the file key. Any one matching [Identity] can
+// recover the file key and decrypt the payload.
+//
+// Encrypted data is ASCII-armored so it survives being stored and read back
+// as text through git plumbing.
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	armorHeader = "-----BEGIN PARTIO ENCRYPTED FILE-----"
+	armorFooter = "-----END PARTIO ENCRYPTED FILE-----"
+
+	// stanzaPrefix introduces a per-recipient wrapped file key line.
+	stanzaPrefix = "x25519 "
+
+	// hkdfInfo binds derived wrapping keys to this format and version.
+	hkdfInfo = "partio-encrypt/v1"
+
+	fileKeySize = 32
+	lineWidth   = 64
+)
+
+// ErrNoMatchingIdentity is returned by Decrypt when none of the supplied
+// identities can unwrap the file key.
+var ErrNoMatchingIdentity = errors.New("no identity matches any recipient")
+
+// IsEncrypted reports whether data is armored output from Encrypt.
+func IsEncrypted(data string) bool {
+	return strings.HasPrefix(strings.TrimSpace(data), armorHeader)
+}
+
+// Encrypt seals plaintext so that any of the given recipients can decrypt it.
+func Encrypt(plaintext []byte, recipients []*Recipient) (string, error) {
+	if len(recipients) == 0 {
+		return "", errors.New("no recipients")
+	}
+
+	fileKey := make([]byte, fileKeySize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return "", fmt.Errorf("generating file key: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(armorHeader + "\n")
+
+	for _, r := range recipients {
+		eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return "", fmt.Errorf("generating ephemeral key: %w", err)
+		}
+		shared, err := eph.ECDH(r.key)
+		if err != nil {
+			return "", fmt.Errorf("key agreement: %w", err)
+		}
+		wrapped, err := wrapKey(shared, eph.PublicKey().Bytes(), r.key.Bytes(), fileKey)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", stanzaPrefix,
+			base64.StdEncoding.EncodeToString(eph.PublicKey().Bytes()),
+			base64.StdEncoding.EncodeToString(wrapped))
+	}
+	b.WriteString("\n")
+
+	aead, err := newGCM(fileKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+	body := aead.Seal(nonce, nonce, plaintext, nil)
+
+	encoded := base64.StdEncoding.EncodeToString(body)
+	for len(encoded) > lineWidth {
+		b.WriteString(encoded[:lineWidth] + "\n")
+		encoded = encoded[lineWidth:]
+	}
+	if encoded != "" {
+		b.WriteString(encoded + "\n")
+	}
+	b.WriteString(armorFooter + "\n")
+
+	return b.String(), nil
+}
+
+// Decrypt opens armored data produced by Encrypt using the first identity
+// that matches one of its recipients.
+func Decrypt(armored string, identities []*Identity) ([]byte, error) {
+	lines := strings.Split(strings.TrimSpace(armored), "\n")
+	if len(lines) < 2 || lines[0] != armorHeader || lines[len(lines)-1] != armorFooter {
+		return nil, errors.New("malformed encrypted data: missing armor")
+	}
+	lines = lines[1 : len(lines)-1]
+
+	var stanzas []string
+	i := 0
+	for ; i < len(lines) && lines[i] != ""; i++ {
+		if !strings.HasPrefix(lines[i], stanzaPrefix) {
+			return nil, fmt.Errorf("malformed encrypted data: unexpected header line %q", lines[i])
+		}
+		stanzas = append(stanzas, strings.TrimPrefix(lines[i], stanzaPrefix))
+	}
+
+	body, err := base64.StdEncoding.DecodeString(strings.Join(lines[i:], ""))
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted data: %w", err)
+	}
+
+	fileKey, err := unwrapFileKey(stanzas, identities)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted data: body too short")
+	}
+	plaintext, err := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// unwrapFileKey tries every identity against every recipient stanza.
+func unwrapFileKey(stanzas []string, identities []*Identity) ([]byte, error) {
+	for _, stanza := range stanzas {
+		fields := strings.Fields(stanza)
+		if len(fields) != 2 {
+			continue
+		}
+		ephBytes, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			continue
+		}
+		wrapped, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			continue
+		}
+		ephPub, err := ecdh.X25519().NewPublicKey(ephBytes)
+		if err != nil {
+			continue
+		}
+		for _, id := range identities {
+			shared, err := id.key.ECDH(ephPub)
+			if err != nil {
+				continue
+			}
+			fileKey, err := unwrapKey(shared, ephBytes, id.key.PublicKey().Bytes(), wrapped)
+			if err == nil {
+				return fileKey, nil
+			}
+		}
+	}
+	return nil, ErrNoMatchingIdentity
+}
+
+func wrapKey(shared, ephPub, recipientPub, fileKey []byte) ([]byte, error) {
+	aead, err := wrappingAEAD(shared, ephPub, recipientPub)
+	if err != nil {
+		return nil, err
+	}
+	// Each wrapping key is derived from a fresh ephemeral key, so a fixed
+	// nonce is never reused with the same key.
+	return aead.Seal(nil, make([]byte, aead.NonceSize()), fileKey, nil), nil
+}
+
+func unwrapKey(shared, ephPub, recipientPub, wrapped []byte) ([]byte, error) {
+	aead, err := wrappingAEAD(shared, ephPub, recipientPub)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, make([]byte, aead.NonceSize()), wrapped, nil)
+}
+
+func wrappingAEAD(shared, ephPub, recipientPub []byte) (cipher.AEAD, error) {
+	salt := bytes.Join([][]byte{ephPub, recipientPub}, nil)
+	key, err := hkdf.Key(sha256.New, shared, salt, hkdfInfo, fileKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("deriving wrapping key: %w", err)
+	}
+	return newGCM(key)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
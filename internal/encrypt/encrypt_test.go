@@ -0,0 +1,146 @@
+package encrypt
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mustIdentity(t *testing.T) *Identity {
+	t.Helper()
+	id, err := GenerateIdentity()
+	if err != nil {
+		t.Fatalf("GenerateIdentity: %v", err)
+	}
+	return id
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	alice := mustIdentity(t)
+	bob := mustIdentity(t)
+	mallory := mustIdentity(t)
+
+	tests := []struct {
+		name       string
+		plaintext  string
+		identities []*Identity
+		wantErr    error
+	}{
+		{name: "first recipient decrypts", plaintext: "secret transcript", identities: []*Identity{alice}},
+		{name: "second recipient decrypts", plaintext: "secret transcript", identities: []*Identity{bob}},
+		{name: "empty plaintext", plaintext: "", identities: []*Identity{alice}},
+		{name: "large plaintext", plaintext: strings.Repeat("line of transcript\n", 5000), identities: []*Identity{bob}},
+		{name: "non-recipient cannot decrypt", plaintext: "secret", identities: []*Identity{mallory}, wantErr: ErrNoMatchingIdentity},
+		{name: "no identities", plaintext: "secret", wantErr: ErrNoMatchingIdentity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			armored, err := Encrypt([]byte(tt.plaintext), []*Recipient{alice.Recipient(), bob.Recipient()})
+			if err != nil {
+				t.Fatalf("Encrypt: %v", err)
+			}
+			if !IsEncrypted(armored) {
+				t.Error("IsEncrypted() = false for Encrypt output")
+			}
+			if tt.plaintext != "" && strings.Contains(armored, tt.plaintext) {
+				t.Error("armored output contains plaintext")
+			}
+
+			// Simulate reading back through git, which trims surrounding whitespace.
+			got, err := Decrypt(strings.TrimSpace(armored), tt.identities)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decrypt error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			if string(got) != tt.plaintext {
+				t.Errorf("Decrypt = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestEncryptNoRecipients(t *testing.T) {
+	if _, err := Encrypt([]byte("x"), nil); err == nil {
+		t.Error("expected error with no recipients")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	id := mustIdentity(t)
+	armored, err := Encrypt([]byte("payload"), []*Recipient{id.Recipient()})
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(armored), "\n")
+	body := lines[len(lines)-2]
+	flipped := []byte(body)
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+	lines[len(lines)-2] = string(flipped)
+
+	if _, err := Decrypt(strings.Join(lines, "\n"), []*Identity{id}); err == nil {
+		t.Error("expected error decrypting tampered data")
+	}
+}
+
+func TestParseKeysRoundTrip(t *testing.T) {
+	id := mustIdentity(t)
+
+	parsedID, err := ParseIdentity(id.String())
+	if err != nil {
+		t.Fatalf("ParseIdentity: %v", err)
+	}
+	if parsedID.String() != id.String() {
+		t.Error("identity did not round-trip")
+	}
+
+	r, err := ParseRecipient(id.Recipient().String())
+	if err != nil {
+		t.Fatalf("ParseRecipient: %v", err)
+	}
+	if r.String() != id.Recipient().String() {
+		t.Error("recipient did not round-trip")
+	}
+
+	for _, bad := range []string{"", "age1abc", recipientPrefix + "!!!", recipientPrefix + "AAAA"} {
+		if _, err := ParseRecipient(bad); err == nil {
+			t.Errorf("ParseRecipient(%q) expected error", bad)
+		}
+	}
+}
+
+func TestIdentityFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "identity.txt")
+
+	ids, err := LoadIdentities(path)
+	if err != nil || len(ids) != 0 {
+		t.Fatalf("LoadIdentities(missing) = %v, %v; want none, nil", ids, err)
+	}
+
+	id := mustIdentity(t)
+	if err := WriteIdentity(path, id); err != nil {
+		t.Fatalf("WriteIdentity: %v", err)
+	}
+	if err := WriteIdentity(path, id); err == nil {
+		t.Error("expected WriteIdentity to refuse to overwrite")
+	}
+
+	ids, err = LoadIdentities(path)
+	if err != nil {
+		t.Fatalf("LoadIdentities: %v", err)
+	}
+	if len(ids) != 1 || ids[0].String() != id.String() {
+		t.Errorf("LoadIdentities returned %d identities, want the written one", len(ids))
+	}
+}
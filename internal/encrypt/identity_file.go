@@ -0,0 +1,68 @@
+package encrypt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultIdentityPath returns the identity file location: $PARTIO_IDENTITY_FILE
+// if set, otherwise ~/.config/partio/identity.txt.
+func DefaultIdentityPath() (string, error) {
+	if v := os.Getenv("PARTIO_IDENTITY_FILE"); v != "" {
+		return v, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "partio", "identity.txt"), nil
+}
+
+// LoadIdentities reads all identities from path. Blank lines and lines
+// starting with "#" are ignored. A missing file yields no identities and no
+// error, so callers can treat "no key available" uniformly.
+func LoadIdentities(path string) ([]*Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading identity file: %w", err)
+	}
+
+	var ids []*Identity
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := ParseIdentity(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// WriteIdentity writes id to path with owner-only permissions, refusing to
+// overwrite an existing file.
+func WriteIdentity(path string, id *Identity) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating identity directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating identity file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	content := fmt.Sprintf("# partio identity\n# recipient: %s\n%s\n", id.Recipient(), id)
+	if _, err := f.WriteString(content); err != nil {
+		return fmt.Errorf("writing identity file: %w", err)
+	}
+	return nil
+}
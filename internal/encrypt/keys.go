@@ -0,0 +1,96 @@
+package encrypt
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	recipientPrefix = "partio-x25519:"
+	identityPrefix  = "PARTIO-X25519-SECRET:"
+)
+
+// Recipient is an X25519 public key that checkpoints can be encrypted to.
+type Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// Identity is an X25519 private key able to decrypt data encrypted to its
+// corresponding Recipient.
+type Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// GenerateIdentity creates a new random identity.
+func GenerateIdentity() (*Identity, error) {
+	k, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	return &Identity{key: k}, nil
+}
+
+// Recipient returns the public recipient for this identity.
+func (i *Identity) Recipient() *Recipient {
+	return &Recipient{key: i.key.PublicKey()}
+}
+
+// String encodes the identity as "PARTIO-X25519-SECRET:<base64>".
+func (i *Identity) String() string {
+	return identityPrefix + base64.StdEncoding.EncodeToString(i.key.Bytes())
+}
+
+// String encodes the recipient as "partio-x25519:<base64>".
+func (r *Recipient) String() string {
+	return recipientPrefix + base64.StdEncoding.EncodeToString(r.key.Bytes())
+}
+
+// ParseRecipient decodes a recipient string produced by Recipient.String.
+func ParseRecipient(s string) (*Recipient, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, recipientPrefix) {
+		return nil, fmt.Errorf("invalid recipient %q: missing %q prefix", s, recipientPrefix)
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, recipientPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", s, err)
+	}
+	k, err := ecdh.X25519().NewPublicKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", s, err)
+	}
+	return &Recipient{key: k}, nil
+}
+
+// ParseRecipients decodes a list of recipient strings.
+func ParseRecipients(ss []string) ([]*Recipient, error) {
+	var out []*Recipient
+	for _, s := range ss {
+		r, err := ParseRecipient(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// ParseIdentity decodes an identity string produced by Identity.String.
+func ParseIdentity(s string) (*Identity, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, identityPrefix) {
+		return nil, fmt.Errorf("invalid identity: missing %q prefix", identityPrefix)
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, identityPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity: %w", err)
+	}
+	k, err := ecdh.X25519().NewPrivateKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity: %w", err)
+	}
+	return &Identity{key: k}, nil
+}
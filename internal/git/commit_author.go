@@ -0,0 +1,12 @@
+package git
+
+import "fmt"
+
+// CommitAuthor returns the author of a commit as "Name <email>".
+func CommitAuthor(hash string) (string, error) {
+	out, err := execGit("log", "-1", "--format=%an <%ae>", hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit author: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,25 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// FetchRef updates the local ref dst to the remote's ref src, forcing it if
+// the remote was rewritten. The remote may be a configured remote name or a
+// URL. When the remote has no src, dst is deleted and found is false.
+func FetchRef(remote, src, dst string) (found bool, err error) {
+	if _, err := execGit("ls-remote", "--exit-code", remote, src); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+			_, _ = execGit("update-ref", "-d", dst)
+			return false, nil
+		}
+		return false, fmt.Errorf("listing %s on %s: %w", src, remote, err)
+	}
+	if _, err := execGit("fetch", "--quiet", "--no-tags", "--no-write-fetch-head", remote, "+"+src+":"+dst); err != nil {
+		return false, fmt.Errorf("fetching %s from %s: %w", src, remote, err)
+	}
+	return true, nil
+}
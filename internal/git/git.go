@@ -5,7 +5,12 @@ import (
 	"strings"
 )
 
-const CheckpointBranch = "partio/checkpoints/v1"
+// CheckpointBranch is the orphan branch checkpoints are written to.
+const CheckpointBranch = "partio/checkpoints/v2"
+
+// LegacyCheckpointBranch holds checkpoints written before schema v2. It is
+// still read; `partio migrate` converts it to CheckpointBranch.
+const LegacyCheckpointBranch = "partio/checkpoints/v1"
 
 // execGit runs a git command and returns trimmed stdout.
 func execGit(args ...string) (string, error) {
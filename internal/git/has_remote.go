@@ -1,7 +1,7 @@
 package git
 
-// HasRemote returns true if the repository has a remote named "origin".
-func HasRemote() bool {
-	_, err := execGit("remote", "get-url", "origin")
+// HasRemote returns true if the repository has a remote with the given name.
+func HasRemote(name string) bool {
+	_, err := execGit("remote", "get-url", name)
 	return err == nil
 }
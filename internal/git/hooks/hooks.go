@@ -13,30 +13,64 @@ var hookNames = []string{"pre-commit", "post-commit", "pre-push"}
 func hookScript(name string) string {
 	return fmt.Sprintf(`#!/bin/bash
 %s
-if command -v partio &> /dev/null; then
-    partio _hook %s "$@"
+%sif command -v partio &> /dev/null; then
+    %spartio _hook %s "$@"
     exit_code=$?
     [ $exit_code -ne 0 ] && exit $exit_code
 fi
-# Chain to original hook if backed up
-hooks_dir="$(git rev-parse --git-common-dir)/hooks"
-[ -f "$hooks_dir/%s.partio-backup" ] && exec "$hooks_dir/%s.partio-backup" "$@"
-exit 0
-`, partioMarker, name, name, name)
+%s`, partioMarker, stdinCapture(name), stdinFeed(name), name, chainScript(name))
 }
 
 // hookScriptAbsolute returns the bash shim for a given hook name using an absolute binary path.
 func hookScriptAbsolute(name, binaryPath string) string {
 	return fmt.Sprintf(`#!/bin/bash
 %s
-%s _hook %s "$@"
+%s%s%s _hook %s "$@"
 exit_code=$?
 [ $exit_code -ne 0 ] && exit $exit_code
-# Chain to original hook if backed up
+%s`, partioMarker, stdinCapture(name), stdinFeed(name), binaryPath, name, chainScript(name))
+}
+
+// chainScript returns the shim tail that chains to a backed-up original hook.
+func chainScript(name string) string {
+	if !readsStdin(name) {
+		return fmt.Sprintf(`# Chain to original hook if backed up
 hooks_dir="$(git rev-parse --git-common-dir)/hooks"
 [ -f "$hooks_dir/%s.partio-backup" ] && exec "$hooks_dir/%s.partio-backup" "$@"
 exit 0
-`, partioMarker, binaryPath, name, name, name)
+`, name, name)
+	}
+	return fmt.Sprintf(`# Chain to original hook if backed up, replaying the captured stdin
+hooks_dir="$(git rev-parse --git-common-dir)/hooks"
+if [ -f "$hooks_dir/%s.partio-backup" ]; then
+    partio_feed | "$hooks_dir/%s.partio-backup" "$@"
+    exit $?
+fi
+exit 0
+`, name, name)
+}
+
+// readsStdin reports whether git passes data to the hook on stdin. For these
+// hooks the shim captures stdin once so both partio and any chained original
+// hook can read it.
+func readsStdin(name string) bool {
+	return name == "pre-push"
+}
+
+func stdinCapture(name string) string {
+	if !readsStdin(name) {
+		return ""
+	}
+	return `partio_stdin="$(cat)"
+partio_feed() { [ -n "$partio_stdin" ] && printf '%s\n' "$partio_stdin"; }
+`
+}
+
+func stdinFeed(name string) string {
+	if !readsStdin(name) {
+		return ""
+	}
+	return "partio_feed | "
 }
 
 func isPartioHook(content string) bool {
@@ -0,0 +1,13 @@
+package git
+
+import "fmt"
+
+// PushRefspec pushes an explicit refspec (e.g. "src:dst") to the remote.
+// The remote may be a configured remote name or a URL.
+func PushRefspec(remote, refspec string) error {
+	_, err := execGit("push", "--no-verify", remote, refspec)
+	if err != nil {
+		return fmt.Errorf("pushing %s to %s: %w", refspec, remote, err)
+	}
+	return nil
+}
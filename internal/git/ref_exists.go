@@ -0,0 +1,7 @@
+package git
+
+// RefExists checks if a fully-qualified ref (e.g. "refs/partio/...") exists.
+func RefExists(ref string) bool {
+	_, err := execGit("rev-parse", "--verify", "--quiet", ref)
+	return err == nil
+}
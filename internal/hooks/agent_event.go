@@ -0,0 +1,143 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/git"
+	"github.com/partio-io/cli/internal/session"
+)
+
+// agentEventPayload is the JSON an agent passes to its hooks. Claude Code
+// writes it to the hook's stdin; Codex appends it to the notify program's
+// arguments.
+type agentEventPayload struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+
+	// Codex notifications.
+	Type     string `json:"type"`
+	ThreadID string `json:"thread-id"`
+}
+
+// AgentEvent records a session event reported by an agent's own hooks, so
+// the session's state and transcript are known without guessing from
+// running processes and file times. Events from repositories where partio
+// was never enabled are ignored, since agent hooks are installed per user.
+func (r *Runner) AgentEvent(ev session.Event, agentName string, payload []byte) error {
+	partioDir := filepath.Join(r.repoRoot, config.PartioDir)
+	if _, err := os.Stat(partioDir); err != nil {
+		slog.Debug("partio not set up in this repository, ignoring agent event", "event", ev)
+		return nil
+	}
+
+	var p agentEventPayload
+	if len(bytes.TrimSpace(payload)) > 0 {
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("parsing agent event payload: %w", err)
+		}
+	}
+	if p.Type != "" && p.Type != "agent-turn-complete" {
+		slog.Debug("ignoring agent notification", "type", p.Type)
+		return nil
+	}
+
+	detector, err := agent.NewDetector(agentName)
+	if err != nil {
+		return err
+	}
+
+	sessionID := p.SessionID
+	if sessionID == "" {
+		sessionID = p.ThreadID
+	}
+	transcript := p.TranscriptPath
+	if transcript == "" && sessionID != "" {
+		if sl, ok := detector.(agent.SessionLocator); ok {
+			if transcript, err = sl.SessionPath(sessionID); err != nil {
+				slog.Debug("could not locate agent transcript", "agent", agentName, "session_id", sessionID, "error", err)
+			}
+		}
+	}
+
+	branch, _ := git.CurrentBranch()
+	s, err := session.NewManager(partioDir).RecordEvent(ev, session.EventSource{
+		Agent:          detector.Name(),
+		AgentSessionID: sessionID,
+		TranscriptPath: transcript,
+		Branch:         branch,
+		SourceDir:      r.repoRoot,
+	})
+	if err != nil {
+		return fmt.Errorf("recording agent event: %w", err)
+	}
+	slog.Debug("agent event recorded", "event", ev, "agent", s.Agent, "session", s.ID, "state", s.State, "transcript", s.TranscriptPath)
+	return nil
+}
+
+// reportedSessions returns the open sessions of agentName (any agent when
+// empty) in the worktree repoRoot that the agent reported through its
+// hooks and whose transcripts still exist, most recently active first.
+func reportedSessions(repoRoot, agentName string) []*session.Session {
+	sessions, err := session.NewManager(filepath.Join(repoRoot, config.PartioDir)).List()
+	if err != nil {
+		slog.Debug("could not list sessions", "error", err)
+		return nil
+	}
+	var open []*session.Session
+	for _, s := range sessions {
+		if s.State == session.StateEnded || s.TranscriptPath == "" || s.SourceDir != repoRoot {
+			continue
+		}
+		if agentName != "" && s.Agent != agentName {
+			continue
+		}
+		if _, err := os.Stat(s.TranscriptPath); err != nil {
+			continue
+		}
+		open = append(open, s)
+	}
+	return open
+}
+
+// readReportedSessions parses the transcripts of sessions reported by the
+// agent's hooks and picks the one that produced a commit of files. It
+// returns no data if the detector cannot read them.
+func readReportedSessions(detector agent.Detector, reported []*session.Session, repoRoot string, files []string) (string, *agent.SessionData, *agent.SessionMatch) {
+	sr, ok := detector.(agent.SessionReader)
+	if !ok {
+		return "", nil, nil
+	}
+
+	var candidates []agent.SessionCandidate
+	for _, s := range reported {
+		data, err := sr.ReadSession(s.TranscriptPath)
+		if err != nil {
+			slog.Warn("could not read agent-reported session", "path", s.TranscriptPath, "error", err)
+			continue
+		}
+		c := agent.SessionCandidate{Path: s.TranscriptPath, Data: data}
+		if info, err := os.Stat(s.TranscriptPath); err == nil {
+			c.ModTime = info.ModTime()
+		}
+		candidates = append(candidates, c)
+	}
+
+	reason := "reported by " + detector.Name() + " hooks"
+	switch len(candidates) {
+	case 0:
+		return "", nil, nil
+	case 1:
+		return candidates[0].Path, candidates[0].Data, &agent.SessionMatch{Reason: reason, Candidates: 1}
+	}
+	best := agent.RankSessions(candidates, repoRoot, files, time.Now())[0]
+	best.Match.Reason = reason + "; " + best.Match.Reason
+	return best.Path, best.Data, &best.Match
+}
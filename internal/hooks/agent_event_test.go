@@ -0,0 +1,63 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/session"
+)
+
+func TestAgentEvent(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+	r := &Runner{repoRoot: repo}
+	partioDir := filepath.Join(repo, config.PartioDir)
+	transcript := filepath.Join(t.TempDir(), "s1.jsonl")
+	if err := os.WriteFile(transcript, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"session_id":"s1","transcript_path":"` + transcript + `","hook_event_name":"SessionStart"}`)
+
+	// Without .partio/ the event is ignored.
+	if err := r.AgentEvent(session.EventStart, "claude-code", payload); err != nil {
+		t.Fatalf("AgentEvent: %v", err)
+	}
+	if _, err := os.Stat(partioDir); !os.IsNotExist(err) {
+		t.Fatal("event recorded in a repository without partio")
+	}
+
+	if err := os.MkdirAll(partioDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AgentEvent(session.EventStart, "claude-code", payload); err != nil {
+		t.Fatalf("AgentEvent: %v", err)
+	}
+	reported := reportedSessions(repo, "")
+	if len(reported) != 1 {
+		t.Fatalf("got %d reported sessions, want 1", len(reported))
+	}
+	if rs := reported[0]; rs.AgentSessionID != "s1" || rs.TranscriptPath != transcript || rs.State != session.StateActive {
+		t.Fatalf("reported session = %+v", rs)
+	}
+
+	// Codex notifications other than a finished turn are ignored.
+	if err := r.AgentEvent(session.EventStop, "codex", []byte(`{"type":"approval-requested","thread-id":"t1"}`)); err != nil {
+		t.Fatalf("AgentEvent: %v", err)
+	}
+	if reported := reportedSessions(repo, ""); len(reported) != 1 || reported[0].Agent != "claude-code" {
+		t.Errorf("reported sessions = %+v after ignored notification", reported)
+	}
+
+	if err := r.AgentEvent(session.EventEnd, "claude-code", payload); err != nil {
+		t.Fatalf("AgentEvent: %v", err)
+	}
+	if reported := reportedSessions(repo, ""); len(reported) != 0 {
+		t.Errorf("reported sessions = %+v, want none once ended", reported)
+	}
+
+	if err := r.AgentEvent(session.EventStop, "claude-code", []byte("not json")); err == nil {
+		t.Error("expected an error for a malformed payload")
+	}
+}
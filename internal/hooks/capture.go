@@ -0,0 +1,129 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/agent/claude"
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/encrypt"
+	"github.com/partio-io/cli/internal/redact"
+)
+
+// findSession locates the detector's session that produced changes to
+// files: a session reported by the agent's hooks, else the best match among
+// recent sessions, else the latest one. It returns no data if the detector
+// cannot parse sessions.
+func findSession(repoRoot string, cfg config.Config, detector agent.Detector, files []string) (string, *agent.SessionData, *agent.SessionMatch, error) {
+	if reported := linkableSessions(reportedSessions(repoRoot, detector.Name()), cfg); len(reported) > 0 {
+		if path, data, match := readReportedSessions(detector, reported, repoRoot, files); data != nil {
+			return path, data, match, nil
+		}
+	}
+	if sm, ok := detector.(agent.SessionMatcher); ok {
+		return sm.FindMatchingSession(repoRoot, files)
+	}
+	if sp, ok := detector.(agent.SessionParser); ok {
+		path, data, err := sp.FindLatestSession(repoRoot)
+		return path, data, nil, err
+	}
+	return "", nil, nil, nil
+}
+
+// newSessionFiles prepares the checkpoint files of a session read from
+// sessionPath. ContentHash and Diff are left to the caller.
+func newSessionFiles(detector agent.Detector, sessionPath string, sessionData *agent.SessionData, match *agent.SessionMatch) *checkpoint.SessionFiles {
+	sessionFiles := &checkpoint.SessionFiles{
+		Metadata: checkpoint.SessionMetadata{
+			Agent: detector.Name(),
+		},
+	}
+
+	if sessionData != nil {
+		sessionFiles.Metadata.SessionID = sessionData.SessionID
+		sessionFiles.Context = sessionData.Context
+		sessionFiles.Prompt = sessionData.Prompt
+		sessionFiles.Metadata.Models = sessionData.Models()
+		sessionFiles.Metadata.Tokens = sessionData.Tokens
+		sessionFiles.Metadata.ModelTokens = sessionData.ModelTokens
+		sessionFiles.Metadata.TotalTokens = sessionData.TotalTokens
+		if sessionData.ToolCalls != nil {
+			if tools, err := json.MarshalIndent(sessionData.ToolCalls, "", "  "); err == nil {
+				sessionFiles.Tools = string(tools)
+			}
+		}
+		if transcript, err := json.MarshalIndent(sessionData.Normalize(), "", "  "); err == nil {
+			sessionFiles.Transcript = string(transcript)
+		}
+		sessionFiles.Metadata.Duration = sessionData.Duration.String()
+		if match != nil {
+			m := checkpoint.SessionMatch(*match)
+			sessionFiles.Metadata.Match = &m
+		}
+	}
+
+	if sessionData != nil && sessionData.PlanSlug != "" {
+		planContent, err := claude.ReadPlanFile(sessionData.PlanSlug)
+		if err != nil {
+			slog.Warn("could not read plan file", "slug", sessionData.PlanSlug, "error", err)
+		} else {
+			sessionFiles.Plan = planContent
+		}
+	}
+
+	if sessionPath != "" {
+		sources := []string{sessionPath}
+		if sessionData != nil && len(sessionData.SourceFiles) > 0 {
+			sources = sessionData.SourceFiles
+		}
+		if raw, err := readSources(sources); err == nil {
+			sessionFiles.FullJSONL = raw
+		} else {
+			slog.Debug("could not read session transcript", "path", sessionPath, "error", err)
+		}
+	}
+
+	return sessionFiles
+}
+
+// writeCheckpoint redacts secrets from the session files and writes the
+// checkpoint to the checkpoint branch, signed and encrypted as configured.
+func writeCheckpoint(repoRoot string, cfg config.Config, cp *checkpoint.Checkpoint, sessionFiles *checkpoint.SessionFiles) error {
+	// Redact secrets from session content before persisting to the metadata branch.
+	redactOpts := redact.Options{
+		Enabled:          cfg.Redact.Enabled,
+		EntropyThreshold: cfg.Redact.EntropyThreshold,
+		EntropyMinLength: cfg.Redact.EntropyMinLength,
+	}
+	redact.SessionFiles(sessionFiles, redactOpts)
+
+	store, err := openStore(repoRoot, cfg)
+	if err != nil {
+		return err
+	}
+	if err := store.Write(cp, sessionFiles); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	return nil
+}
+
+// openStore returns the checkpoint store of repoRoot, signing and
+// encrypting as configured.
+func openStore(repoRoot string, cfg config.Config) (*checkpoint.Store, error) {
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	if cfg.Encryption.Enabled {
+		recipients, err := encrypt.ParseRecipients(cfg.Encryption.Recipients)
+		if err != nil {
+			return nil, fmt.Errorf("parsing encryption recipients: %w", err)
+		}
+		if len(recipients) == 0 {
+			return nil, fmt.Errorf("encryption is enabled but no recipients are configured")
+		}
+		store.SetRecipients(recipients)
+	}
+	return store, nil
+}
@@ -6,33 +6,43 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/session"
 )
 
 // shouldLinkCommit determines whether the current commit should be linked to
 // the active agent session. It returns true if the commit should be linked.
+// When the session is idle, idle_commit_linking applies instead of
+// commit_linking if it is set.
 //
-// When commit_linking is "ask", it prompts the user interactively via /dev/tty.
+// When the mode is "ask", it prompts the user interactively via /dev/tty.
 // The prompt offers [Y/n/a]: Y (default) links, n skips, a links and persists
 // "always" to settings so future commits are linked without prompting.
-func shouldLinkCommit(repoRoot string, cfg config.Config) bool {
-	switch cfg.CommitLinking {
+func shouldLinkCommit(repoRoot string, cfg config.Config, idle bool) bool {
+	mode, setting, subject := cfg.CommitLinking, "commit_linking", "active"
+	if idle && cfg.IdleCommitLinking != "" {
+		mode, setting, subject = cfg.IdleCommitLinking, "idle_commit_linking", "idle"
+	}
+
+	switch mode {
 	case config.CommitLinkingAlways:
-		slog.Debug("commit linking: always (auto-linking)")
+		slog.Debug("commit linking: always (auto-linking)", "setting", setting)
 		return true
 	case config.CommitLinkingNever:
-		slog.Debug("commit linking: never (skipping)")
+		slog.Debug("commit linking: never (skipping)", "setting", setting)
 		return false
 	default: // "ask" or unset
-		return promptCommitLinking(repoRoot)
+		return promptCommitLinking(repoRoot, setting, subject)
 	}
 }
 
 // promptCommitLinking opens /dev/tty to ask the user whether to link this
-// commit. If /dev/tty is not available (non-interactive), it defaults to
-// linking the commit.
-func promptCommitLinking(repoRoot string) bool {
+// commit to the subject ("active" or "idle") session, persisting "always"
+// to setting on request. If /dev/tty is not available (non-interactive), it
+// defaults to linking the commit.
+func promptCommitLinking(repoRoot, setting, subject string) bool {
 	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
 		slog.Debug("commit linking: no TTY available, auto-linking")
@@ -40,7 +50,7 @@ func promptCommitLinking(repoRoot string) bool {
 	}
 	defer func() { _ = tty.Close() }()
 
-	_, _ = fmt.Fprint(tty, "partio: Link this commit to the active AI session? [Y/n/a] ")
+	_, _ = fmt.Fprintf(tty, "partio: Link this commit to the %s AI session? [Y/n/a] ", subject)
 
 	reader := bufio.NewReader(tty)
 	line, _ := reader.ReadString('\n')
@@ -52,11 +62,42 @@ func promptCommitLinking(repoRoot string) bool {
 		return false
 	case "a":
 		slog.Debug("commit linking: user chose always")
-		if err := config.SaveRepoSetting(repoRoot, "commit_linking", config.CommitLinkingAlways); err != nil {
-			slog.Warn("could not persist commit_linking=always", "error", err)
+		if err := config.SaveRepoSetting(repoRoot, setting, config.CommitLinkingAlways); err != nil {
+			slog.Warn("could not persist setting", "setting", setting, "value", config.CommitLinkingAlways, "error", err)
 		}
 		return true
 	default: // "", "y", "Y"
 		return true
 	}
 }
+
+// linkableSessions orders open sessions for commit linking: ACTIVE ones
+// before IDLE ones, keeping their order otherwise. IDLE sessions are
+// dropped when idle_commit_linking is "never".
+func linkableSessions(sessions []*session.Session, cfg config.Config) []*session.Session {
+	var active, idle []*session.Session
+	for _, s := range sessions {
+		if s.State == session.StateIdle {
+			idle = append(idle, s)
+		} else {
+			active = append(active, s)
+		}
+	}
+	if cfg.IdleCommitLinking == config.CommitLinkingNever {
+		return active
+	}
+	return append(active, idle...)
+}
+
+// transcriptIdle reports whether the transcript at path has been quiet for
+// at least quiet. A zero quiet period disables idle detection.
+func transcriptIdle(path string, quiet time.Duration) bool {
+	if quiet <= 0 {
+		return false
+	}
+	activity, err := session.TranscriptActivity(path)
+	if err != nil {
+		return false
+	}
+	return time.Since(activity) >= quiet
+}
@@ -1,15 +1,19 @@
 package hooks
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/session"
 )
 
 func TestShouldLinkCommit(t *testing.T) {
 	tests := []struct {
 		name     string
 		linking  string
+		idle     string
+		isIdle   bool
 		wantLink bool
 	}{
 		{
@@ -32,15 +36,64 @@ func TestShouldLinkCommit(t *testing.T) {
 			linking:  "",
 			wantLink: true, // treated as "ask" → no TTY → auto-link
 		},
+		{
+			name:     "idle session uses idle linking",
+			linking:  config.CommitLinkingAlways,
+			idle:     config.CommitLinkingNever,
+			isIdle:   true,
+			wantLink: false,
+		},
+		{
+			name:     "active session ignores idle linking",
+			linking:  config.CommitLinkingAlways,
+			idle:     config.CommitLinkingNever,
+			wantLink: true,
+		},
+		{
+			name:     "idle session falls back to commit linking",
+			linking:  config.CommitLinkingNever,
+			isIdle:   true,
+			wantLink: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cfg := config.Config{CommitLinking: tt.linking}
-			got := shouldLinkCommit(t.TempDir(), cfg)
+			cfg := config.Config{CommitLinking: tt.linking, IdleCommitLinking: tt.idle}
+			got := shouldLinkCommit(t.TempDir(), cfg, tt.isIdle)
 			if got != tt.wantLink {
 				t.Errorf("shouldLinkCommit() = %v, want %v", got, tt.wantLink)
 			}
 		})
 	}
 }
+
+func TestLinkableSessions(t *testing.T) {
+	sessions := []*session.Session{
+		{ID: "idle-1", State: session.StateIdle},
+		{ID: "active-1", State: session.StateActive},
+		{ID: "idle-2", State: session.StateIdle},
+		{ID: "active-2", State: session.StateActive},
+	}
+
+	tests := []struct {
+		idleLinking string
+		want        string
+	}{
+		{"", "active-1 active-2 idle-1 idle-2"},
+		{config.CommitLinkingAsk, "active-1 active-2 idle-1 idle-2"},
+		{config.CommitLinkingNever, "active-1 active-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.idleLinking, func(t *testing.T) {
+			var ids []string
+			for _, s := range linkableSessions(sessions, config.Config{IdleCommitLinking: tt.idleLinking}) {
+				ids = append(ids, s.ID)
+			}
+			if got := strings.Join(ids, " "); got != tt.want {
+				t.Errorf("linkableSessions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
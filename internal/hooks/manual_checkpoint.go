@@ -0,0 +1,114 @@
+package hooks
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/partio-io/cli/internal/agent"
+	"github.com/partio-io/cli/internal/agent/claude"
+	"github.com/partio-io/cli/internal/attribution"
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/git"
+)
+
+// Checkpoint takes a manual checkpoint without committing: the working tree,
+// with staged, unstaged and untracked changes, is recorded in a snapshot
+// commit on top of HEAD (see checkpoint.Store.Snapshot), and the agent
+// session, plan and diff from HEAD are written to the checkpoint branch
+// like a commit's. The session is not marked as condensed, so the commit
+// that follows still captures it.
+func (r *Runner) Checkpoint(note string) (*checkpoint.Checkpoint, error) {
+	head, err := git.CurrentCommit()
+	if err != nil {
+		return nil, fmt.Errorf("a checkpoint needs at least one commit to build on")
+	}
+	branch, _ := git.CurrentBranch()
+
+	store, err := openStore(r.repoRoot, r.cfg)
+	if err != nil {
+		return nil, err
+	}
+	cpID := checkpoint.NewID()
+	msg := "partio checkpoint " + cpID
+	if note != "" {
+		msg += "\n\n" + note
+	}
+	snapshot, err := store.Snapshot(cpID, head, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := git.DiffNameOnly(snapshot)
+	if err != nil {
+		slog.Debug("could not list changed files", "error", err)
+	}
+	detector := r.checkpointDetector()
+	sessionPath, sessionData, match, err := findSession(r.repoRoot, r.cfg, detector, files)
+	if err != nil {
+		slog.Warn("checkpoint: could not read agent session", "agent", detector.Name(), "error", err)
+	}
+
+	attr, err := attribution.Calculate(snapshot, sessionData != nil)
+	if err != nil {
+		attr = &attribution.Result{}
+	}
+	author, err := git.CommitAuthor(snapshot)
+	if err != nil {
+		slog.Debug("could not read snapshot author", "commit", snapshot, "error", err)
+	}
+
+	cp := &checkpoint.Checkpoint{
+		ID:            cpID,
+		CommitHash:    head,
+		Branch:        branch,
+		CreatedAt:     time.Now(),
+		Agent:         detector.Name(),
+		AgentPct:      attr.AgentPercent,
+		ContentHash:   snapshot,
+		Author:        author,
+		PartioVersion: r.version,
+		HookStrategy:  r.cfg.Strategy,
+		Manual:        true,
+		Note:          note,
+		Snapshot:      snapshot,
+	}
+	if sessionData != nil {
+		cp.SessionID = sessionData.SessionID
+		cp.PlanSlug = sessionData.PlanSlug
+	}
+
+	sessionFiles := newSessionFiles(detector, sessionPath, sessionData, match)
+	sessionFiles.ContentHash = snapshot
+	if d, err := git.Diff(snapshot); err == nil {
+		sessionFiles.Diff = d
+	}
+
+	if err := writeCheckpoint(r.repoRoot, r.cfg, cp, sessionFiles); err != nil {
+		_, _ = git.ExecGit("update-ref", "-d", checkpoint.SnapshotRef(cpID))
+		return nil, err
+	}
+	slog.Debug("manual checkpoint created", "id", cpID, "snapshot", snapshot, "session_found", sessionData != nil)
+	return cp, nil
+}
+
+// checkpointDetector picks the agent whose session a manual checkpoint
+// captures: one that reported an open session through its hooks, else the
+// configured agent, else a running one, else Claude Code.
+func (r *Runner) checkpointDetector() agent.Detector {
+	for _, s := range linkableSessions(reportedSessions(r.repoRoot, ""), r.cfg) {
+		if d, err := agent.NewDetector(s.Agent); err == nil {
+			return d
+		}
+	}
+	if r.cfg.Agent != "" {
+		if d, err := agent.NewDetector(r.cfg.Agent); err == nil {
+			return d
+		}
+		slog.Warn("unknown configured agent", "agent", r.cfg.Agent)
+	}
+	if active := agent.DetectActive(); len(active) > 0 {
+		return active[0]
+	}
+	return claude.New()
+}
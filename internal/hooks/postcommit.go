@@ -1,12 +1,12 @@
 package hooks
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/partio-io/cli/internal/agent"
@@ -16,17 +16,16 @@ import (
 	"github.com/partio-io/cli/internal/checkpoint"
 	"github.com/partio-io/cli/internal/config"
 	"github.com/partio-io/cli/internal/git"
-	"github.com/partio-io/cli/internal/redact"
 	"github.com/partio-io/cli/internal/session"
 )
 
 // PostCommit runs post-commit hook logic.
 func (r *Runner) PostCommit() error {
 	slog.Debug("post-commit hook running")
-	return runPostCommit(r.repoRoot, r.cfg)
+	return runPostCommit(r.repoRoot, r.cfg, r.version)
 }
 
-func runPostCommit(repoRoot string, cfg config.Config) error {
+func runPostCommit(repoRoot string, cfg config.Config, version string) error {
 	// Read pre-commit state
 	stateFile := filepath.Join(repoRoot, config.PartioDir, "state", "pre-commit.json")
 	data, err := os.ReadFile(stateFile)
@@ -70,8 +69,6 @@ func runPostCommit(repoRoot string, cfg config.Config) error {
 	}
 
 	// Parse agent session data using SessionParser interface (any agent).
-	var sessionPath string
-	var sessionData *agent.SessionData
 	agentName := cfg.Agent
 	if state.AgentName != "" {
 		agentName = state.AgentName
@@ -81,28 +78,30 @@ func runPostCommit(repoRoot string, cfg config.Config) error {
 		slog.Warn("unknown agent, falling back to claude-code", "agent", agentName, "error", detErr)
 		detector = claude.New()
 	}
-	if sp, ok := detector.(agent.SessionParser); ok {
-		sessionPath, sessionData, err = sp.FindLatestSession(repoRoot)
-		if err != nil {
-			slog.Warn("post-commit: could not read agent session", "agent", agentName, "commit", commitHash, "error", err)
-		}
+	commitFiles, diffErr := git.DiffNameOnly(commitHash)
+	if diffErr != nil {
+		slog.Debug("could not list committed files", "commit", commitHash, "error", diffErr)
+	}
+	sessionPath, sessionData, match, err := findSession(repoRoot, cfg, detector, commitFiles)
+	if err != nil {
+		slog.Warn("post-commit: could not read agent session", "agent", agentName, "commit", commitHash, "error", err)
 	}
 
 	// Log staged file paths and session content paths for diagnosing path mismatches.
-	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
-		commitFiles, _ := git.DiffNameOnly(commitHash)
-		slog.Debug("post-commit: file overlap check",
-			"commit", commitHash,
-			"staged_files", commitFiles,
-			"session_path", sessionPath,
-			"session_found", sessionData != nil,
-		)
+	slog.Debug("post-commit: file overlap check",
+		"commit", commitHash,
+		"staged_files", commitFiles,
+		"session_path", sessionPath,
+		"session_found", sessionData != nil,
+	)
+	if match != nil {
+		slog.Debug("post-commit: session matched", "score", match.Score, "reason", match.Reason, "candidates", match.Candidates)
 	}
 
 	// Skip if this session is already fully condensed and ended — re-processing
 	// it produces a redundant checkpoint with no new content.
 	if sessionData != nil && sessionData.SessionID != "" {
-		if shouldSkipSession(filepath.Join(repoRoot, config.PartioDir), sessionData.SessionID, sessionPath) {
+		if shouldSkipSession(repoRoot, sessionKey(detector, sessionData, repoRoot), sessionPath) {
 			slog.Warn("post-commit: no checkpoint created", "reason", "session already condensed", "commit", commitHash, "session_id", sessionData.SessionID)
 			return nil
 		}
@@ -113,7 +112,7 @@ func runPostCommit(repoRoot string, cfg config.Config) error {
 	cpID := checkpoint.NewID()
 
 	trailers := map[string]string{
-		"Partio-Checkpoint":  cpID,
+		checkpoint.Trailer:   cpID,
 		"Partio-Attribution": fmt.Sprintf("%d%% agent", attr.AgentPercent),
 	}
 
@@ -127,15 +126,23 @@ func runPostCommit(repoRoot string, cfg config.Config) error {
 		return fmt.Errorf("getting post-amend commit: %w", err)
 	}
 
+	author, err := git.CommitAuthor(commitHash)
+	if err != nil {
+		slog.Debug("could not read commit author", "commit", commitHash, "error", err)
+	}
+
 	// Create checkpoint with the post-amend hash
 	cp := &checkpoint.Checkpoint{
-		ID:          cpID,
-		CommitHash:  commitHash,
-		Branch:      state.Branch,
-		CreatedAt:   time.Now(),
-		Agent:       agentName,
-		AgentPct:    attr.AgentPercent,
-		ContentHash: commitHash,
+		ID:            cpID,
+		CommitHash:    commitHash,
+		Branch:        state.Branch,
+		CreatedAt:     time.Now(),
+		Agent:         detector.Name(),
+		AgentPct:      attr.AgentPercent,
+		ContentHash:   commitHash,
+		Author:        author,
+		PartioVersion: version,
+		HookStrategy:  cfg.Strategy,
 	}
 
 	if sessionData != nil {
@@ -144,62 +151,21 @@ func runPostCommit(repoRoot string, cfg config.Config) error {
 	}
 
 	// Prepare session files
-	sessionFiles := &checkpoint.SessionFiles{
-		ContentHash: commitHash,
-		Context:     "",
-		FullJSONL:   "",
-		Metadata: checkpoint.SessionMetadata{
-			Agent: cfg.Agent,
-		},
-		Prompt: "",
-	}
-
+	sessionFiles := newSessionFiles(detector, sessionPath, sessionData, match)
+	sessionFiles.ContentHash = commitHash
 	if d, err := git.Diff(commitHash); err == nil {
 		sessionFiles.Diff = d
 	}
 
-	if sessionData != nil {
-		sessionFiles.Context = sessionData.Context
-		sessionFiles.Prompt = sessionData.Prompt
-		sessionFiles.Metadata.TotalTokens = sessionData.TotalTokens
-		sessionFiles.Metadata.Duration = sessionData.Duration.String()
-	}
-
-	if sessionData != nil && sessionData.PlanSlug != "" {
-		planContent, err := claude.ReadPlanFile(sessionData.PlanSlug)
-		if err != nil {
-			slog.Warn("could not read plan file", "slug", sessionData.PlanSlug, "error", err)
-		} else {
-			sessionFiles.Plan = planContent
-		}
-	}
-
-	if sessionPath != "" {
-		rawJSONL, err := claude.ReadRawJSONL(sessionPath)
-		if err == nil {
-			sessionFiles.FullJSONL = string(rawJSONL)
-		}
-	}
-
-	// Redact secrets from session content before persisting to the metadata branch.
-	redactOpts := redact.Options{
-		Enabled:          cfg.Redact.Enabled,
-		EntropyThreshold: cfg.Redact.EntropyThreshold,
-		EntropyMinLength: cfg.Redact.EntropyMinLength,
-	}
-	redact.SessionFiles(sessionFiles, redactOpts)
-
-	// Write checkpoint to orphan branch
-	store := checkpoint.NewStore(repoRoot)
-	if err := store.Write(cp, sessionFiles); err != nil {
-		return fmt.Errorf("writing checkpoint: %w", err)
+	if err := writeCheckpoint(repoRoot, cfg, cp, sessionFiles); err != nil {
+		return err
 	}
 
 	// Mark the session as condensed so subsequent commits with the same session
 	// are skipped. This is best-effort; failure is non-fatal.
 	if sessionData != nil && sessionData.SessionID != "" {
 		mgr := session.NewManager(filepath.Join(repoRoot, config.PartioDir))
-		if markErr := mgr.MarkCondensed(sessionData.SessionID); markErr != nil {
+		if markErr := mgr.MarkCondensed(sessionKey(detector, sessionData, repoRoot)); markErr != nil {
 			slog.Debug("could not mark session as condensed", "error", markErr)
 		}
 	}
@@ -211,5 +177,30 @@ func runPostCommit(repoRoot string, cfg config.Config) error {
 	}
 
 	slog.Debug("checkpoint created", "id", cpID, "agent_pct", attr.AgentPercent)
+
+	maybeApplyRetention(repoRoot, cfg, commitHash)
 	return nil
 }
+
+// readSources concatenates the agent files a session was read from into one
+// raw transcript. A single file is returned verbatim.
+func readSources(paths []string) (string, error) {
+	var b strings.Builder
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		b.Write(data)
+		if i < len(paths)-1 && len(data) > 0 && data[len(data)-1] != '\n' {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+// sessionKey returns the key the session state of an agent session in
+// repoRoot is tracked under.
+func sessionKey(detector agent.Detector, data *agent.SessionData, repoRoot string) session.Key {
+	return session.Key{Agent: detector.Name(), AgentSessionID: data.SessionID, Worktree: repoRoot}
+}
@@ -0,0 +1,46 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSources(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"old.jsonl":   `{"uuid":"a"}`,
+		"new.jsonl":   "{\"uuid\":\"b\"}\n",
+		"agent.jsonl": `{"uuid":"c"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  string
+	}{
+		{"single file verbatim", []string{path("old.jsonl")}, `{"uuid":"a"}`},
+		{"joined by newlines", []string{path("old.jsonl"), path("new.jsonl"), path("agent.jsonl")}, "{\"uuid\":\"a\"}\n{\"uuid\":\"b\"}\n{\"uuid\":\"c\"}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readSources(tt.paths)
+			if err != nil {
+				t.Fatalf("readSources: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := readSources([]string{path("missing.jsonl")}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
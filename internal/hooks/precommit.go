@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/partio-io/cli/internal/agent"
 	"github.com/partio-io/cli/internal/agent/claude"
@@ -34,8 +35,33 @@ func runPreCommit(repoRoot string, cfg config.Config) error {
 	// otherwise auto-detect any running agent.
 	var detector agent.Detector
 	var running bool
+	var sessionPath, agentSessionID string
+	var reportedSession *session.Session
 
-	if cfg.Agent != "" {
+	// An agent that reports events through its hooks names its session and
+	// transcript; trust that over process detection while the session is open.
+	partioDir := filepath.Join(repoRoot, config.PartioDir)
+	mgr := session.NewManager(partioDir)
+	if threshold := cfg.StaleSessionThreshold.Duration(); threshold > 0 {
+		if _, err := mgr.CleanupStale(threshold); err != nil {
+			slog.Debug("could not clean up stale session", "error", err)
+		}
+	}
+	if quiet := cfg.IdleThreshold.Duration(); quiet > 0 {
+		if _, err := mgr.DetectIdle(quiet, time.Now()); err != nil {
+			slog.Debug("could not detect idle sessions", "error", err)
+		}
+	}
+	if reported := linkableSessions(reportedSessions(repoRoot, ""), cfg); len(reported) > 0 {
+		rs := reported[0]
+		if d, err := agent.NewDetector(rs.Agent); err == nil {
+			detector, running, reportedSession = d, true, rs
+			sessionPath, agentSessionID = rs.TranscriptPath, rs.AgentSessionID
+			slog.Debug("using agent-reported session", "agent", rs.Agent, "path", sessionPath, "open_sessions", len(reported))
+		}
+	}
+
+	if !running && cfg.Agent != "" {
 		d, err := agent.NewDetector(cfg.Agent)
 		if err != nil {
 			slog.Warn("unknown configured agent", "agent", cfg.Agent, "error", err)
@@ -60,12 +86,12 @@ func runPreCommit(repoRoot string, cfg config.Config) error {
 	}
 
 	// Check for condensed sessions (Claude-specific optimisation).
-	if running {
+	if running && sessionPath == "" {
 		if cd, ok := detector.(*claude.Detector); ok {
 			latestPath, pathErr := cd.FindLatestJSONLPath(repoRoot)
 			if pathErr == nil {
 				sid := claude.PeekSessionID(latestPath)
-				if shouldSkipSession(filepath.Join(repoRoot, config.PartioDir), sid, latestPath) {
+				if shouldSkipSession(repoRoot, session.Key{Agent: cd.Name(), AgentSessionID: sid, Worktree: repoRoot}, latestPath) {
 					slog.Debug("skipping already-condensed ended session", "session_id", sid)
 					running = false
 				}
@@ -74,14 +100,16 @@ func runPreCommit(repoRoot string, cfg config.Config) error {
 	}
 
 	// Find session data using the SessionParser interface (works for any agent).
-	var sessionPath string
-	if running {
+	if running && sessionPath == "" {
 		if sp, ok := detector.(agent.SessionParser); ok {
-			path, _, findErr := sp.FindLatestSession(repoRoot)
+			path, data, findErr := sp.FindLatestSession(repoRoot)
 			if findErr != nil {
 				slog.Debug("agent running but no session found", "agent", detector.Name(), "error", findErr)
 			} else {
 				sessionPath = path
+				if data != nil {
+					agentSessionID = data.SessionID
+				}
 				slog.Debug("agent session detected", "agent", detector.Name(), "path", path)
 			}
 		}
@@ -97,22 +125,32 @@ func runPreCommit(repoRoot string, cfg config.Config) error {
 		agentActive = running && sessionPath != ""
 	}
 
+	// A session whose transcript has been quiet for the idle threshold may
+	// be linked differently (idle_commit_linking).
+	idle := false
+	if reportedSession != nil {
+		idle = reportedSession.State == session.StateIdle
+	} else if sessionPath != "" {
+		idle = transcriptIdle(sessionPath, cfg.IdleThreshold.Duration())
+	}
+
 	// Check commit linking preference before saving state.
-	if agentActive && !shouldLinkCommit(repoRoot, cfg) {
+	if agentActive && !shouldLinkCommit(repoRoot, cfg, idle) {
 		agentActive = false
 	}
 
 	// Record an ACTIVE session with the agent's PID so a crashed or abandoned
 	// agent leaves a session that stale-cleanup can later detect and end.
-	if agentActive {
+	// An idle session is left IDLE.
+	if agentActive && !idle {
 		pid := 0
 		if pp, ok := detector.(agent.PIDProvider); ok {
 			if p, found := pp.AgentPID(); found {
 				pid = p
 			}
 		}
-		mgr := session.NewManager(filepath.Join(repoRoot, config.PartioDir))
-		if recErr := mgr.RecordActive(detector.Name(), branch, repoRoot, pid); recErr != nil {
+		key := session.Key{Agent: detector.Name(), AgentSessionID: agentSessionID, Worktree: repoRoot}
+		if recErr := mgr.RecordActive(key, branch, pid); recErr != nil {
 			slog.Debug("could not record active session", "error", recErr)
 		}
 	}
@@ -126,7 +164,7 @@ func runPreCommit(repoRoot string, cfg config.Config) error {
 	}
 
 	// Save state for post-commit
-	stateDir := filepath.Join(repoRoot, config.PartioDir, "state")
+	stateDir := filepath.Join(partioDir, "state")
 	if err := os.MkdirAll(stateDir, 0o755); err != nil {
 		return err
 	}
@@ -140,21 +178,22 @@ func runPreCommit(repoRoot string, cfg config.Config) error {
 }
 
 // shouldSkipSession returns true when the Partio session state shows that
-// sessionID has already been fully captured (condensed + ended) and the JSONL
-// at sessionPath has not been modified since the capture time. Both ENDED and
-// Condensed must be set; IDLE or ACTIVE sessions are never skipped.
-func shouldSkipSession(partioDir, sessionID, sessionPath string) bool {
-	if sessionID == "" || sessionPath == "" {
+// the agent session identified by key has already been fully captured
+// (condensed + ended) and the JSONL at sessionPath has not been modified
+// since the capture time. Both ENDED and Condensed must be set; IDLE or
+// ACTIVE sessions are never skipped.
+func shouldSkipSession(repoRoot string, key session.Key, sessionPath string) bool {
+	if key.AgentSessionID == "" || sessionPath == "" {
 		return false
 	}
 
-	mgr := session.NewManager(partioDir)
-	sess, err := mgr.Current()
+	mgr := session.NewManager(filepath.Join(repoRoot, config.PartioDir))
+	sess, err := mgr.Find(key)
 	if err != nil || sess == nil {
 		return false
 	}
 
-	if sess.State != session.StateEnded || !sess.Condensed || sess.CapturedSessionID != sessionID {
+	if sess.State != session.StateEnded || !sess.Condensed || sess.CapturedSessionID != key.AgentSessionID {
 		return false
 	}
 
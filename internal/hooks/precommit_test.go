@@ -6,22 +6,27 @@ import (
 	"testing"
 	"time"
 
+	"github.com/partio-io/cli/internal/config"
 	"github.com/partio-io/cli/internal/session"
 )
 
+func claudeKey(repoRoot, id string) session.Key {
+	return session.Key{Agent: "claude-code", AgentSessionID: id, Worktree: repoRoot}
+}
+
 func TestShouldSkipSession(t *testing.T) {
 	tests := []struct {
 		name         string
-		setup        func(t *testing.T, partioDir, jsonlPath string)
+		setup        func(t *testing.T, repoRoot string)
 		sessionID    string
 		modifyJSONL  bool // touch JSONL after marking condensed to simulate new content
 		wantSkip     bool
 	}{
 		{
 			name: "skip when ended and condensed with matching session ID",
-			setup: func(t *testing.T, partioDir, jsonlPath string) {
-				mgr := session.NewManager(partioDir)
-				if err := mgr.MarkCondensed("sess-123"); err != nil {
+			setup: func(t *testing.T, repoRoot string) {
+				mgr := session.NewManager(filepath.Join(repoRoot, config.PartioDir))
+				if err := mgr.MarkCondensed(claudeKey(repoRoot, "sess-123")); err != nil {
 					t.Fatalf("MarkCondensed: %v", err)
 				}
 			},
@@ -30,9 +35,9 @@ func TestShouldSkipSession(t *testing.T) {
 		},
 		{
 			name: "do not skip when session ID does not match",
-			setup: func(t *testing.T, partioDir, jsonlPath string) {
-				mgr := session.NewManager(partioDir)
-				if err := mgr.MarkCondensed("sess-other"); err != nil {
+			setup: func(t *testing.T, repoRoot string) {
+				mgr := session.NewManager(filepath.Join(repoRoot, config.PartioDir))
+				if err := mgr.MarkCondensed(claudeKey(repoRoot, "sess-other")); err != nil {
 					t.Fatalf("MarkCondensed: %v", err)
 				}
 			},
@@ -41,15 +46,15 @@ func TestShouldSkipSession(t *testing.T) {
 		},
 		{
 			name:      "do not skip when no session state exists",
-			setup:     func(t *testing.T, partioDir, jsonlPath string) {},
+			setup:     func(t *testing.T, repoRoot string) {},
 			sessionID: "sess-123",
 			wantSkip:  false,
 		},
 		{
 			name: "do not skip when session is active (not ended)",
-			setup: func(t *testing.T, partioDir, jsonlPath string) {
-				mgr := session.NewManager(partioDir)
-				if _, err := mgr.Start("claude-code", "main", "/tmp/test"); err != nil {
+			setup: func(t *testing.T, repoRoot string) {
+				mgr := session.NewManager(filepath.Join(repoRoot, config.PartioDir))
+				if _, err := mgr.Start("claude-code", "main", repoRoot); err != nil {
 					t.Fatalf("Start: %v", err)
 				}
 				// Active session — do NOT mark condensed
@@ -59,9 +64,9 @@ func TestShouldSkipSession(t *testing.T) {
 		},
 		{
 			name: "do not skip when JSONL was modified after capture",
-			setup: func(t *testing.T, partioDir, jsonlPath string) {
-				mgr := session.NewManager(partioDir)
-				if err := mgr.MarkCondensed("sess-123"); err != nil {
+			setup: func(t *testing.T, repoRoot string) {
+				mgr := session.NewManager(filepath.Join(repoRoot, config.PartioDir))
+				if err := mgr.MarkCondensed(claudeKey(repoRoot, "sess-123")); err != nil {
 					t.Fatalf("MarkCondensed: %v", err)
 				}
 			},
@@ -71,7 +76,7 @@ func TestShouldSkipSession(t *testing.T) {
 		},
 		{
 			name:      "do not skip when session ID is empty",
-			setup:     func(t *testing.T, partioDir, jsonlPath string) {},
+			setup:     func(t *testing.T, repoRoot string) {},
 			sessionID: "",
 			wantSkip:  false,
 		},
@@ -79,7 +84,7 @@ func TestShouldSkipSession(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			partioDir := t.TempDir()
+			repoRoot := t.TempDir()
 			jsonlDir := t.TempDir()
 			jsonlPath := filepath.Join(jsonlDir, "session.jsonl")
 
@@ -87,7 +92,7 @@ func TestShouldSkipSession(t *testing.T) {
 				t.Fatalf("writing JSONL: %v", err)
 			}
 
-			tt.setup(t, partioDir, jsonlPath)
+			tt.setup(t, repoRoot)
 
 			if tt.modifyJSONL {
 				// Ensure modification time is strictly after CapturedAt by waiting a moment.
@@ -98,7 +103,7 @@ func TestShouldSkipSession(t *testing.T) {
 				}
 			}
 
-			got := shouldSkipSession(partioDir, tt.sessionID, jsonlPath)
+			got := shouldSkipSession(repoRoot, claudeKey(repoRoot, tt.sessionID), jsonlPath)
 			if got != tt.wantSkip {
 				t.Errorf("shouldSkipSession() = %v, want %v", got, tt.wantSkip)
 			}
@@ -1,26 +1,36 @@
 package hooks
 
 import (
+	"io"
 	"log/slog"
+	"regexp"
+	"strings"
 
+	"github.com/partio-io/cli/internal/checkpoint"
 	"github.com/partio-io/cli/internal/config"
 	"github.com/partio-io/cli/internal/git"
 )
 
-// PrePush runs pre-push hook logic.
-func (r *Runner) PrePush() error {
+// defaultRemote is used when neither config nor the hook arguments name a remote.
+const defaultRemote = "origin"
+
+// PrePush runs pre-push hook logic. args are the arguments git passes to the
+// pre-push hook (the remote name and URL) and stdin carries the ref updates
+// being pushed.
+func (r *Runner) PrePush(args []string, stdin io.Reader) error {
 	slog.Debug("pre-push hook running")
-	return runPrePush(r.repoRoot, r.cfg)
+	return runPrePush(r.repoRoot, r.cfg, args, stdin)
 }
 
-func runPrePush(repoRoot string, cfg config.Config) error {
+func runPrePush(repoRoot string, cfg config.Config, args []string, stdin io.Reader) error {
 	if !cfg.StrategyOptions.PushSessions {
 		slog.Debug("push_sessions disabled, skipping checkpoint push")
 		return nil
 	}
 
-	if !git.HasRemote() {
-		slog.Debug("no remote configured, skipping checkpoint push")
+	remote := resolveCheckpointRemote(cfg, args)
+	if !isRemoteURL(remote) && !git.HasRemote(remote) {
+		slog.Debug("checkpoint remote not configured, skipping checkpoint push", "remote", remote)
 		return nil
 	}
 
@@ -29,10 +39,139 @@ func runPrePush(repoRoot string, cfg config.Config) error {
 		return nil
 	}
 
-	if err := git.PushBranch("origin", git.CheckpointBranch); err != nil {
-		slog.Warn("could not push checkpoint branch", "error", err)
+	// Only checkpoints linked to the commits being pushed leave this clone, so
+	// sessions from local-only branches stay local.
+	pushRemote := defaultRemote
+	if len(args) > 0 && args[0] != "" {
+		pushRemote = args[0]
+	}
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	ids := pushedCheckpointIDs(store, parsePushUpdates(stdin), pushRemote)
+	if len(ids) == 0 {
+		slog.Debug("no checkpoints referenced by pushed commits, skipping checkpoint push")
+		return nil
+	}
+
+	// Publish on top of what the remote has now, so that checkpoints other
+	// clones published since the last push are kept and the push
+	// fast-forwards.
+	dst := CheckpointDestRef(cfg)
+	publishRef := publishRefFor(remote)
+	base := remoteRefFor(remote)
+	if _, err := git.FetchRef(remote, dst, base); err != nil {
+		slog.Warn("could not fetch remote checkpoints, skipping checkpoint push", "remote", remote, "error", err)
+		return nil
+	}
+
+	if legacy, err := store.Unmigrated(); err == nil && len(legacy) > 0 {
+		slog.Warn("checkpoints on the legacy branch are not pushed; run 'partio migrate'", "branch", git.LegacyCheckpointBranch, "count", len(legacy))
+	}
+	added, err := store.Publish(publishRef, base, ids)
+	if err != nil {
+		slog.Warn("could not prepare checkpoints for push", "error", err)
+		return nil
+	}
+	if added == 0 && !git.RefExists(publishRef) {
+		slog.Debug("pushed checkpoints not found locally, skipping checkpoint push")
+		return nil
+	}
+
+	if err := git.PushRefspec(remote, publishRef+":"+dst); err != nil {
+		slog.Warn("could not push checkpoint branch", "remote", remote, "error", err)
 		// Don't fail the push
 	}
 
 	return nil
 }
+
+// pushedCheckpointIDs collects the Partio-Checkpoint trailers of all commits
+// sent by updates. remote is the remote the hook was invoked for and is used to
+// exclude commits it already has when a new ref is created.
+func pushedCheckpointIDs(store *checkpoint.Store, updates []pushUpdate, remote string) []string {
+	tracking := "--remotes"
+	if !isRemoteURL(remote) {
+		tracking = "--remotes=" + remote
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, u := range updates {
+		if u.isDelete() {
+			continue
+		}
+		commits, err := store.CommitTrailers(u.revRange(tracking)...)
+		if err != nil && !isZeroSHA(u.RemoteSHA) {
+			// The remote tip may not exist locally; fall back to everything the
+			// remote-tracking refs don't already have.
+			commits, err = store.CommitTrailers(u.LocalSHA, "--not", tracking)
+		}
+		if err != nil {
+			slog.Debug("could not read checkpoint trailers", "ref", u.LocalRef, "error", err)
+			continue
+		}
+		for _, c := range commits {
+			for _, id := range c.IDs {
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// resolveCheckpointRemote picks the remote checkpoints are pushed to: the
+// configured checkpoint remote if set, otherwise the remote the pre-push hook
+// was invoked for, otherwise "origin".
+func resolveCheckpointRemote(cfg config.Config, args []string) string {
+	if cfg.StrategyOptions.CheckpointRemote != "" {
+		return cfg.StrategyOptions.CheckpointRemote
+	}
+	if len(args) > 0 && args[0] != "" {
+		return args[0]
+	}
+	return defaultRemote
+}
+
+// CheckpointDestRef returns the ref checkpoints are pushed to on the remote.
+func CheckpointDestRef(cfg config.Config) string {
+	if cfg.StrategyOptions.CheckpointRef != "" {
+		return cfg.StrategyOptions.CheckpointRef
+	}
+	return "refs/heads/" + git.CheckpointBranch
+}
+
+var unsafeRefChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// publishRefFor returns the local ref that accumulates the checkpoints
+// published to remote. Each remote gets its own ref so that pushing to one
+// never publishes checkpoints that were only pushed to another.
+func publishRefFor(remote string) string {
+	return checkpoint.PublishRefPrefix + refName(remote) + checkpoint.PublishRefSuffix
+}
+
+// remoteRefFor returns the local ref holding the remote's copy of the
+// checkpoints, as last fetched before publishing to it.
+func remoteRefFor(remote string) string {
+	return checkpoint.RemoteRefPrefix + refName(remote) + checkpoint.PublishRefSuffix
+}
+
+// refName turns a remote name or URL into a ref name component.
+func refName(remote string) string {
+	name := strings.Trim(unsafeRefChars.ReplaceAllString(remote, "_"), "._")
+	if name == "" {
+		name = defaultRemote
+	}
+	return name
+}
+
+// isRemoteURL reports whether remote looks like a URL or scp-style address
+// rather than the name of a configured remote.
+func isRemoteURL(remote string) bool {
+	return strings.Contains(remote, "://") ||
+		strings.Contains(remote, "@") ||
+		strings.HasPrefix(remote, "/") ||
+		strings.HasPrefix(remote, ".")
+}
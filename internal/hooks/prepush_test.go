@@ -0,0 +1,198 @@
+package hooks
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/config"
+)
+
+func TestResolveCheckpointRemote(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		args       []string
+		want       string
+	}{
+		{
+			name: "defaults to origin without hook args",
+			want: "origin",
+		},
+		{
+			name: "forwards the hook's remote argument",
+			args: []string{"upstream", "git@example.com:org/repo.git"},
+			want: "upstream",
+		},
+		{
+			name:       "configured remote overrides hook argument",
+			configured: "checkpoints",
+			args:       []string{"upstream", "git@example.com:org/repo.git"},
+			want:       "checkpoints",
+		},
+		{
+			name:       "configured URL is used as-is",
+			configured: "https://mirror.internal/org/repo.git",
+			args:       []string{"origin"},
+			want:       "https://mirror.internal/org/repo.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{StrategyOptions: config.StrategyOptions{CheckpointRemote: tt.configured}}
+			if got := resolveCheckpointRemote(cfg, tt.args); got != tt.want {
+				t.Errorf("resolveCheckpointRemote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckpointDestRef(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{
+			name: "pushes to the checkpoint branch by default",
+			want: "refs/heads/partio/checkpoints/v2",
+		},
+		{
+			name: "uses custom ref namespace",
+			ref:  "refs/partio/checkpoints/v1",
+			want: "refs/partio/checkpoints/v1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{StrategyOptions: config.StrategyOptions{CheckpointRef: tt.ref}}
+			if got := CheckpointDestRef(cfg); got != tt.want {
+				t.Errorf("CheckpointDestRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishRefFor(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"origin", "refs/partio/published/origin/v2"},
+		{"git@example.com:org/repo.git", "refs/partio/published/git_example.com_org_repo.git/v2"},
+		{"https://example.com/org/repo.git", "refs/partio/published/https_example.com_org_repo.git/v2"},
+		{"..", "refs/partio/published/origin/v2"},
+	}
+
+	for _, tt := range tests {
+		if got := publishRefFor(tt.remote); got != tt.want {
+			t.Errorf("publishRefFor(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+}
+
+func TestIsRemoteURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   bool
+	}{
+		{"origin", false},
+		{"internal-mirror", false},
+		{"https://example.com/org/repo.git", true},
+		{"git@example.com:org/repo.git", true},
+		{"/srv/git/repo.git", true},
+		{"../repo.git", true},
+	}
+
+	for _, tt := range tests {
+		if got := isRemoteURL(tt.remote); got != tt.want {
+			t.Errorf("isRemoteURL(%q) = %v, want %v", tt.remote, got, tt.want)
+		}
+	}
+}
+
+// gitIn runs git in dir and returns its trimmed output.
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// pushFromClone captures checkpoint id in clone, commits a change linking to
+// it and runs the pre-push hook for pushing that commit to origin.
+func pushFromClone(t *testing.T, clone string, cfg config.Config, id string) {
+	t.Helper()
+	store := checkpoint.NewStore(clone)
+	cp := &checkpoint.Checkpoint{ID: id, Branch: "main", CreatedAt: time.Now()}
+	if err := store.Write(cp, &checkpoint.SessionFiles{Prompt: "prompt " + id}); err != nil {
+		t.Fatalf("Write(%s): %v", id, err)
+	}
+	gitIn(t, clone, "commit", "--allow-empty", "-q", "-m", "change", "-m", checkpoint.Trailer+": "+id)
+	head := gitIn(t, clone, "rev-parse", "HEAD")
+
+	t.Chdir(clone)
+	stdin := strings.NewReader("refs/heads/main " + head + " refs/heads/main " + strings.Repeat("0", 40) + "\n")
+	if err := runPrePush(clone, cfg, []string{"origin"}, stdin); err != nil {
+		t.Fatalf("runPrePush: %v", err)
+	}
+}
+
+func TestRunPrePushFromTwoClones(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	tests := []struct {
+		name string
+		ref  string
+	}{
+		{name: "checkpoint branch"},
+		{name: "custom ref", ref: "refs/partio/checkpoints/v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			remote := filepath.Join(root, "remote.git")
+			gitIn(t, root, "init", "-q", "--bare", remote)
+
+			var clones []string
+			for _, name := range []string{"a", "b"} {
+				clone := filepath.Join(root, name)
+				gitIn(t, root, "init", "-q", "-b", "main", clone)
+				gitIn(t, clone, "remote", "add", "origin", remote)
+				clones = append(clones, clone)
+			}
+
+			cfg := config.Config{StrategyOptions: config.StrategyOptions{PushSessions: true, CheckpointRef: tt.ref}}
+			dst := CheckpointDestRef(cfg)
+
+			// Each push after the first starts from a remote another clone
+			// has published to since.
+			pushFromClone(t, clones[0], cfg, "aa0000000001")
+			pushFromClone(t, clones[1], cfg, "bb0000000002")
+			pushFromClone(t, clones[0], cfg, "aa0000000003")
+
+			files := gitIn(t, remote, "ls-tree", "-r", "--name-only", dst)
+			for _, path := range []string{"aa/0000000001/metadata.json", "bb/0000000002/metadata.json", "aa/0000000003/metadata.json"} {
+				if !strings.Contains(files, path) {
+					t.Errorf("remote %s is missing %s:\n%s", dst, path, files)
+				}
+			}
+			if roots := gitIn(t, remote, "rev-list", "--max-parents=0", dst); strings.Count(roots, "\n") != 0 {
+				t.Errorf("remote %s has unrelated histories, roots:\n%s", dst, roots)
+			}
+		})
+	}
+}
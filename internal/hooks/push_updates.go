@@ -0,0 +1,56 @@
+package hooks
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// pushUpdate is a single ref update passed to the pre-push hook on stdin.
+type pushUpdate struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+// parsePushUpdates reads the "<local ref> <local sha> <remote ref> <remote sha>"
+// lines git writes to the pre-push hook's stdin. Malformed lines are skipped.
+func parsePushUpdates(r io.Reader) []pushUpdate {
+	var updates []pushUpdate
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		updates = append(updates, pushUpdate{
+			LocalRef:  fields[0],
+			LocalSHA:  fields[1],
+			RemoteRef: fields[2],
+			RemoteSHA: fields[3],
+		})
+	}
+	return updates
+}
+
+// isDelete reports whether the update deletes the remote ref.
+func (u pushUpdate) isDelete() bool {
+	return isZeroSHA(u.LocalSHA)
+}
+
+// revRange returns git log revision arguments selecting the commits this
+// update sends to the remote. For new remote refs, commits already reachable
+// from remoteTrackingGlob (e.g. "--remotes=origin") are excluded.
+func (u pushUpdate) revRange(remoteTrackingGlob string) []string {
+	if isZeroSHA(u.RemoteSHA) {
+		return []string{u.LocalSHA, "--not", remoteTrackingGlob}
+	}
+	return []string{u.RemoteSHA + ".." + u.LocalSHA}
+}
+
+// isZeroSHA reports whether sha is git's all-zero object name, used for refs
+// that do not exist on one side of the push.
+func isZeroSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
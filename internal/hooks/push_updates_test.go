@@ -0,0 +1,70 @@
+package hooks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePushUpdates(t *testing.T) {
+	input := `refs/heads/main 1111111111111111111111111111111111111111 refs/heads/main 2222222222222222222222222222222222222222
+malformed line
+refs/heads/feature 3333333333333333333333333333333333333333 refs/heads/feature 0000000000000000000000000000000000000000
+`
+	got := parsePushUpdates(strings.NewReader(input))
+	want := []pushUpdate{
+		{
+			LocalRef:  "refs/heads/main",
+			LocalSHA:  "1111111111111111111111111111111111111111",
+			RemoteRef: "refs/heads/main",
+			RemoteSHA: "2222222222222222222222222222222222222222",
+		},
+		{
+			LocalRef:  "refs/heads/feature",
+			LocalSHA:  "3333333333333333333333333333333333333333",
+			RemoteRef: "refs/heads/feature",
+			RemoteSHA: "0000000000000000000000000000000000000000",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePushUpdates() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPushUpdateRevRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		update pushUpdate
+		want   []string
+	}{
+		{
+			name:   "existing remote ref uses remote..local",
+			update: pushUpdate{LocalSHA: "aaa", RemoteSHA: "bbb"},
+			want:   []string{"bbb..aaa"},
+		},
+		{
+			name:   "new remote ref excludes remote-tracking commits",
+			update: pushUpdate{LocalSHA: "aaa", RemoteSHA: "0000000000000000000000000000000000000000"},
+			want:   []string{"aaa", "--not", "--remotes=origin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.update.revRange("--remotes=origin"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("revRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushUpdateIsDelete(t *testing.T) {
+	del := pushUpdate{LocalSHA: "0000000000000000000000000000000000000000", RemoteSHA: "abc"}
+	if !del.isDelete() {
+		t.Error("expected zero local SHA to be a delete")
+	}
+	upd := pushUpdate{LocalSHA: "abc", RemoteSHA: "def"}
+	if upd.isDelete() {
+		t.Error("expected non-zero local SHA not to be a delete")
+	}
+}
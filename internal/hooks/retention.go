@@ -0,0 +1,91 @@
+package hooks
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/partio-io/cli/internal/checkpoint"
+	"github.com/partio-io/cli/internal/config"
+	"github.com/partio-io/cli/internal/git"
+)
+
+const retentionStateFile = "retention.json"
+
+type retentionState struct {
+	LastRun time.Time `json:"last_run"`
+}
+
+// RetentionPolicy converts the retention config into a checkpoint policy,
+// resolving the main branch when keep_if_on_main is set.
+func RetentionPolicy(opts config.RetentionOptions) checkpoint.RetentionPolicy {
+	policy := checkpoint.RetentionPolicy{
+		Default: checkpoint.RetentionRule{
+			MaxAge:   opts.MaxAge.Duration(),
+			MaxCount: opts.MaxCount,
+		},
+		MaxTotalSize: int64(opts.MaxTotalSize),
+	}
+	for _, b := range opts.Branches {
+		policy.Branches = append(policy.Branches, checkpoint.BranchRetention{
+			Pattern: b.Pattern,
+			Rule: checkpoint.RetentionRule{
+				MaxAge:   b.MaxAge.Duration(),
+				MaxCount: b.MaxCount,
+				Keep:     b.Keep,
+			},
+		})
+	}
+
+	if opts.KeepIfOnMain {
+		switch {
+		case opts.MainBranch != "":
+			policy.MainBranch = opts.MainBranch
+		case git.BranchExists("main"):
+			policy.MainBranch = "main"
+		case git.BranchExists("master"):
+			policy.MainBranch = "master"
+		}
+	}
+	return policy
+}
+
+// maybeApplyRetention applies the configured retention policy at most once
+// per interval. Failures are logged, never returned: retention must not
+// break a commit.
+func maybeApplyRetention(repoRoot string, cfg config.Config, currentCommit string) {
+	opts := cfg.Retention
+	if !opts.Auto || !opts.Configured() {
+		return
+	}
+
+	statePath := filepath.Join(repoRoot, config.PartioDir, "state", retentionStateFile)
+	var state retentionState
+	if data, err := os.ReadFile(statePath); err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	if time.Since(state.LastRun) < opts.Interval.Duration() {
+		return
+	}
+
+	// Record the run first so a failing policy isn't retried on every commit.
+	state.LastRun = time.Now()
+	if err := os.MkdirAll(filepath.Dir(statePath), 0o755); err == nil {
+		if data, err := json.Marshal(state); err == nil {
+			_ = os.WriteFile(statePath, data, 0o644)
+		}
+	}
+
+	store := checkpoint.NewStore(repoRoot)
+	store.SetSigning(cfg.SignCheckpoints)
+	result, err := store.ApplyRetention(RetentionPolicy(opts), currentCommit, false)
+	if err != nil {
+		slog.Warn("post-commit: could not apply retention policy", "error", err)
+		return
+	}
+	if len(result.Removed) > 0 {
+		slog.Info("retention policy removed checkpoints", "removed", len(result.Removed), "kept", len(result.Kept))
+	}
+}
@@ -9,10 +9,12 @@ import (
 type Runner struct {
 	cfg      config.Config
 	repoRoot string
+	version  string
 }
 
-// NewRunner creates a new hook runner.
-func NewRunner(cfg config.Config) (*Runner, error) {
+// NewRunner creates a new hook runner. version is the running partio version,
+// recorded in checkpoint metadata.
+func NewRunner(cfg config.Config, version string) (*Runner, error) {
 	repoRoot, err := git.RepoRoot()
 	if err != nil {
 		return nil, err
@@ -21,5 +23,6 @@ func NewRunner(cfg config.Config) (*Runner, error) {
 	return &Runner{
 		cfg:      cfg,
 		repoRoot: repoRoot,
+		version:  version,
 	}, nil
 }
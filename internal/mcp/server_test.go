@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	echo := Tool{
+		Name:        "echo",
+		Description: "Echoes its text argument.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}}}`),
+		Handler: func(args json.RawMessage) (string, error) {
+			var a struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(args, &a); err != nil {
+				return "", err
+			}
+			if a.Text == "" {
+				return "", errors.New("text is required")
+			}
+			return a.Text, nil
+		},
+	}
+	srv := NewServer("partio", "1.2.3", []Tool{echo})
+
+	tests := []struct {
+		name    string
+		request string
+		want    string // "" means no response
+	}{
+		{
+			name:    "initialize with a known version",
+			request: `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"0"}}}`,
+			want:    `{"jsonrpc":"2.0","id":1,"result":{"capabilities":{"tools":{}},"protocolVersion":"2025-03-26","serverInfo":{"name":"partio","version":"1.2.3"}}}`,
+		},
+		{
+			name:    "initialize with an unknown version",
+			request: `{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+			want:    `{"jsonrpc":"2.0","id":2,"result":{"capabilities":{"tools":{}},"protocolVersion":"2025-06-18","serverInfo":{"name":"partio","version":"1.2.3"}}}`,
+		},
+		{
+			name:    "notification",
+			request: `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		},
+		{
+			name:    "ping",
+			request: `{"jsonrpc":"2.0","id":"p","method":"ping"}`,
+			want:    `{"jsonrpc":"2.0","id":"p","result":{}}`,
+		},
+		{
+			name:    "list tools",
+			request: `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`,
+			want:    `{"jsonrpc":"2.0","id":3,"result":{"tools":[{"name":"echo","description":"Echoes its text argument.","inputSchema":{"type":"object","properties":{"text":{"type":"string"}}}}]}}`,
+		},
+		{
+			name:    "call tool",
+			request: `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+			want:    `{"jsonrpc":"2.0","id":4,"result":{"content":[{"type":"text","text":"hi"}]}}`,
+		},
+		{
+			name:    "tool error",
+			request: `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"echo"}}`,
+			want:    `{"jsonrpc":"2.0","id":5,"result":{"content":[{"type":"text","text":"text is required"}],"isError":true}}`,
+		},
+		{
+			name:    "unknown tool",
+			request: `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"nope"}}`,
+			want:    `{"jsonrpc":"2.0","id":6,"error":{"code":-32602,"message":"unknown tool: nope"}}`,
+		},
+		{
+			name:    "unknown method",
+			request: `{"jsonrpc":"2.0","id":7,"method":"resources/list"}`,
+			want:    `{"jsonrpc":"2.0","id":7,"error":{"code":-32601,"message":"method not found: resources/list"}}`,
+		},
+		{
+			name:    "parse error",
+			request: `{"jsonrpc":`,
+			want:    `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`,
+		},
+		{
+			name:    "invalid request",
+			request: `{"id":8,"method":"ping"}`,
+			want:    `{"jsonrpc":"2.0","id":8,"error":{"code":-32600,"message":"invalid request"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := srv.Serve(strings.NewReader(tt.request+"\n"), &out); err != nil {
+				t.Fatalf("Serve: %v", err)
+			}
+			got := strings.TrimSpace(out.String())
+			if got != tt.want {
+				t.Errorf("response =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeSession(t *testing.T) {
+	srv := NewServer("partio", "dev", nil)
+	in := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}` + "\n\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}` // no trailing newline
+
+	var out strings.Builder
+	if err := srv.Serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"id":1`) || lines[1] != `{"jsonrpc":"2.0","id":2,"result":{}}` {
+		t.Errorf("responses:\n%s", out.String())
+	}
+}
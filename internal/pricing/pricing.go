@@ -0,0 +1,66 @@
+// Package pricing estimates what agent sessions cost from their token usage.
+package pricing
+
+import "strings"
+
+// Price is the cost in USD per million tokens of each kind.
+type Price struct {
+	Input         float64 `json:"input"`
+	Output        float64 `json:"output"`
+	CacheCreation float64 `json:"cache_creation"`
+	CacheRead     float64 `json:"cache_read"`
+}
+
+// Cost returns the estimated cost in USD of the given token counts.
+func (p Price) Cost(input, output, cacheCreation, cacheRead int) float64 {
+	return (float64(input)*p.Input +
+		float64(output)*p.Output +
+		float64(cacheCreation)*p.CacheCreation +
+		float64(cacheRead)*p.CacheRead) / 1e6
+}
+
+// Table maps model names to prices. A model matches the key equal to its
+// name or, failing that, the longest key it starts with, so
+// "claude-sonnet-4" also prices "claude-sonnet-4-20250514".
+type Table map[string]Price
+
+// Lookup returns the price of model.
+func (t Table) Lookup(model string) (Price, bool) {
+	if p, ok := t[model]; ok {
+		return p, true
+	}
+	best := ""
+	for key := range t {
+		if strings.HasPrefix(model, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// Default returns the built-in list prices. They are estimates; override or
+// extend them with the "pricing" setting.
+func Default() Table {
+	return Table{
+		// Anthropic
+		"claude-opus-4":     {Input: 15, Output: 75, CacheCreation: 18.75, CacheRead: 1.50},
+		"claude-opus-4-5":   {Input: 5, Output: 25, CacheCreation: 6.25, CacheRead: 0.50},
+		"claude-sonnet-4":   {Input: 3, Output: 15, CacheCreation: 3.75, CacheRead: 0.30},
+		"claude-3-7-sonnet": {Input: 3, Output: 15, CacheCreation: 3.75, CacheRead: 0.30},
+		"claude-3-5-sonnet": {Input: 3, Output: 15, CacheCreation: 3.75, CacheRead: 0.30},
+		"claude-haiku-4-5":  {Input: 1, Output: 5, CacheCreation: 1.25, CacheRead: 0.10},
+		"claude-3-5-haiku":  {Input: 0.80, Output: 4, CacheCreation: 1, CacheRead: 0.08},
+
+		// OpenAI (cached input has no write surcharge)
+		"gpt-5":             {Input: 1.25, Output: 10, CacheRead: 0.125},
+		"gpt-5-mini":        {Input: 0.25, Output: 2, CacheRead: 0.025},
+		"gpt-4.1":           {Input: 2, Output: 8, CacheRead: 0.50},
+		"o3":                {Input: 2, Output: 8, CacheRead: 0.50},
+		"o3-mini":           {Input: 1.10, Output: 4.40, CacheRead: 0.55},
+		"o4-mini":           {Input: 1.10, Output: 4.40, CacheRead: 0.275},
+		"codex-mini-latest": {Input: 1.50, Output: 6, CacheRead: 0.375},
+	}
+}
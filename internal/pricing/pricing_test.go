@@ -0,0 +1,39 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	table := Default()
+
+	tests := []struct {
+		model string
+		want  float64 // input price
+		ok    bool
+	}{
+		{"claude-sonnet-4-5-20250929", 3, true},
+		{"claude-opus-4-1-20250805", 15, true},
+		{"claude-opus-4-5-20251101", 5, true},
+		{"gpt-5-codex", 1.25, true},
+		{"gpt-5-mini", 0.25, true},
+		{"mystery-model", 0, false},
+	}
+
+	for _, tt := range tests {
+		p, ok := table.Lookup(tt.model)
+		if ok != tt.ok || p.Input != tt.want {
+			t.Errorf("Lookup(%q) = (%v, %v), want input %v, %v", tt.model, p, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	p := Price{Input: 3, Output: 15, CacheCreation: 3.75, CacheRead: 0.30}
+	got := p.Cost(1_000_000, 100_000, 200_000, 2_000_000)
+	want := 3 + 1.5 + 0.75 + 0.6
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost() = %v, want %v", got, want)
+	}
+}
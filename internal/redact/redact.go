@@ -78,6 +78,8 @@ func SessionFiles(sf *checkpoint.SessionFiles, opts Options) *checkpoint.Session
 	sf.Diff = Text(sf.Diff, opts)
 	sf.FullJSONL = Text(sf.FullJSONL, opts)
 	sf.Plan = Text(sf.Plan, opts)
+	sf.Tools = Text(sf.Tools, opts)
+	sf.Transcript = Text(sf.Transcript, opts)
 	return sf
 }
 
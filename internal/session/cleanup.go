@@ -9,13 +9,11 @@ import (
 
 // CleanupResult holds the outcome of a stale-session cleanup run.
 type CleanupResult struct {
-	// Cleaned is true when a stale session was found and transitioned to ENDED.
-	Cleaned bool
-	// Session is the session that was cleaned up, or nil if none.
-	Session *Session
+	// Cleaned lists the stale sessions that were transitioned to ENDED.
+	Cleaned []*Session
 }
 
-// CleanupStale checks the current session and, if it is stale, transitions it
+// CleanupStale checks every tracked session and transitions the stale ones
 // to ENDED state. A session is considered stale when:
 //   - Its state is ACTIVE or IDLE, and
 //   - The session state file has not been updated within threshold, and
@@ -23,37 +21,36 @@ type CleanupResult struct {
 //
 // Returns a CleanupResult describing what happened.
 func (m *Manager) CleanupStale(threshold time.Duration) (CleanupResult, error) {
-	s, err := m.Current()
+	sessions, err := m.List()
 	if err != nil {
 		return CleanupResult{}, err
 	}
-	if s == nil {
-		return CleanupResult{}, nil
-	}
-
-	// Only clean ACTIVE or IDLE sessions — ENDED sessions are already done.
-	if s.State != StateActive && s.State != StateIdle {
-		return CleanupResult{}, nil
-	}
 
-	if !isStale(m.currentPath(), s, threshold) {
-		return CleanupResult{}, nil
-	}
+	var result CleanupResult
+	for _, s := range sessions {
+		// Only clean ACTIVE or IDLE sessions — ENDED sessions are already done.
+		if s.State != StateActive && s.State != StateIdle {
+			continue
+		}
+		if !isStale(m.path(s.ID), s, threshold) {
+			continue
+		}
 
-	slog.Info("cleaning up stale session",
-		"id", s.ID,
-		"agent", s.Agent,
-		"state", s.State,
-		"pid", s.AgentPID,
-	)
+		slog.Info("cleaning up stale session",
+			"id", s.ID,
+			"agent", s.Agent,
+			"state", s.State,
+			"pid", s.AgentPID,
+		)
 
-	s.State = StateEnded
-	s.EndedAt = time.Now()
-	if err := m.save(s); err != nil {
-		return CleanupResult{}, err
+		s.State = StateEnded
+		s.EndedAt = time.Now()
+		if err := m.save(s); err != nil {
+			return result, err
+		}
+		result.Cleaned = append(result.Cleaned, s)
 	}
-
-	return CleanupResult{Cleaned: true, Session: s}, nil
+	return result, nil
 }
 
 // isStale returns true when the session file is old enough AND (if PID is known)
@@ -72,16 +69,16 @@ func isStale(path string, s *Session, threshold time.Duration) bool {
 
 	// File is old enough. If we have a PID, require the process to be gone too.
 	if s.AgentPID > 0 {
-		return !isProcessAlive(s.AgentPID)
+		return !ProcessAlive(s.AgentPID)
 	}
 
 	// No PID recorded — rely on timestamp alone.
 	return true
 }
 
-// isProcessAlive returns true if the process with the given PID is still alive.
+// ProcessAlive returns true if the process with the given PID is still alive.
 // Uses signal 0, which checks process existence without sending an actual signal.
-func isProcessAlive(pid int) bool {
+func ProcessAlive(pid int) bool {
 	err := syscall.Kill(pid, 0)
 	return err == nil
 }
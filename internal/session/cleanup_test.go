@@ -14,7 +14,7 @@ func TestCleanupStale_NoSession(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if result.Cleaned {
+	if len(result.Cleaned) > 0 {
 		t.Error("expected no cleanup when there is no session")
 	}
 }
@@ -23,17 +23,17 @@ func TestCleanupStale_EndedSession(t *testing.T) {
 	dir := t.TempDir()
 	mgr := NewManager(dir)
 
-	_, err := mgr.Start("claude-code", "main", "/tmp/test")
+	s, err := mgr.Start("claude-code", "main", "/tmp/test")
 	if err != nil {
 		t.Fatalf("start error: %v", err)
 	}
-	if err := mgr.End(); err != nil {
+	if err := mgr.End(s.ID); err != nil {
 		t.Fatalf("end error: %v", err)
 	}
 
 	// Backdate the file so it looks old.
 	past := time.Now().Add(-20 * time.Minute)
-	if err := os.Chtimes(mgr.currentPath(), past, past); err != nil {
+	if err := os.Chtimes(mgr.path(s.ID), past, past); err != nil {
 		t.Fatalf("chtimes error: %v", err)
 	}
 
@@ -41,7 +41,7 @@ func TestCleanupStale_EndedSession(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if result.Cleaned {
+	if len(result.Cleaned) > 0 {
 		t.Error("should not clean up an already-ended session")
 	}
 }
@@ -50,8 +50,7 @@ func TestCleanupStale_RecentActiveSession(t *testing.T) {
 	dir := t.TempDir()
 	mgr := NewManager(dir)
 
-	_, err := mgr.Start("claude-code", "main", "/tmp/test")
-	if err != nil {
+	if _, err := mgr.Start("claude-code", "main", "/tmp/test"); err != nil {
 		t.Fatalf("start error: %v", err)
 	}
 
@@ -60,7 +59,7 @@ func TestCleanupStale_RecentActiveSession(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if result.Cleaned {
+	if len(result.Cleaned) > 0 {
 		t.Error("should not clean up a recently updated session")
 	}
 
@@ -74,14 +73,14 @@ func TestCleanupStale_OldActiveSession_NoPID(t *testing.T) {
 	dir := t.TempDir()
 	mgr := NewManager(dir)
 
-	_, err := mgr.Start("claude-code", "main", "/tmp/test")
+	s, err := mgr.Start("claude-code", "main", "/tmp/test")
 	if err != nil {
 		t.Fatalf("start error: %v", err)
 	}
 
 	// Backdate the state file so it appears stale.
 	past := time.Now().Add(-20 * time.Minute)
-	if err := os.Chtimes(mgr.currentPath(), past, past); err != nil {
+	if err := os.Chtimes(mgr.path(s.ID), past, past); err != nil {
 		t.Fatalf("chtimes error: %v", err)
 	}
 
@@ -89,12 +88,9 @@ func TestCleanupStale_OldActiveSession_NoPID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if !result.Cleaned {
+	if len(result.Cleaned) == 0 {
 		t.Error("expected stale session to be cleaned up")
 	}
-	if result.Session == nil {
-		t.Fatal("expected session to be set in result")
-	}
 
 	cur, _ := mgr.Current()
 	if cur.State != StateEnded {
@@ -119,7 +115,7 @@ func TestCleanupStale_OldIdleSession_NoPID(t *testing.T) {
 	}
 
 	past := time.Now().Add(-20 * time.Minute)
-	if err := os.Chtimes(mgr.currentPath(), past, past); err != nil {
+	if err := os.Chtimes(mgr.path(s.ID), past, past); err != nil {
 		t.Fatalf("chtimes error: %v", err)
 	}
 
@@ -127,7 +123,7 @@ func TestCleanupStale_OldIdleSession_NoPID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if !result.Cleaned {
+	if len(result.Cleaned) == 0 {
 		t.Error("expected stale idle session to be cleaned up")
 	}
 
@@ -153,7 +149,7 @@ func TestCleanupStale_OldActiveSession_AlivePID(t *testing.T) {
 	}
 
 	past := time.Now().Add(-20 * time.Minute)
-	if err := os.Chtimes(mgr.currentPath(), past, past); err != nil {
+	if err := os.Chtimes(mgr.path(s.ID), past, past); err != nil {
 		t.Fatalf("chtimes error: %v", err)
 	}
 
@@ -161,7 +157,7 @@ func TestCleanupStale_OldActiveSession_AlivePID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if result.Cleaned {
+	if len(result.Cleaned) > 0 {
 		t.Error("should not clean up a session whose process is still alive")
 	}
 
@@ -190,7 +186,7 @@ func TestCleanupStale_OldActiveSession_DeadPID(t *testing.T) {
 	}
 
 	past := time.Now().Add(-20 * time.Minute)
-	if err := os.Chtimes(mgr.currentPath(), past, past); err != nil {
+	if err := os.Chtimes(mgr.path(s.ID), past, past); err != nil {
 		t.Fatalf("chtimes error: %v", err)
 	}
 
@@ -198,7 +194,7 @@ func TestCleanupStale_OldActiveSession_DeadPID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if !result.Cleaned {
+	if len(result.Cleaned) == 0 {
 		t.Error("expected session with dead PID to be cleaned up")
 	}
 
@@ -207,3 +203,50 @@ func TestCleanupStale_OldActiveSession_DeadPID(t *testing.T) {
 		t.Errorf("expected state=ended, got %s", cur.State)
 	}
 }
+
+func TestCleanupStale_MultipleSessions(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(dir)
+
+	past := time.Now().Add(-20 * time.Minute)
+	var stale []*Session
+	for _, worktree := range []string{"/tmp/a", "/tmp/b"} {
+		s, err := mgr.Start("claude-code", "main", worktree)
+		if err != nil {
+			t.Fatalf("start error: %v", err)
+		}
+		if err := os.Chtimes(mgr.path(s.ID), past, past); err != nil {
+			t.Fatalf("chtimes error: %v", err)
+		}
+		stale = append(stale, s)
+	}
+	fresh, err := mgr.Start("codex", "main", "/tmp/a")
+	if err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+
+	result, err := mgr.CleanupStale(10 * time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Cleaned) != 2 {
+		t.Fatalf("cleaned %d sessions, want 2", len(result.Cleaned))
+	}
+
+	for _, s := range stale {
+		got, err := mgr.Get(s.ID)
+		if err != nil || got == nil {
+			t.Fatalf("get %s: %v, %v", s.ID, got, err)
+		}
+		if got.State != StateEnded {
+			t.Errorf("session %s: state=%s, want ended", s.ID, got.State)
+		}
+	}
+	got, err := mgr.Get(fresh.ID)
+	if err != nil || got == nil {
+		t.Fatalf("get %s: %v, %v", fresh.ID, got, err)
+	}
+	if got.State != StateActive {
+		t.Errorf("fresh session: state=%s, want active", got.State)
+	}
+}
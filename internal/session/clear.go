@@ -2,7 +2,7 @@ package session
 
 import "os"
 
-// Clear removes the current session state file.
+// Clear removes all session state.
 func (m *Manager) Clear() error {
-	return os.Remove(m.currentPath())
+	return os.RemoveAll(m.stateDir)
 }
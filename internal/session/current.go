@@ -4,22 +4,103 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"time"
 )
 
-// Current returns the active session, or nil if none.
-func (m *Manager) Current() (*Session, error) {
-	path := m.currentPath()
-	data, err := os.ReadFile(path)
+// Get returns the session id, or nil if it is not tracked.
+func (m *Manager) Get(id string) (*Session, error) {
+	data, err := os.ReadFile(m.path(id))
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("reading session state: %w", err)
 	}
-
 	var s Session
 	if err := json.Unmarshal(data, &s); err != nil {
 		return nil, fmt.Errorf("parsing session state: %w", err)
 	}
 	return &s, nil
 }
+
+// Find returns the session stored under key, or nil if there is none.
+func (m *Manager) Find(key Key) (*Session, error) {
+	idx, err := m.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	id := idx.lookup(key)
+	if id == "" {
+		return nil, nil
+	}
+	s, err := m.Get(id)
+	if s == nil && err == nil {
+		// The index is stale; rebuild it and look again.
+		if idx, err = m.rebuildIndex(); err != nil {
+			return nil, err
+		}
+		if id = idx.lookup(key); id != "" {
+			return m.Get(id)
+		}
+	}
+	return s, err
+}
+
+// List returns every tracked session, most recently updated first.
+func (m *Manager) List() ([]*Session, error) {
+	if err := m.migrateLegacy(); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(m.stateDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading session directory: %w", err)
+	}
+
+	var sessions []*Session
+	updated := make(map[string]time.Time)
+	for _, e := range entries {
+		if e.IsDir() || !isSessionFile(e.Name()) {
+			continue
+		}
+		s, err := m.Get(e.Name()[:len(e.Name())-len(".json")])
+		if err != nil || s == nil {
+			continue // unreadable or removed meanwhile
+		}
+		if info, err := e.Info(); err == nil {
+			updated[s.ID] = info.ModTime()
+		}
+		sessions = append(sessions, s)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return updated[sessions[i].ID].After(updated[sessions[j].ID])
+	})
+	return sessions, nil
+}
+
+// Current returns the most recently updated open session, or the most
+// recently updated session when none is open, or nil if none is tracked.
+func (m *Manager) Current() (*Session, error) {
+	sessions, err := m.List()
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+	for _, s := range sessions {
+		if s.State != StateEnded {
+			return s, nil
+		}
+	}
+	return sessions[0], nil
+}
+
+// UpdatedAt returns when the session was last updated.
+func (m *Manager) UpdatedAt(s *Session) time.Time {
+	info, err := os.Stat(m.path(s.ID))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
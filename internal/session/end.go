@@ -1,17 +1,19 @@
 package session
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
-// End marks the current session as ended.
-func (m *Manager) End() error {
-	s, err := m.Current()
+// End marks the session id as ended.
+func (m *Manager) End(id string) error {
+	s, err := m.Get(id)
 	if err != nil {
 		return err
 	}
 	if s == nil {
-		return nil
+		return fmt.Errorf("session %s not found", id)
 	}
-
 	s.State = StateEnded
 	s.EndedAt = time.Now()
 	return m.save(s)
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// Event is a session lifecycle event reported by an agent's hooks.
+type Event string
+
+const (
+	// EventStart is sent when the agent starts or resumes a session.
+	EventStart Event = "start"
+	// EventPrompt is sent when the user submits a prompt.
+	EventPrompt Event = "prompt"
+	// EventTool is sent after the agent runs a tool.
+	EventTool Event = "tool"
+	// EventStop is sent when the agent finishes responding.
+	EventStop Event = "stop"
+	// EventEnd is sent when the session is closed.
+	EventEnd Event = "end"
+)
+
+// Events lists the valid events, in lifecycle order.
+var Events = []Event{EventStart, EventPrompt, EventTool, EventStop, EventEnd}
+
+// ParseEvent validates an event name.
+func ParseEvent(name string) (Event, error) {
+	for _, e := range Events {
+		if string(e) == name {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("unknown agent event %q (want start, prompt, tool, stop or end)", name)
+}
+
+// state returns the session state an event leaves the session in.
+func (e Event) state() State {
+	switch e {
+	case EventStop:
+		return StateIdle
+	case EventEnd:
+		return StateEnded
+	default:
+		return StateActive
+	}
+}
+
+// EventSource describes the agent session an event came from.
+type EventSource struct {
+	Agent          string
+	AgentSessionID string
+	TranscriptPath string
+	Branch         string
+	SourceDir      string
+}
+
+// RecordEvent applies an agent-reported event to the session it belongs to:
+// start, prompt and tool make it ACTIVE, stop makes it IDLE and end makes it
+// ENDED. A session the git hooks recorded in the same worktree before the
+// agent's session ID was known is adopted while open; otherwise a new
+// session is tracked.
+func (m *Manager) RecordEvent(ev Event, src EventSource) (*Session, error) {
+	key := Key{Agent: src.Agent, AgentSessionID: src.AgentSessionID, Worktree: src.SourceDir}
+	s, err := m.Find(key)
+	if err == nil && s == nil && src.AgentSessionID != "" {
+		s, err = m.Find(Key{Agent: src.Agent, Worktree: src.SourceDir})
+		if s != nil && s.State == StateEnded {
+			s = nil
+		}
+	}
+	if err != nil || s == nil {
+		s = New(src.Agent, src.Branch, src.SourceDir)
+	}
+
+	s.AgentSessionID = src.AgentSessionID
+	if src.TranscriptPath != "" {
+		s.TranscriptPath = src.TranscriptPath
+	}
+	if src.Branch != "" {
+		s.Branch = src.Branch
+	}
+	s.State = ev.state()
+	s.LastEvent = ev
+	if s.State == StateEnded {
+		s.EndedAt = time.Now()
+	} else {
+		s.EndedAt = time.Time{}
+	}
+	return s, m.save(s)
+}
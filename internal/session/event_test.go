@@ -0,0 +1,99 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestRecordEvent(t *testing.T) {
+	src := EventSource{Agent: "claude-code", AgentSessionID: "s1", TranscriptPath: "/t/s1.jsonl", Branch: "main", SourceDir: "/repo"}
+
+	tests := []struct {
+		name      string
+		setup     func(m *Manager)
+		events    []Event
+		source    EventSource
+		wantState State
+		wantNew   bool
+	}{
+		{name: "start makes a session active", events: []Event{EventStart}, source: src, wantState: StateActive, wantNew: true},
+		{name: "stop makes it idle", events: []Event{EventStart, EventStop}, source: src, wantState: StateIdle},
+		{name: "prompt reactivates it", events: []Event{EventStart, EventStop, EventPrompt}, source: src, wantState: StateActive},
+		{name: "end ends it", events: []Event{EventStart, EventTool, EventEnd}, source: src, wantState: StateEnded},
+		{
+			name:      "another agent session is tracked separately",
+			events:    []Event{EventStart},
+			source:    EventSource{Agent: "claude-code", AgentSessionID: "s2", SourceDir: "/repo"},
+			wantState: StateActive,
+			wantNew:   true,
+		},
+		{
+			name: "an open git hook session is adopted",
+			setup: func(m *Manager) {
+				if err := m.RecordActive(Key{Agent: "claude-code", Worktree: "/repo"}, "main", 0); err != nil {
+					t.Fatal(err)
+				}
+			},
+			events:    []Event{EventPrompt},
+			source:    src,
+			wantState: StateActive,
+		},
+		{
+			name: "a condensed session is resumed",
+			setup: func(m *Manager) {
+				if err := m.MarkCondensed(Key{Agent: "claude-code", AgentSessionID: "s1", Worktree: "/repo"}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			events:    []Event{EventPrompt},
+			source:    src,
+			wantState: StateActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := NewManager(t.TempDir())
+			if tt.setup != nil {
+				tt.setup(mgr)
+			}
+			last := len(tt.events) - 1
+			for _, ev := range tt.events[:last] {
+				if _, err := mgr.RecordEvent(ev, src); err != nil {
+					t.Fatalf("RecordEvent(%s): %v", ev, err)
+				}
+			}
+			before, _ := mgr.Current()
+			s, err := mgr.RecordEvent(tt.events[last], tt.source)
+			if err != nil {
+				t.Fatalf("RecordEvent(%s): %v", tt.events[last], err)
+			}
+
+			if s.State != tt.wantState {
+				t.Errorf("state = %s, want %s", s.State, tt.wantState)
+			}
+			if isNew := before == nil || before.ID != s.ID; isNew != tt.wantNew {
+				t.Errorf("new session = %v, want %v", isNew, tt.wantNew)
+			}
+			if s.AgentSessionID != tt.source.AgentSessionID || s.LastEvent != tt.events[last] {
+				t.Errorf("session = %+v", s)
+			}
+			if (s.State == StateEnded) == s.EndedAt.IsZero() {
+				t.Errorf("EndedAt = %v for state %s", s.EndedAt, s.State)
+			}
+
+			cur, err := mgr.Current()
+			if err != nil || cur.ID != s.ID || cur.State != s.State {
+				t.Errorf("Current = %+v, %v", cur, err)
+			}
+		})
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	if ev, err := ParseEvent("stop"); err != nil || ev != EventStop {
+		t.Errorf("ParseEvent(stop) = %q, %v", ev, err)
+	}
+	if _, err := ParseEvent("pause"); err == nil {
+		t.Error("ParseEvent(pause) succeeded")
+	}
+}
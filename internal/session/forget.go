@@ -0,0 +1,23 @@
+package session
+
+import (
+	"fmt"
+	"os"
+)
+
+// Forget stops tracking the session id, removing its state file and index
+// entry. Unlike End, nothing is remembered about the session afterwards, so
+// a later commit may capture its transcript again.
+func (m *Manager) Forget(id string) error {
+	s, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return fmt.Errorf("session %s not found", id)
+	}
+	if err := os.Remove(m.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing session: %w", err)
+	}
+	return m.updateIndex(func(idx *index) { idx.remove(id) })
+}
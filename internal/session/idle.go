@@ -0,0 +1,102 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// activityTail is how much of the end of a transcript is searched for the
+// last timestamped entry.
+const activityTail = 64 << 10
+
+// TranscriptActivity returns when the agent last wrote to the JSONL
+// transcript at path: the timestamp of its last timestamped entry, or the
+// file's modification time when no entry near the end carries one.
+func TranscriptActivity(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("opening transcript: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading transcript: %w", err)
+	}
+	offset := max(info.Size()-activityTail, 0)
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return time.Time{}, fmt.Errorf("reading transcript: %w", err)
+	}
+
+	lines := bytes.Split(tail, []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		if ts, ok := entryTimestamp(lines[i]); ok {
+			return ts, nil
+		}
+	}
+	return info.ModTime(), nil
+}
+
+// entryTimestamp parses the "timestamp" field of a transcript entry, either
+// an RFC 3339 string or Unix seconds.
+func entryTimestamp(line []byte) (time.Time, bool) {
+	var entry struct {
+		Timestamp json.RawMessage `json:"timestamp"`
+	}
+	if json.Unmarshal(line, &entry) != nil || len(entry.Timestamp) == 0 {
+		return time.Time{}, false
+	}
+	var s string
+	if json.Unmarshal(entry.Timestamp, &s) == nil {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		return t, err == nil
+	}
+	var secs float64
+	if json.Unmarshal(entry.Timestamp, &secs) == nil && secs > 0 {
+		return time.Unix(int64(secs), 0), true
+	}
+	return time.Time{}, false
+}
+
+// DetectIdle moves open sessions between ACTIVE and IDLE by the activity in
+// their transcripts: an ACTIVE session whose transcript has been quiet for
+// at least quiet becomes IDLE, and an IDLE session whose transcript was
+// written after the session was last updated becomes ACTIVE again. Only
+// sessions with a known transcript are considered. It returns the sessions
+// whose state changed.
+func (m *Manager) DetectIdle(quiet time.Duration, now time.Time) ([]*Session, error) {
+	sessions, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []*Session
+	for _, s := range sessions {
+		if s.State == StateEnded || s.TranscriptPath == "" {
+			continue
+		}
+		activity, err := TranscriptActivity(s.TranscriptPath)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case s.State == StateActive && now.Sub(activity) >= quiet:
+			s.State = StateIdle
+		case s.State == StateIdle && activity.After(m.UpdatedAt(s)):
+			s.State = StateActive
+		default:
+			continue
+		}
+		if err := m.save(s); err != nil {
+			return changed, err
+		}
+		changed = append(changed, s)
+	}
+	return changed, nil
+}
@@ -0,0 +1,114 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTranscriptActivity(t *testing.T) {
+	mtime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		content string
+		want    time.Time
+	}{
+		{
+			name:    "last timestamped entry",
+			content: `{"type":"user","timestamp":"2026-03-01T10:00:00Z"}` + "\n" + `{"type":"assistant","timestamp":"2026-03-01T10:05:00.5Z"}` + "\n",
+			want:    time.Date(2026, 3, 1, 10, 5, 0, 5e8, time.UTC),
+		},
+		{
+			name:    "skips entries without a timestamp",
+			content: `{"timestamp":"2026-03-01T10:00:00Z"}` + "\n" + `{"type":"summary"}` + "\n",
+			want:    time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "unix seconds",
+			content: `{"timestamp":1772359200}` + "\n",
+			want:    time.Unix(1772359200, 0),
+		},
+		{
+			name:    "falls back to the modification time",
+			content: `{"type":"summary"}` + "\n" + "not json\n",
+			want:    mtime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "t.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := TranscriptActivity(path)
+			if err != nil {
+				t.Fatalf("TranscriptActivity: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("TranscriptActivity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectIdle(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		state       State
+		activityAgo time.Duration
+		updatedAgo  time.Duration
+		want        State
+	}{
+		{name: "active and quiet becomes idle", state: StateActive, activityAgo: 10 * time.Minute, updatedAgo: 10 * time.Minute, want: StateIdle},
+		{name: "active and recent stays active", state: StateActive, activityAgo: time.Minute, updatedAgo: time.Minute, want: StateActive},
+		{name: "idle with new activity becomes active", state: StateIdle, activityAgo: time.Minute, updatedAgo: 3 * time.Minute, want: StateActive},
+		{name: "idle without new activity stays idle", state: StateIdle, activityAgo: 3 * time.Minute, updatedAgo: time.Minute, want: StateIdle},
+		{name: "ended is left alone", state: StateEnded, activityAgo: time.Minute, updatedAgo: 3 * time.Minute, want: StateEnded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := NewManager(t.TempDir())
+			transcript := filepath.Join(t.TempDir(), "t.jsonl")
+			if err := os.WriteFile(transcript, []byte(`{"type":"user"}`+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			activity := now.Add(-tt.activityAgo)
+			if err := os.Chtimes(transcript, activity, activity); err != nil {
+				t.Fatal(err)
+			}
+
+			s := New("claude-code", "main", "/repo")
+			s.State = tt.state
+			s.TranscriptPath = transcript
+			if err := mgr.save(s); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+			updated := now.Add(-tt.updatedAgo)
+			if err := os.Chtimes(mgr.path(s.ID), updated, updated); err != nil {
+				t.Fatal(err)
+			}
+
+			changed, err := mgr.DetectIdle(5*time.Minute, now)
+			if err != nil {
+				t.Fatalf("DetectIdle: %v", err)
+			}
+			got, _ := mgr.Get(s.ID)
+			if got.State != tt.want {
+				t.Errorf("state = %s, want %s", got.State, tt.want)
+			}
+			if wantChanged := tt.want != tt.state; (len(changed) == 1) != wantChanged {
+				t.Errorf("changed = %d session(s), want changed=%v", len(changed), wantChanged)
+			}
+		})
+	}
+}
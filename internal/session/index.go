@@ -0,0 +1,197 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	indexFile = "index.json"
+	lockFile  = "index.lock"
+	// legacyFile held the only tracked session before sessions were keyed.
+	legacyFile = "current.json"
+
+	// lockTimeout bounds how long an index update waits for another
+	// process; a lock older than staleLockAge is assumed abandoned.
+	lockTimeout  = 2 * time.Second
+	staleLockAge = 10 * time.Second
+)
+
+// index maps session keys to the sessions stored under them.
+type index struct {
+	Sessions []indexEntry `json:"sessions"`
+}
+
+type indexEntry struct {
+	Key
+	ID string `json:"id"`
+}
+
+// lookup returns the ID stored under key, or "".
+func (idx *index) lookup(key Key) string {
+	for _, e := range idx.Sessions {
+		if e.Key == key {
+			return e.ID
+		}
+	}
+	return ""
+}
+
+// put stores id under key, dropping any other key that pointed at id. It
+// returns the ID key previously pointed at, if it was a different session.
+func (idx *index) put(key Key, id string) (replaced string) {
+	kept := idx.Sessions[:0]
+	for _, e := range idx.Sessions {
+		switch {
+		case e.Key == key:
+			if e.ID != id {
+				replaced = e.ID
+			}
+		case e.ID != id:
+			kept = append(kept, e)
+		}
+	}
+	idx.Sessions = append(kept, indexEntry{Key: key, ID: id})
+	return replaced
+}
+
+// remove drops every key pointing at id.
+func (idx *index) remove(id string) {
+	kept := idx.Sessions[:0]
+	for _, e := range idx.Sessions {
+		if e.ID != id {
+			kept = append(kept, e)
+		}
+	}
+	idx.Sessions = kept
+}
+
+// loadIndex reads the index, rebuilding it from the session files when it
+// is missing or unreadable.
+func (m *Manager) loadIndex() (*index, error) {
+	if err := m.migrateLegacy(); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(m.stateDir, indexFile))
+	if err == nil {
+		var idx index
+		if json.Unmarshal(data, &idx) == nil {
+			return &idx, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading session index: %w", err)
+	}
+	return m.rebuildIndex()
+}
+
+// rebuildIndex indexes every session file, the most recently modified one
+// winning when two share a key.
+func (m *Manager) rebuildIndex() (*index, error) {
+	sessions, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	idx := &index{}
+	for i := len(sessions) - 1; i >= 0; i-- {
+		idx.put(sessions[i].Key(), sessions[i].ID)
+	}
+	return idx, nil
+}
+
+// updateIndex applies fn to the index under a lock and writes it back.
+func (m *Manager) updateIndex(fn func(*index)) error {
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	idx, err := m.loadIndex()
+	if err != nil {
+		return err
+	}
+	fn(idx)
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling session index: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(m.stateDir, indexFile), data); err != nil {
+		return fmt.Errorf("writing session index: %w", err)
+	}
+	return nil
+}
+
+// lock takes the index lock, which serializes hook processes (e.g. two
+// agents reporting events at once) updating the index.
+func (m *Manager) lock() (unlock func(), err error) {
+	path := filepath.Join(m.stateDir, lockFile)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("locking session index: %w", err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("locking session index: %s is held by another process", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// migrateLegacy moves the single session of the old current.json layout
+// into its own file. The index is rebuilt from the files afterwards.
+func (m *Manager) migrateLegacy() error {
+	path := filepath.Join(m.stateDir, legacyFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading session state: %w", err)
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s.ID == "" {
+			s.ID = newID()
+		}
+		if s.AgentSessionID == "" {
+			s.AgentSessionID = s.CapturedSessionID
+		}
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling session: %w", err)
+		}
+		if err := writeFileAtomic(m.path(s.ID), data); err != nil {
+			return fmt.Errorf("migrating session state: %w", err)
+		}
+		info, _ := os.Stat(path)
+		if info != nil {
+			_ = os.Chtimes(m.path(s.ID), info.ModTime(), info.ModTime())
+		}
+	}
+	_ = os.Remove(filepath.Join(m.stateDir, indexFile))
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing %s: %w", legacyFile, err)
+	}
+	return nil
+}
+
+// isSessionFile reports whether name is a session state file.
+func isSessionFile(name string) bool {
+	return strings.HasSuffix(name, ".json") && !strings.HasPrefix(name, ".") &&
+		name != indexFile && name != legacyFile
+}
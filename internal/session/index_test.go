@@ -0,0 +1,79 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFind_RebuildsIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		index []byte // nil removes the index
+	}{
+		{name: "missing index"},
+		{name: "corrupt index", index: []byte("{not json")},
+		{name: "stale index", index: []byte(`{"sessions":[{"agent":"claude-code","agent_session_id":"a","worktree":"/repo","id":"gone"}]}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := NewManager(t.TempDir())
+			key := Key{Agent: "claude-code", AgentSessionID: "a", Worktree: "/repo"}
+			if err := mgr.RecordActive(key, "main", 0); err != nil {
+				t.Fatalf("RecordActive: %v", err)
+			}
+			want, _ := mgr.Find(key)
+
+			indexPath := filepath.Join(mgr.stateDir, indexFile)
+			if tt.index == nil {
+				if err := os.Remove(indexPath); err != nil {
+					t.Fatal(err)
+				}
+			} else if err := os.WriteFile(indexPath, tt.index, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := mgr.Find(key)
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if got == nil || got.ID != want.ID {
+				t.Errorf("Find = %+v, want session %s", got, want.ID)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(dir)
+
+	legacy := Session{
+		ID:                "old-id",
+		Agent:             "claude-code",
+		State:             StateEnded,
+		SourceDir:         "/repo",
+		Condensed:         true,
+		CapturedSessionID: "sess-1",
+	}
+	data, _ := json.Marshal(legacy)
+	if err := os.MkdirAll(mgr.stateDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mgr.stateDir, legacyFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := mgr.Find(Key{Agent: "claude-code", AgentSessionID: "sess-1", Worktree: "/repo"})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if s == nil || s.ID != "old-id" || !s.Condensed {
+		t.Fatalf("Find = %+v, want migrated session old-id", s)
+	}
+	if _, err := os.Stat(filepath.Join(mgr.stateDir, legacyFile)); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, stat err = %v", legacyFile, err)
+	}
+}
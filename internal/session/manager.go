@@ -8,7 +8,9 @@ import (
 	"time"
 )
 
-// Manager handles session lifecycle transitions.
+// Manager handles session lifecycle transitions. Each tracked session is
+// stored in its own file, <id>.json, in the sessions directory, and
+// index.json maps session keys to those files.
 type Manager struct {
 	stateDir string
 }
@@ -20,36 +22,75 @@ func NewManager(partioDir string) *Manager {
 	}
 }
 
+// save writes s to its file and points its key at it in the index. A
+// different session previously stored under the same key is removed.
 func (m *Manager) save(s *Session) error {
+	if err := os.MkdirAll(m.stateDir, 0o755); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling session: %w", err)
 	}
-	return os.WriteFile(m.currentPath(), data, 0o644)
+	if err := writeFileAtomic(m.path(s.ID), data); err != nil {
+		return fmt.Errorf("writing session: %w", err)
+	}
+
+	return m.updateIndex(func(idx *index) {
+		if replaced := idx.put(s.Key(), s.ID); replaced != "" {
+			_ = os.Remove(m.path(replaced))
+		}
+	})
 }
 
-func (m *Manager) currentPath() string {
-	return filepath.Join(m.stateDir, "current.json")
+// path returns the state file of the session id.
+func (m *Manager) path(id string) string {
+	return filepath.Join(m.stateDir, id+".json")
 }
 
-// MarkCondensed marks the current session as ended and fully condensed with the
-// given Claude session ID. Future hook runs that see the same session ID and an
-// unmodified JSONL file will skip checkpoint creation.
-func (m *Manager) MarkCondensed(capturedSessionID string) error {
-	s, err := m.Current()
+// MarkCondensed marks the session identified by key as ended and fully
+// condensed. Future hook runs that see the same agent session and an
+// unmodified transcript will skip checkpoint creation.
+func (m *Manager) MarkCondensed(key Key) error {
+	s, err := m.Find(key)
 	if err != nil {
 		return err
 	}
 	if s == nil {
 		// No tracked session yet — create a minimal one to persist condensed state.
-		if err := os.MkdirAll(m.stateDir, 0o755); err != nil {
-			return fmt.Errorf("creating session directory: %w", err)
+		s = &Session{
+			ID:             newID(),
+			Agent:          key.Agent,
+			AgentSessionID: key.AgentSessionID,
+			SourceDir:      key.Worktree,
 		}
-		s = &Session{}
 	}
 	s.State = StateEnded
 	s.Condensed = true
-	s.CapturedSessionID = capturedSessionID
+	s.CapturedSessionID = key.AgentSessionID
 	s.CapturedAt = time.Now()
 	return m.save(s)
 }
+
+// writeFileAtomic replaces path with data, so concurrent readers never see
+// a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
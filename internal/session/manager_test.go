@@ -50,12 +50,12 @@ func TestManagerEnd(t *testing.T) {
 	dir := t.TempDir()
 	mgr := NewManager(dir)
 
-	_, err := mgr.Start("claude-code", "main", "/tmp/test")
+	s, err := mgr.Start("claude-code", "main", "/tmp/test")
 	if err != nil {
 		t.Fatalf("start error: %v", err)
 	}
 
-	if err := mgr.End(); err != nil {
+	if err := mgr.End(s.ID); err != nil {
 		t.Fatalf("end error: %v", err)
 	}
 
@@ -73,12 +73,12 @@ func TestManagerMarkCondensed(t *testing.T) {
 		dir := t.TempDir()
 		mgr := NewManager(dir)
 
-		_, err := mgr.Start("claude-code", "main", "/tmp/test")
+		s, err := mgr.Start("claude-code", "main", "/tmp/test")
 		if err != nil {
 			t.Fatalf("start error: %v", err)
 		}
 
-		if err := mgr.MarkCondensed("sess-abc"); err != nil {
+		if err := mgr.MarkCondensed(s.Key()); err != nil {
 			t.Fatalf("mark condensed error: %v", err)
 		}
 
@@ -92,8 +92,8 @@ func TestManagerMarkCondensed(t *testing.T) {
 		if !cur.Condensed {
 			t.Error("expected condensed=true")
 		}
-		if cur.CapturedSessionID != "sess-abc" {
-			t.Errorf("expected captured_session_id=sess-abc, got %s", cur.CapturedSessionID)
+		if cur.ID != s.ID {
+			t.Errorf("expected session %s to be condensed, got %s", s.ID, cur.ID)
 		}
 		if cur.CapturedAt.IsZero() {
 			t.Error("expected captured_at to be set")
@@ -104,7 +104,7 @@ func TestManagerMarkCondensed(t *testing.T) {
 		dir := t.TempDir()
 		mgr := NewManager(dir)
 
-		if err := mgr.MarkCondensed("sess-xyz"); err != nil {
+		if err := mgr.MarkCondensed(Key{Agent: "claude-code", AgentSessionID: "sess-xyz", Worktree: "/tmp/test"}); err != nil {
 			t.Fatalf("mark condensed error: %v", err)
 		}
 
@@ -142,3 +142,23 @@ func TestManagerClear(t *testing.T) {
 		t.Error("expected nil session after clear")
 	}
 }
+
+func TestManagerForget(t *testing.T) {
+	mgr := NewManager(t.TempDir())
+
+	key := Key{Agent: "claude-code", AgentSessionID: "sess-1", Worktree: "/tmp/test"}
+	if err := mgr.MarkCondensed(key); err != nil {
+		t.Fatalf("mark condensed error: %v", err)
+	}
+	s, _ := mgr.Find(key)
+
+	if err := mgr.Forget(s.ID); err != nil {
+		t.Fatalf("forget error: %v", err)
+	}
+	if got, err := mgr.Find(key); err != nil || got != nil {
+		t.Errorf("Find after forget = %+v, %v; want nil", got, err)
+	}
+	if err := mgr.Forget(s.ID); err == nil {
+		t.Error("expected an error forgetting an unknown session")
+	}
+}
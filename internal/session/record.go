@@ -1,26 +1,17 @@
 package session
 
-import (
-	"fmt"
-	"os"
-)
-
-// RecordActive persists the current agent session in ACTIVE state, refreshing
-// the state file (and therefore its modification time) and recording the agent
-// PID for later liveness checks. A non-ended session is refreshed in place
-// (keeping its ID and start time); otherwise a new session is created.
-func (m *Manager) RecordActive(agentName, branch, sourceDir string, pid int) error {
-	if err := os.MkdirAll(m.stateDir, 0o755); err != nil {
-		return fmt.Errorf("creating session directory: %w", err)
-	}
-
-	s, err := m.Current()
+// RecordActive persists the agent session identified by key in ACTIVE
+// state, refreshing its state file (and therefore its modification time)
+// and recording the agent PID for later liveness checks. A non-ended
+// session is refreshed in place (keeping its ID and start time); otherwise
+// a new session replaces it.
+func (m *Manager) RecordActive(key Key, branch string, pid int) error {
+	s, err := m.Find(key)
 	if err != nil || s == nil || s.State == StateEnded {
-		s = New(agentName, branch, sourceDir)
+		s = New(key.Agent, branch, key.Worktree)
+		s.AgentSessionID = key.AgentSessionID
 	} else {
-		s.Agent = agentName
 		s.Branch = branch
-		s.SourceDir = sourceDir
 		s.State = StateActive
 	}
 	s.AgentPID = pid
@@ -2,10 +2,12 @@ package session
 
 import "testing"
 
+var recordKey = Key{Agent: "claude-code", Worktree: "/tmp/repo"}
+
 func TestRecordActive_CreatesActiveSessionWithPID(t *testing.T) {
 	mgr := NewManager(t.TempDir())
 
-	if err := mgr.RecordActive("claude-code", "main", "/tmp/repo", 4242); err != nil {
+	if err := mgr.RecordActive(recordKey, "main", 4242); err != nil {
 		t.Fatalf("RecordActive: %v", err)
 	}
 
@@ -27,12 +29,12 @@ func TestRecordActive_CreatesActiveSessionWithPID(t *testing.T) {
 func TestRecordActive_RefreshesExistingSession(t *testing.T) {
 	mgr := NewManager(t.TempDir())
 
-	if err := mgr.RecordActive("claude-code", "main", "/tmp/repo", 1); err != nil {
+	if err := mgr.RecordActive(recordKey, "main", 1); err != nil {
 		t.Fatalf("first RecordActive: %v", err)
 	}
 	first, _ := mgr.Current()
 
-	if err := mgr.RecordActive("claude-code", "main", "/tmp/repo", 2); err != nil {
+	if err := mgr.RecordActive(recordKey, "main", 2); err != nil {
 		t.Fatalf("second RecordActive: %v", err)
 	}
 	second, _ := mgr.Current()
@@ -53,11 +55,11 @@ func TestRecordActive_ReplacesEndedSession(t *testing.T) {
 
 	// A previously ended/condensed session must not be refreshed — new agent
 	// activity should start a fresh ACTIVE session.
-	if err := mgr.MarkCondensed("sess-1"); err != nil {
+	if err := mgr.MarkCondensed(recordKey); err != nil {
 		t.Fatalf("MarkCondensed: %v", err)
 	}
 
-	if err := mgr.RecordActive("claude-code", "main", "/tmp/repo", 7); err != nil {
+	if err := mgr.RecordActive(recordKey, "main", 7); err != nil {
 		t.Fatalf("RecordActive: %v", err)
 	}
 
@@ -72,3 +74,32 @@ func TestRecordActive_ReplacesEndedSession(t *testing.T) {
 		t.Error("expected Condensed to be reset on a fresh active session")
 	}
 }
+
+func TestRecordActive_KeepsSessionsApart(t *testing.T) {
+	mgr := NewManager(t.TempDir())
+
+	other := Key{Agent: "claude-code", Worktree: "/tmp/other"}
+	if err := mgr.RecordActive(recordKey, "main", 1); err != nil {
+		t.Fatalf("RecordActive: %v", err)
+	}
+	if err := mgr.RecordActive(other, "feature", 2); err != nil {
+		t.Fatalf("RecordActive: %v", err)
+	}
+	if err := mgr.MarkCondensed(recordKey); err != nil {
+		t.Fatalf("MarkCondensed: %v", err)
+	}
+
+	sessions, err := mgr.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+	for _, s := range sessions {
+		wantCondensed := s.Key() == recordKey
+		if s.Condensed != wantCondensed {
+			t.Errorf("session %s (%s): condensed = %v, want %v", s.ID, s.SourceDir, s.Condensed, wantCondensed)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Resolve returns the session identified by ref: its ID, its agent's
+// session ID, or an unambiguous prefix of either.
+func (m *Manager) Resolve(ref string) (*Session, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("empty session ID")
+	}
+	sessions, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Session
+	for _, s := range sessions {
+		if s.ID == ref || s.AgentSessionID == ref {
+			return s, nil
+		}
+		if strings.HasPrefix(s.ID, ref) || (s.AgentSessionID != "" && strings.HasPrefix(s.AgentSessionID, ref)) {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("session %s not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("session ID %s is ambiguous: matches %d sessions", ref, len(matches))
+	}
+}
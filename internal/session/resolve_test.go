@@ -0,0 +1,49 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	mgr := NewManager(t.TempDir())
+	a := &Session{ID: "aaaa1111", Agent: "claude-code", AgentSessionID: "sess-1", SourceDir: "/repo"}
+	b := &Session{ID: "aaaa2222", Agent: "codex", AgentSessionID: "thread-9", SourceDir: "/repo"}
+	for _, s := range []*Session{a, b} {
+		if err := mgr.save(s); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "aaaa1111", want: a.ID},
+		{ref: "aaaa2", want: b.ID},
+		{ref: "sess-1", want: a.ID},
+		{ref: "thread", want: b.ID},
+		{ref: "aaaa", wantErr: "ambiguous"},
+		{ref: "zzz", wantErr: "not found"},
+		{ref: "", wantErr: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			s, err := mgr.Resolve(tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q): %v", tt.ref, err)
+			}
+			if s.ID != tt.want {
+				t.Errorf("Resolve(%q) = %s, want %s", tt.ref, s.ID, tt.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,13 @@ type Session struct {
 	// stale-session cleanup to verify whether the process is still alive.
 	AgentPID int `json:"agent_pid,omitempty"`
 
+	// AgentSessionID and TranscriptPath identify the agent's own session
+	// when the agent reports events through its hooks; LastEvent is the
+	// latest event received.
+	AgentSessionID string `json:"agent_session_id,omitempty"`
+	TranscriptPath string `json:"transcript_path,omitempty"`
+	LastEvent      Event  `json:"last_event,omitempty"`
+
 	// Condensed is true when the session has been fully captured in a checkpoint
 	// and has ended. Post-commit sets this after creating a checkpoint so future
 	// commits with the same session can be skipped.
@@ -28,10 +35,25 @@ type Session struct {
 	CapturedAt        time.Time `json:"captured_at,omitempty"`
 }
 
+// Key identifies a tracked session: one agent session in one worktree.
+// Sessions recorded before the agent's session ID is known have an empty
+// AgentSessionID.
+type Key struct {
+	Agent          string `json:"agent"`
+	AgentSessionID string `json:"agent_session_id,omitempty"`
+	Worktree       string `json:"worktree"`
+}
+
+// Key returns the key the session is tracked under. Its worktree is the
+// session's SourceDir.
+func (s *Session) Key() Key {
+	return Key{Agent: s.Agent, AgentSessionID: s.AgentSessionID, Worktree: s.SourceDir}
+}
+
 // New creates a new session with a generated UUID.
 func New(agent, branch, sourceDir string) *Session {
 	return &Session{
-		ID:        uuid.New().String(),
+		ID:        newID(),
 		Agent:     agent,
 		State:     StateActive,
 		StartedAt: time.Now(),
@@ -39,3 +61,7 @@ func New(agent, branch, sourceDir string) *Session {
 		SourceDir: sourceDir,
 	}
 }
+
+func newID() string {
+	return uuid.New().String()
+}